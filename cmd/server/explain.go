@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/takeshy/mcp-gatekeeper/internal/executor"
+	"github.com/takeshy/mcp-gatekeeper/internal/mcp"
+	"github.com/takeshy/mcp-gatekeeper/internal/plugin"
+	"github.com/takeshy/mcp-gatekeeper/internal/policy"
+)
+
+// runExplain implements the "explain" subcommand and returns the process exit code:
+// 0 if the call would be allowed, 1 if it would be denied, 2 on errors
+func runExplain(args []string) int {
+	fs := flag.NewFlagSet("explain", flag.ContinueOnError)
+	var (
+		pluginsDir = fs.String("plugins-dir", "", "Directory containing plugin JSON files")
+		pluginFile = fs.String("plugin-file", "", "Single plugin JSON file")
+		rootDir    = fs.String("root-dir", ".", "Root directory for command execution")
+		cwd        = fs.String("cwd", "", "Working directory for the call (defaults to root-dir)")
+		arguments  = fs.String("arguments", "", "Tool arguments as a JSON object (for tools with typed parameters)")
+	)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s explain (--plugins-dir=DIR | --plugin-file=FILE) [--root-dir=DIR] [--cwd=DIR] [--arguments=JSON] TOOL [ARGS...]\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() < 1 {
+		fs.Usage()
+		return 2
+	}
+
+	var plugins *plugin.Config
+	var err error
+	switch {
+	case *pluginFile != "":
+		plugins, err = plugin.LoadFromFile(*pluginFile)
+	case *pluginsDir != "":
+		plugins, err = plugin.LoadFromDir(*pluginsDir)
+	default:
+		fmt.Fprintln(os.Stderr, "Error: --plugins-dir or --plugin-file is required")
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to load plugins: %v\n", err)
+		return 2
+	}
+
+	rootDirAbs, err := filepath.Abs(*rootDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid root-dir path: %v\n", err)
+		return 2
+	}
+
+	params := &mcp.CallToolParams{Name: fs.Arg(0), Arguments: map[string]interface{}{}}
+	if *arguments != "" {
+		if fs.NArg() > 1 {
+			fmt.Fprintln(os.Stderr, "Error: --arguments cannot be combined with positional arguments")
+			return 2
+		}
+		if err := json.Unmarshal([]byte(*arguments), &params.Arguments); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid --arguments: %v\n", err)
+			return 2
+		}
+	} else if fs.NArg() > 1 {
+		toolArgs := make([]interface{}, 0, fs.NArg()-1)
+		for _, a := range fs.Args()[1:] {
+			toolArgs = append(toolArgs, a)
+		}
+		params.Arguments["args"] = toolArgs
+	}
+	if *cwd != "" {
+		params.Arguments["cwd"] = *cwd
+	}
+
+	// The executor is only used to validate cwd and report each tool's effective isolation
+	exec := executor.NewExecutor(&executor.ExecutorConfig{RootDir: rootDirAbs})
+	result, err := mcp.Explain(policy.NewEvaluator(), exec, plugins, rootDirAbs, params)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(result); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to encode result: %v\n", err)
+		return 2
+	}
+
+	if !result.Allowed {
+		return 1
+	}
+	return 0
+}
@@ -17,10 +17,25 @@ import (
 	"github.com/takeshy/mcp-gatekeeper/internal/executor"
 	"github.com/takeshy/mcp-gatekeeper/internal/mcp"
 	"github.com/takeshy/mcp-gatekeeper/internal/plugin"
+	"github.com/takeshy/mcp-gatekeeper/internal/policy"
 	"github.com/takeshy/mcp-gatekeeper/internal/version"
 )
 
 func main() {
+	// Re-executed to apply a tool's resource limits or namespace sandbox; does not return in that case
+	executor.RunResourceLimitHelper()
+	executor.RunNamespaceHelper()
+
+	// Subcommands are dispatched before the server flags are parsed
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "validate":
+			os.Exit(runValidate(os.Args[2:]))
+		case "explain":
+			os.Exit(runExplain(os.Args[2:]))
+		}
+	}
+
 	var (
 		showVersion     = flag.Bool("version", false, "Show version and exit")
 		mode            = flag.String("mode", "stdio", "Server mode: stdio, http, or bridge")
@@ -36,10 +51,26 @@ func main() {
 		maxResponseSize = flag.Int("max-response-size", 500000, "Max response size in bytes for bridge mode (default 500000)")
 		debug           = flag.Bool("debug", false, "Enable debug logging (logs request/response for bridge mode)")
 		dbPath           = flag.String("db", "", "SQLite database path for audit logging (optional)")
+		auditRedactFile  = flag.String("audit-redact-file", "", "File of regular expressions redacted from audit logs in addition to known token formats, one per line (requires --db)")
+		auditEnvKeys     = flag.String("audit-redact-env-keys", strings.Join(db.DefaultAuditEnvKeys, ","), "Comma-separated glob patterns of environment variables whose values are redacted from audit logs, empty = none (requires --db)")
+		auditEnvMinLen   = flag.Int("audit-redact-env-min-length", db.DefaultAuditEnvMinLength, "Shortest environment variable value redacted from audit logs (requires --db)")
+		enableAPIKeys    = flag.Bool("enable-api-keys", false, "Enable named API keys managed with mcp-gatekeeper-admin (requires --db)")
 		enableOAuth      = flag.Bool("enable-oauth", false, "Enable OAuth 2.0 authentication (requires --db)")
 		oauthIssuer      = flag.String("oauth-issuer", "", "OAuth issuer URL (optional, auto-detected if empty)")
 		enableStreamable = flag.Bool("enable-streamable", false, "Enable MCP Streamable HTTP (2025-06-18)")
 		sessionTTL       = flag.Duration("session-ttl", 30*time.Minute, "Session TTL for Streamable HTTP")
+		watchPlugins     = flag.Bool("watch-plugins", false, "Reload plugins when --plugins-dir or --plugin-file changes (stdio/http)")
+		watchInterval    = flag.Duration("watch-interval", plugin.DefaultWatchInterval, "Polling interval for --watch-plugins")
+		enableExplain    = flag.Bool("enable-explain", false, "Serve the gatekeeper/explain policy dry-run method (stdio/http)")
+		timeout          = flag.Duration("timeout", executor.DefaultTimeout, "Command timeout for tools without their own timeout (stdio/http)")
+		timeoutCeiling   = flag.Duration("timeout-ceiling", executor.DefaultTimeoutCeiling, "Largest timeout a tool may set (stdio/http)")
+		maxOutput        = flag.Int("max-output", executor.DefaultMaxOutput, "Per-stream output limit in bytes for tools without their own (stdio/http)")
+		outputCeiling    = flag.Int("output-ceiling", executor.DefaultOutputCeiling, "Largest max_output_bytes/max_stderr_bytes a tool may set (stdio/http)")
+		maxConcurrency   = flag.Int("max-concurrency", executor.DefaultMaxConcurrency, "Maximum concurrent tool executions, 0 = unlimited (stdio/http)")
+		maxQueue         = flag.Int("max-queue", executor.DefaultMaxQueue, "Maximum tool executions waiting for a free slot (stdio/http)")
+		queueTimeout     = flag.Duration("queue-timeout", executor.DefaultQueueTimeout, "Maximum time a tool execution waits for a free slot, 0 = no limit (stdio/http)")
+		bwrapBindPaths   = flag.String("bwrap-bind-paths", "", "Comma-separated host directories outside --root-dir that tool bwrap binds may use (stdio/http)")
+		strictSandbox    = flag.Bool("strict-sandbox", false, "Refuse to start or reload if a tool's declared sandbox is unavailable (stdio/http)")
 	)
 	flag.Parse()
 
@@ -78,6 +109,18 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Validate named API keys require DB
+	if *enableAPIKeys && *dbPath == "" {
+		fmt.Fprintf(os.Stderr, "Error: --enable-api-keys requires --db to be specified\n")
+		os.Exit(1)
+	}
+
+	// Validate audit redaction requires DB
+	if *auditRedactFile != "" && *dbPath == "" {
+		fmt.Fprintf(os.Stderr, "Error: --audit-redact-file requires --db to be specified\n")
+		os.Exit(1)
+	}
+
 	// Open database if specified (optional for audit logging, required for OAuth)
 	var database *db.DB
 	if *dbPath != "" {
@@ -88,7 +131,35 @@ func main() {
 			os.Exit(1)
 		}
 		defer database.Close()
+		if *auditRedactFile != "" {
+			patterns, err := db.LoadAuditRedactPatterns(*auditRedactFile)
+			if err == nil {
+				err = database.SetAuditRedactPatterns(patterns)
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		var envKeys []string
+		for _, key := range strings.Split(*auditEnvKeys, ",") {
+			if key = strings.TrimSpace(key); key != "" {
+				envKeys = append(envKeys, key)
+			}
+		}
+		if err := policy.ValidateAllowedEnvKeys(envKeys); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid --audit-redact-env-keys: %v\n", err)
+			os.Exit(1)
+		}
+		if *auditEnvMinLen < 1 {
+			fmt.Fprintln(os.Stderr, "Error: --audit-redact-env-min-length must be at least 1")
+			os.Exit(1)
+		}
+		database.SetAuditEnvRedaction(envKeys, *auditEnvMinLen)
 		fmt.Printf("Audit logging enabled (db: %s)\n", *dbPath)
+		if *enableAPIKeys {
+			fmt.Printf("Named API key authentication enabled\n")
+		}
 		if *enableOAuth {
 			fmt.Printf("OAuth 2.0 authentication enabled\n")
 		}
@@ -108,7 +179,7 @@ func main() {
 			upstreamEnvVars = strings.Split(*upstreamEnv, ",")
 		}
 
-		if err := runBridge(*addr, *upstream, upstreamEnvVars, *apiKey, *rateLimit, *maxResponseSize, *debug, database, *enableOAuth, *oauthIssuer, *enableStreamable, *sessionTTL); err != nil {
+		if err := runBridge(*addr, *upstream, upstreamEnvVars, *apiKey, *rateLimit, *maxResponseSize, *debug, database, *enableAPIKeys, *enableOAuth, *oauthIssuer, *enableStreamable, *sessionTTL); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
@@ -160,15 +231,46 @@ func main() {
 		}
 	}
 
+	// Validate execution limits
+	limits := executor.Limits{
+		Timeout:        *timeout,
+		MaxOutput:      *maxOutput,
+		TimeoutCeiling: *timeoutCeiling,
+		OutputCeiling:  *outputCeiling,
+		MaxConcurrency: *maxConcurrency,
+		MaxQueue:       *maxQueue,
+		QueueTimeout:   *queueTimeout,
+	}
+	if err := limits.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid execution limits: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Resolve host paths allowed for bwrap binds
+	var bindPaths []string
+	if *bwrapBindPaths != "" {
+		for _, p := range strings.Split(*bwrapBindPaths, ",") {
+			abs, err := filepath.Abs(strings.TrimSpace(p))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: invalid bwrap-bind-paths entry %q: %v\n", p, err)
+				os.Exit(1)
+			}
+			bindPaths = append(bindPaths, abs)
+		}
+	}
+
 	// Load plugins
 	var plugins *plugin.Config
+	var watchPath string
 	if *pluginFile != "" {
+		watchPath = *pluginFile
 		plugins, err = plugin.LoadFromFile(*pluginFile)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: failed to load plugin file: %v\n", err)
 			os.Exit(1)
 		}
 	} else if *pluginsDir != "" {
+		watchPath = *pluginsDir
 		plugins, err = plugin.LoadFromDir(*pluginsDir)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: failed to load plugins: %v\n", err)
@@ -179,10 +281,50 @@ func main() {
 		fmt.Fprintf(os.Stderr, "Usage: %s --root-dir=/path --plugins-dir=/path/to/plugins [options]\n", os.Args[0])
 		os.Exit(1)
 	}
+	if err := validatePlugins(plugins, limits, rootDirAbs, bindPaths); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid plugin configuration: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Run '%s validate' for a full report\n", os.Args[0])
+		os.Exit(1)
+	}
+
+	// With --strict-sandbox, every tool's declared sandbox must be able to run it
+	var sandboxChecker *executor.Executor
+	if *strictSandbox {
+		sandboxChecker = executor.NewExecutor(&executor.ExecutorConfig{
+			RootDir:   rootDirAbs,
+			WasmDir:   wasmDirAbs,
+			BindPaths: bindPaths,
+		})
+		if err := checkSandboxes(plugins, sandboxChecker); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --strict-sandbox: %v\n", err)
+			os.Exit(1)
+		}
+	}
 
 	// Print loaded tools
 	printLoadedTools(plugins)
 
+	// Watch plugin files for changes (OnReload is set by the server)
+	var watcherConfig *plugin.WatcherConfig
+	if *watchPlugins {
+		watcherConfig = &plugin.WatcherConfig{
+			Path:     watchPath,
+			Interval: *watchInterval,
+			Validate: func(plugins *plugin.Config) error {
+				if err := validatePlugins(plugins, limits, rootDirAbs, bindPaths); err != nil {
+					return err
+				}
+				if sandboxChecker != nil {
+					return checkSandboxes(plugins, sandboxChecker)
+				}
+				return nil
+			},
+			OnError: func(err error) {
+				fmt.Fprintf(os.Stderr, "[WARN] Plugin reload failed, keeping previous configuration: %v\n", err)
+			},
+		}
+	}
+
 	// Check if any tool uses bubblewrap and prepare mount directories
 	var sandboxExecutor *executor.Executor
 	hasBubblewrap := false
@@ -193,7 +335,8 @@ func main() {
 		}
 	}
 
-	if hasBubblewrap {
+	// With --watch-plugins, bubblewrap tools may be added later, so prepare up front
+	if hasBubblewrap || *watchPlugins {
 		sandboxExecutor = executor.NewExecutor(&executor.ExecutorConfig{
 			RootDir: rootDirAbs,
 			WasmDir: wasmDirAbs,
@@ -206,12 +349,13 @@ func main() {
 				fmt.Printf("Created bubblewrap mount directories: %v\n", createdDirs)
 			}
 		}
+		reportSandboxBackend(sandboxExecutor.GetSandboxMode())
 	}
 
 	// Run in appropriate mode
 	switch *mode {
 	case "stdio":
-		if err := runStdio(plugins, *apiKey, rootDirAbs, wasmDirAbs, database); err != nil {
+		if err := runStdio(plugins, watcherConfig, *apiKey, rootDirAbs, wasmDirAbs, database, *enableExplain, limits, bindPaths); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			if sandboxExecutor != nil {
 				sandboxExecutor.Cleanup()
@@ -219,7 +363,7 @@ func main() {
 			os.Exit(1)
 		}
 	case "http":
-		if err := runHTTP(plugins, *addr, *rateLimit, rootDirAbs, wasmDirAbs, *apiKey, database, *enableOAuth, *oauthIssuer, *enableStreamable, *sessionTTL); err != nil {
+		if err := runHTTP(plugins, watcherConfig, *addr, *rateLimit, rootDirAbs, wasmDirAbs, *apiKey, database, *enableAPIKeys, *enableOAuth, *oauthIssuer, *enableStreamable, *sessionTTL, *enableExplain, limits, bindPaths); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			if sandboxExecutor != nil {
 				sandboxExecutor.Cleanup()
@@ -239,7 +383,7 @@ func main() {
 	}
 }
 
-func runStdio(plugins *plugin.Config, apiKey string, rootDir string, wasmDir string, database *db.DB) error {
+func runStdio(plugins *plugin.Config, watcherConfig *plugin.WatcherConfig, apiKey string, rootDir string, wasmDir string, database *db.DB, enableExplain bool, limits executor.Limits, bindPaths []string) error {
 	// For stdio mode, we require API key to be set (either flag or env var)
 	expectedAPIKey := apiKey
 
@@ -247,10 +391,22 @@ func runStdio(plugins *plugin.Config, apiKey string, rootDir string, wasmDir str
 	if err != nil {
 		return fmt.Errorf("failed to create stdio server: %w", err)
 	}
+	server.SetLimits(limits)
+	server.SetBindPaths(bindPaths)
+	if enableExplain {
+		server.EnableExplain()
+	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	if watcherConfig != nil {
+		server.EnableListChanged()
+		if err := startPluginWatcher(ctx, watcherConfig, server.SetPlugins); err != nil {
+			return err
+		}
+	}
+
 	// Handle signals
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
@@ -262,7 +418,7 @@ func runStdio(plugins *plugin.Config, apiKey string, rootDir string, wasmDir str
 	return server.Run(ctx)
 }
 
-func runHTTP(plugins *plugin.Config, addr string, rateLimit int, rootDir string, wasmDir string, apiKey string, database *db.DB, enableOAuth bool, oauthIssuer string, enableStreamable bool, sessionTTL time.Duration) error {
+func runHTTP(plugins *plugin.Config, watcherConfig *plugin.WatcherConfig, addr string, rateLimit int, rootDir string, wasmDir string, apiKey string, database *db.DB, enableAPIKeys bool, enableOAuth bool, oauthIssuer string, enableStreamable bool, sessionTTL time.Duration, enableExplain bool, limits executor.Limits, bindPaths []string) error {
 	config := &mcp.HTTPConfig{
 		RateLimit:        rateLimit,
 		RateLimitWindow:  time.Minute,
@@ -270,10 +426,15 @@ func runHTTP(plugins *plugin.Config, addr string, rateLimit int, rootDir string,
 		WasmDir:          wasmDir,
 		APIKey:           apiKey,
 		DB:               database,
+		EnableAPIKeys:    enableAPIKeys,
 		EnableOAuth:      enableOAuth,
 		OAuthIssuer:      oauthIssuer,
 		EnableStreamable: enableStreamable,
 		SessionTTL:       sessionTTL,
+		WatchPlugins:     watcherConfig != nil,
+		EnableExplain:    enableExplain,
+		Limits:           limits,
+		BindPaths:        bindPaths,
 	}
 	server, err := mcp.NewHTTPServer(plugins, config)
 	if err != nil {
@@ -287,11 +448,17 @@ func runHTTP(plugins *plugin.Config, addr string, rateLimit int, rootDir string,
 		server.StartStreamableCleanup(ctx)
 	}
 
+	if watcherConfig != nil {
+		if err := startPluginWatcher(ctx, watcherConfig, server.SetPlugins); err != nil {
+			return err
+		}
+	}
+
 	httpServer := &http.Server{
 		Addr:         addr,
 		Handler:      server.Handler(),
 		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 60 * time.Second,
+		WriteTimeout: mcp.RequestTimeout(limits), // Long enough for the slowest permitted tool
 		IdleTimeout:  60 * time.Second,
 	}
 
@@ -326,7 +493,7 @@ func runHTTP(plugins *plugin.Config, addr string, rateLimit int, rootDir string,
 	return nil
 }
 
-func runBridge(addr string, upstream string, upstreamEnv []string, apiKey string, rateLimit int, maxResponseSize int, debug bool, database *db.DB, enableOAuth bool, oauthIssuer string, enableStreamable bool, sessionTTL time.Duration) error {
+func runBridge(addr string, upstream string, upstreamEnv []string, apiKey string, rateLimit int, maxResponseSize int, debug bool, database *db.DB, enableAPIKeys bool, enableOAuth bool, oauthIssuer string, enableStreamable bool, sessionTTL time.Duration) error {
 	// Parse upstream command with shell-like syntax support
 	parts, err := bridge.ParseCommand(upstream)
 	if err != nil {
@@ -347,6 +514,7 @@ func runBridge(addr string, upstream string, upstreamEnv []string, apiKey string
 		MaxResponseSize:  maxResponseSize,
 		Debug:            debug,
 		DB:               database,
+		EnableAPIKeys:    enableAPIKeys,
 		EnableOAuth:      enableOAuth,
 		OAuthIssuer:      oauthIssuer,
 		EnableStreamable: enableStreamable,
@@ -404,6 +572,59 @@ func runBridge(addr string, upstream string, upstreamEnv []string, apiKey string
 	return nil
 }
 
+// startPluginWatcher starts polling the plugin files and passes each valid reload to apply
+func startPluginWatcher(ctx context.Context, config *plugin.WatcherConfig, apply func(*plugin.Config)) error {
+	config.OnReload = func(plugins *plugin.Config) {
+		apply(plugins)
+		fmt.Fprintf(os.Stderr, "[plugins] reloaded %d tools from %s\n", len(plugins.ListTools()), config.Path)
+	}
+	watcher, err := plugin.NewWatcher(config)
+	if err != nil {
+		return fmt.Errorf("failed to watch plugins: %w", err)
+	}
+	go watcher.Run(ctx)
+	return nil
+}
+
+// validatePlugins validates every tool, including its execution limits and bwrap binds,
+// and the env key patterns of a plugin configuration
+func validatePlugins(plugins *plugin.Config, limits executor.Limits, rootDir string, bindPaths []string) error {
+	for _, tool := range plugins.ListTools() {
+		if err := policy.ValidateTool(tool); err != nil {
+			return fmt.Errorf("tool %q: %w", tool.Name, err)
+		}
+		if err := limits.ValidateTool(tool); err != nil {
+			return fmt.Errorf("tool %q: %w", tool.Name, err)
+		}
+		if err := executor.ValidateBwrapBinds(tool.Bwrap, rootDir, bindPaths); err != nil {
+			return fmt.Errorf("tool %q: %w", tool.Name, err)
+		}
+	}
+	return policy.ValidateAllowedEnvKeys(plugins.AllowedEnvKeys)
+}
+
+// checkSandboxes returns an error for the first tool whose declared sandbox is unavailable
+func checkSandboxes(plugins *plugin.Config, exec *executor.Executor) error {
+	for _, tool := range plugins.ListTools() {
+		if err := exec.CheckSandbox(context.Background(), tool); err != nil {
+			return fmt.Errorf("tool %q: %w", tool.Name, err)
+		}
+	}
+	return nil
+}
+
+// reportSandboxBackend tells which sandbox bubblewrap tools run in
+func reportSandboxBackend(mode executor.SandboxMode) {
+	switch mode {
+	case executor.SandboxBwrap:
+		fmt.Fprintf(os.Stderr, "Sandbox for bubblewrap tools: bwrap\n")
+	case executor.SandboxNamespace:
+		fmt.Fprintf(os.Stderr, "Sandbox for bubblewrap tools: native Linux namespaces (bwrap not installed)\n")
+	default:
+		fmt.Fprintf(os.Stderr, "[WARN] bwrap is not installed and Linux namespaces are unavailable; bubblewrap tools will fail\n")
+	}
+}
+
 func printLoadedTools(plugins *plugin.Config) {
 	tools := plugins.ListTools()
 	fmt.Println("=== Loaded Tools ===")
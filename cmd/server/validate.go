@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/takeshy/mcp-gatekeeper/internal/validate"
+)
+
+// runValidate implements the "validate" subcommand and returns the process exit code
+func runValidate(args []string) int {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	var (
+		pluginsDir = fs.String("plugins-dir", "", "Directory containing plugin JSON files")
+		pluginFile = fs.String("plugin-file", "", "Single plugin JSON file")
+		format     = fs.String("format", "json", "Report format: json or text")
+		strict     = fs.Bool("strict", false, "Treat warnings as errors")
+	)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s validate [--format=json|text] [--strict] (--plugins-dir=DIR | --plugin-file=FILE | PATH)\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	var path string
+	switch {
+	case *pluginsDir != "" && *pluginFile != "":
+		fmt.Fprintln(os.Stderr, "Error: --plugins-dir and --plugin-file are mutually exclusive")
+		return 2
+	case *pluginsDir != "":
+		path = *pluginsDir
+	case *pluginFile != "":
+		path = *pluginFile
+	case fs.NArg() == 1:
+		path = fs.Arg(0)
+	default:
+		fs.Usage()
+		return 2
+	}
+	if *format != "json" && *format != "text" {
+		fmt.Fprintf(os.Stderr, "Error: invalid format %q (must be json or text)\n", *format)
+		return 2
+	}
+
+	report := validate.Run(path)
+	if *strict && report.Warnings > 0 {
+		report.Valid = false
+	}
+
+	if *format == "text" {
+		printValidateReport(os.Stdout, report)
+	} else {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to encode report: %v\n", err)
+			return 2
+		}
+	}
+
+	if !report.Valid {
+		return 1
+	}
+	return 0
+}
+
+// printValidateReport writes a human-readable validation report
+func printValidateReport(w io.Writer, report *validate.Report) {
+	for _, issue := range report.Issues {
+		location := issue.File
+		if issue.Tool != "" {
+			location += ": " + issue.Tool
+		}
+		if location == "" {
+			location = report.Path
+		}
+		fmt.Fprintf(w, "%s: %s: %s\n", issue.Severity, location, issue.Message)
+	}
+	status := "OK"
+	if !report.Valid {
+		status = "FAILED"
+	}
+	fmt.Fprintf(w, "%s: %d files, %d tools, %d errors, %d warnings\n",
+		status, len(report.Files), report.Tools, report.Errors, report.Warnings)
+}
@@ -0,0 +1,91 @@
+package bridge
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"github.com/takeshy/mcp-gatekeeper/internal/db"
+)
+
+// callerContextKey is the context key for the caller of a request
+type callerContextKey struct{}
+
+// sessionIDContextKey is the context key for the MCP session ID of a Streamable HTTP request
+type sessionIDContextKey struct{}
+
+// caller identifies the client of a request
+type caller struct {
+	audit  db.AuditCaller // Recorded in audit logs
+	scopes []string       // Tools the caller may access (empty = unrestricted)
+}
+
+// newCaller describes the client of r. Principal and authMethod are empty if
+// authentication is disabled.
+func newCaller(r *http.Request, authMethod, principal string, scopes []string) *caller {
+	return &caller{
+		audit: db.AuditCaller{
+			Principal:  principal,
+			AuthMethod: authMethod,
+			ClientIP:   clientIP(r),
+			UserAgent:  r.UserAgent(),
+		},
+		scopes: scopes,
+	}
+}
+
+// clientIP returns the host part of the request's remote address
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// anonymousCallerMiddleware records the client of unauthenticated requests
+func anonymousCallerMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(withCaller(r.Context(), newCaller(r, "", "", nil))))
+	})
+}
+
+// withCaller returns a context carrying the caller of the request
+func withCaller(ctx context.Context, c *caller) context.Context {
+	return context.WithValue(ctx, callerContextKey{}, c)
+}
+
+// withSessionID returns a context carrying the MCP session ID of the request
+func withSessionID(ctx context.Context, sessionID string) context.Context {
+	return context.WithValue(ctx, sessionIDContextKey{}, sessionID)
+}
+
+// callerScopes returns the scopes of the request's caller (nil = unrestricted)
+func callerScopes(ctx context.Context) []string {
+	if c, ok := ctx.Value(callerContextKey{}).(*caller); ok {
+		return c.scopes
+	}
+	return nil
+}
+
+// auditCaller returns the audit details of the request's caller, or nil if there are none
+func auditCaller(ctx context.Context) *db.AuditCaller {
+	c, _ := ctx.Value(callerContextKey{}).(*caller)
+	sessionID, _ := ctx.Value(sessionIDContextKey{}).(string)
+	if c == nil && sessionID == "" {
+		return nil
+	}
+
+	audit := &db.AuditCaller{SessionID: sessionID}
+	if c != nil {
+		*audit = c.audit
+		audit.SessionID = sessionID
+	}
+	return audit
+}
+
+// toolAllowed reports whether the request's caller may access a tool.
+// Callers using the shared API key carry no scopes and may access every tool.
+func toolAllowed(ctx context.Context, toolName string) bool {
+	return db.ScopesAllowTool(callerScopes(ctx), toolName)
+}
@@ -0,0 +1,80 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// checkToolScope returns an error response if a tools/call request names a tool
+// outside the caller's scopes, or nil if the request may be forwarded
+func checkToolScope(ctx context.Context, req *Request) *Response {
+	if req.Method != "tools/call" {
+		return nil
+	}
+	var params struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return nil // Let upstream report invalid params
+	}
+	if toolAllowed(ctx, params.Name) {
+		return nil
+	}
+
+	fmt.Fprintf(os.Stderr, "[bridge] tool not permitted by token scope: %s\n", params.Name)
+	data, _ := json.Marshal(params.Name)
+	return &Response{
+		JSONRPC: "2.0",
+		ID:      req.ID,
+		Error: &RPCError{
+			Code:    -32001,
+			Message: "Insufficient scope",
+			Data:    data,
+		},
+	}
+}
+
+// filterToolsList removes tools outside the caller's scopes from a tools/list response
+func filterToolsList(ctx context.Context, method string, resp *Response) *Response {
+	if method != "tools/list" || resp == nil || resp.Result == nil {
+		return resp
+	}
+	if len(callerScopes(ctx)) == 0 {
+		return resp
+	}
+
+	var result map[string]json.RawMessage
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return resp
+	}
+	var tools []json.RawMessage
+	if err := json.Unmarshal(result["tools"], &tools); err != nil {
+		return resp
+	}
+
+	filtered := make([]json.RawMessage, 0, len(tools))
+	for _, tool := range tools {
+		var t struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal(tool, &t); err == nil && toolAllowed(ctx, t.Name) {
+			filtered = append(filtered, tool)
+		}
+	}
+
+	toolsJSON, err := json.Marshal(filtered)
+	if err != nil {
+		return resp
+	}
+	result["tools"] = toolsJSON
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return resp
+	}
+
+	newResp := *resp
+	newResp.Result = resultJSON
+	return &newResp
+}
@@ -0,0 +1,62 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestCheckToolScope(t *testing.T) {
+	ctx := withCaller(context.Background(), &caller{scopes: []string{"tools:git-*"}})
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		req     *Request
+		wantErr bool
+	}{
+		{"unscoped", context.Background(), &Request{Method: "tools/call", Params: json.RawMessage(`{"name":"ls"}`)}, false},
+		{"allowed", ctx, &Request{Method: "tools/call", Params: json.RawMessage(`{"name":"git-log"}`)}, false},
+		{"denied", ctx, &Request{Method: "tools/call", Params: json.RawMessage(`{"name":"ls"}`)}, true},
+		{"other method", ctx, &Request{Method: "tools/list"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := checkToolScope(tt.ctx, tt.req)
+			if (resp != nil) != tt.wantErr {
+				t.Fatalf("checkToolScope() = %+v, wantErr %v", resp, tt.wantErr)
+			}
+			if resp != nil && resp.Error.Code != -32001 {
+				t.Errorf("expected code -32001, got %d", resp.Error.Code)
+			}
+		})
+	}
+}
+
+func TestFilterToolsList(t *testing.T) {
+	ctx := withCaller(context.Background(), &caller{scopes: []string{"tools:git-*"}})
+	resp := &Response{
+		JSONRPC: "2.0",
+		Result:  json.RawMessage(`{"tools":[{"name":"git-status"},{"name":"ls"}]}`),
+	}
+
+	filtered := filterToolsList(ctx, "tools/list", resp)
+
+	var result struct {
+		Tools []struct {
+			Name string `json:"name"`
+		} `json:"tools"`
+	}
+	if err := json.Unmarshal(filtered.Result, &result); err != nil {
+		t.Fatalf("unmarshal result: %v", err)
+	}
+	if len(result.Tools) != 1 || result.Tools[0].Name != "git-status" {
+		t.Errorf("expected only git-status, got %+v", result.Tools)
+	}
+
+	// Unscoped requests see every tool
+	if got := filterToolsList(context.Background(), "tools/list", resp); got != resp {
+		t.Error("expected unscoped response to be returned unchanged")
+	}
+}
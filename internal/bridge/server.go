@@ -23,7 +23,10 @@ type Server struct {
 	client            *Client
 	router            chi.Router
 	apiKey            string
+	apiKeys           bool                    // Authenticate named API keys from the database
 	rateLimiter       *RateLimiter
+	keyLimiters       map[int64]*RateLimiter  // Per-key rate limiters for named API keys
+	keyLimitersMu     sync.Mutex
 	maxResponseSize   int
 	fileStore         *FileStore
 	debug             bool
@@ -50,6 +53,7 @@ type ServerConfig struct {
 	MaxResponseSize int           // Max response size in bytes (default 500000)
 	Debug           bool          // Enable debug logging
 	DB              *db.DB        // Optional database for audit logging
+	EnableAPIKeys   bool          // Enable named API keys (requires DB)
 	EnableOAuth     bool          // Enable OAuth authentication (requires DB)
 	OAuthIssuer     string        // OAuth issuer URL (optional, auto-detected if empty)
 	EnableStreamable bool         // Enable MCP Streamable HTTP (2025-06-18)
@@ -148,7 +152,9 @@ func NewServer(config *ServerConfig) (*Server, error) {
 
 	s := &Server{
 		apiKey:          config.APIKey,
+		apiKeys:         config.EnableAPIKeys && config.DB != nil,
 		rateLimiter:     NewRateLimiter(rateLimit, rateLimitWindow),
+		keyLimiters:     make(map[int64]*RateLimiter),
 		maxResponseSize: maxResponseSize,
 		fileStore:       fileStore,
 		debug:           config.Debug,
@@ -195,7 +201,7 @@ func (s *Server) setupRoutes() {
 
 	// File retrieval endpoint
 	r.Group(func(r chi.Router) {
-		if s.apiKey != "" || s.oauthHandler != nil {
+		if s.apiKey != "" || s.apiKeys || s.oauthHandler != nil {
 			r.Use(s.authMiddleware)
 		}
 		r.Get("/files/{key}", s.handleFileGet)
@@ -203,8 +209,10 @@ func (s *Server) setupRoutes() {
 
 	// MCP JSON-RPC endpoint
 	r.Group(func(r chi.Router) {
-		if s.apiKey != "" || s.oauthHandler != nil {
+		if s.apiKey != "" || s.apiKeys || s.oauthHandler != nil {
 			r.Use(s.authMiddleware)
+		} else {
+			r.Use(anonymousCallerMiddleware)
 		}
 		r.Use(s.rateLimitMiddleware)
 		if s.streamableHandler != nil {
@@ -269,7 +277,7 @@ func (s *Server) Close() error {
 	return nil
 }
 
-// authMiddleware handles API key and OAuth token authentication
+// authMiddleware handles API key, named API key and OAuth token authentication
 func (s *Server) authMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
@@ -292,17 +300,37 @@ func (s *Server) authMiddleware(next http.Handler) http.Handler {
 		if s.apiKey != "" {
 			if subtle.ConstantTimeCompare([]byte(token), []byte(s.apiKey)) == 1 {
 				authenticated = true
+				r = r.WithContext(withCaller(r.Context(), newCaller(r, db.AuthMethodAPIKey, "api_key", nil)))
+			}
+		}
+
+		// Try named API keys (if enabled and the shared API key didn't match)
+		if !authenticated && s.apiKeys {
+			key, err := s.db.ValidateAPIKey(token)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "[WARN] API key validation error: %v\n", err)
+			}
+			if key != nil {
+				if !s.allowAPIKey(key) {
+					s.writeError(w, http.StatusTooManyRequests, "rate limit exceeded")
+					return
+				}
+				authenticated = true
+				// Tools are restricted to the key's allowlist
+				r = r.WithContext(withCaller(r.Context(), newCaller(r, db.AuthMethodAPIKey, key.Principal(), key.Scopes())))
 			}
 		}
 
-		// Try OAuth token authentication (if enabled and API key didn't match)
+		// Try OAuth token authentication (if enabled and no API key matched)
 		if !authenticated && s.oauthHandler != nil {
-			client, err := s.oauthHandler.ValidateAccessToken(r)
+			client, scopes, err := s.oauthHandler.ValidateAccessToken(r)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "[WARN] OAuth token validation error: %v\n", err)
 			}
 			if client != nil {
 				authenticated = true
+				// Tools are restricted to the token's scopes
+				r = r.WithContext(withCaller(r.Context(), newCaller(r, db.AuthMethodOAuth, client.Principal(), scopes)))
 			}
 		}
 
@@ -316,6 +344,23 @@ func (s *Server) authMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// allowAPIKey checks the per-key rate limit of a named API key
+func (s *Server) allowAPIKey(key *db.APIKey) bool {
+	if key.RateLimit <= 0 {
+		return true
+	}
+
+	s.keyLimitersMu.Lock()
+	limiter, ok := s.keyLimiters[key.ID]
+	if !ok || limiter.limit != key.RateLimit {
+		limiter = NewRateLimiter(key.RateLimit, time.Duration(s.rateLimiter.windowNano))
+		s.keyLimiters[key.ID] = limiter
+	}
+	s.keyLimitersMu.Unlock()
+
+	return limiter.Allow()
+}
+
 // rateLimitMiddleware handles rate limiting
 func (s *Server) rateLimitMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -383,7 +428,7 @@ func (s *Server) handleMCP(w http.ResponseWriter, r *http.Request) {
 			},
 		}
 		s.writeJSONRPC(w, resp)
-		s.logAudit("", "", resp, err, startTime)
+		s.logAudit(ctx, "", "", resp, err, startTime)
 		return
 	}
 
@@ -398,7 +443,7 @@ func (s *Server) handleMCP(w http.ResponseWriter, r *http.Request) {
 			},
 		}
 		s.writeJSONRPC(w, resp)
-		s.logAudit("", string(rawReq), resp, err, startTime)
+		s.logAudit(ctx, "", string(rawReq), resp, err, startTime)
 		return
 	}
 
@@ -414,7 +459,14 @@ func (s *Server) handleMCP(w http.ResponseWriter, r *http.Request) {
 
 	// Handle initialize locally (don't forward, don't require upstream)
 	if req.Method == "initialize" {
-		s.handleInitializeWithAudit(w, &req, string(rawReq), startTime)
+		s.handleInitializeWithAudit(ctx, w, &req, string(rawReq), startTime)
+		return
+	}
+
+	// Enforce the caller's tool scopes before forwarding tool calls
+	if errResp := checkToolScope(ctx, &req); errResp != nil {
+		s.writeJSONRPC(w, errResp)
+		s.logAudit(ctx, req.Method, string(rawReq), errResp, fmt.Errorf("insufficient scope"), startTime)
 		return
 	}
 
@@ -439,7 +491,7 @@ func (s *Server) handleMCP(w http.ResponseWriter, r *http.Request) {
 					},
 				}
 				s.writeJSONRPC(w, resp)
-				s.logAudit(req.Method, string(rawReq), resp, err, startTime)
+				s.logAudit(ctx, req.Method, string(rawReq), resp, err, startTime)
 				return
 			}
 		}
@@ -459,17 +511,20 @@ func (s *Server) handleMCP(w http.ResponseWriter, r *http.Request) {
 			},
 		}
 		s.writeJSONRPC(w, errResp)
-		s.logAudit(req.Method, string(rawReq), errResp, err, startTime)
+		s.logAudit(ctx, req.Method, string(rawReq), errResp, err, startTime)
 		return
 	}
 
 	// Notification (no response)
 	if resp == nil {
 		w.WriteHeader(http.StatusOK)
-		s.logAudit(req.Method, string(rawReq), nil, nil, startTime)
+		s.logAudit(ctx, req.Method, string(rawReq), nil, nil, startTime)
 		return
 	}
 
+	// Hide tools outside the token's scopes
+	resp = filterToolsList(ctx, req.Method, resp)
+
 	// Save original response for audit logging (before externalization)
 	originalResp := resp
 
@@ -506,7 +561,7 @@ func (s *Server) handleMCP(w http.ResponseWriter, r *http.Request) {
 			},
 		}
 		s.writeJSONRPC(w, errResp)
-		s.logAudit(req.Method, string(rawReq), errResp, err, startTime)
+		s.logAudit(ctx, req.Method, string(rawReq), errResp, err, startTime)
 		return
 	}
 
@@ -521,17 +576,17 @@ func (s *Server) handleMCP(w http.ResponseWriter, r *http.Request) {
 			},
 		}
 		s.writeJSONRPC(w, errResp)
-		s.logAudit(req.Method, string(rawReq), errResp, fmt.Errorf("response too large"), startTime)
+		s.logAudit(ctx, req.Method, string(rawReq), errResp, fmt.Errorf("response too large"), startTime)
 		return
 	}
 
 	s.writeJSONRPC(w, resp)
 	// Log original response (before externalization) for audit
-	s.logAudit(req.Method, string(rawReq), originalResp, nil, startTime)
+	s.logAudit(ctx, req.Method, string(rawReq), originalResp, nil, startTime)
 }
 
 // handleInitializeWithAudit handles initialize requests locally with audit logging
-func (s *Server) handleInitializeWithAudit(w http.ResponseWriter, req *Request, params string, startTime time.Time) {
+func (s *Server) handleInitializeWithAudit(ctx context.Context, w http.ResponseWriter, req *Request, params string, startTime time.Time) {
 	// Return bridge server info, forwarding upstream capabilities
 	capabilities := map[string]interface{}{
 		"tools": map[string]interface{}{
@@ -560,7 +615,7 @@ func (s *Server) handleInitializeWithAudit(w http.ResponseWriter, req *Request,
 		Result:  resultJSON,
 	}
 	s.writeJSONRPC(w, resp)
-	s.logAudit(req.Method, params, resp, nil, startTime)
+	s.logAudit(ctx, req.Method, params, resp, nil, startTime)
 }
 
 func (s *Server) maybeSetWWWAuthenticate(w http.ResponseWriter, r *http.Request) {
@@ -604,7 +659,7 @@ func (s *Server) writeError(w http.ResponseWriter, status int, message string) {
 }
 
 // logAudit logs an MCP request/response to the database if configured
-func (s *Server) logAudit(method string, params string, resp *Response, err error, startTime time.Time) {
+func (s *Server) logAudit(ctx context.Context, method string, params string, resp *Response, err error, startTime time.Time) {
 	if s.db == nil {
 		return
 	}
@@ -623,7 +678,7 @@ func (s *Server) logAudit(method string, params string, resp *Response, err erro
 		}
 	}
 
-	if logErr := s.db.LogAudit(db.AuditModeBridge, method, toolName, params, resp, err, startTime); logErr != nil {
+	if logErr := s.db.LogAudit(db.AuditModeBridge, auditCaller(ctx), method, toolName, params, resp, err, startTime); logErr != nil {
 		fmt.Fprintf(os.Stderr, "[WARN] Failed to log audit: %v\n", logErr)
 	}
 }
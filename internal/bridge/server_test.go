@@ -423,3 +423,67 @@ func TestInitializeIncludesOAuthExtensionWhenOAuthEnabled(t *testing.T) {
 		t.Fatalf("expected oauth client credentials extension, got %v", extensions)
 	}
 }
+
+func TestNamedAPIKeyAuth(t *testing.T) {
+	database := newBridgeTestDB(t)
+	key, err := database.CreateAPIKey("ci", []string{"git-*"}, 0, nil)
+	if err != nil {
+		t.Fatalf("CreateAPIKey: %v", err)
+	}
+
+	config := &ServerConfig{
+		Command:       "echo",
+		EnableAPIKeys: true,
+		DB:            database,
+	}
+
+	server, err := NewServer(config)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	defer server.Close()
+
+	post := func(authHeader, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		if authHeader != "" {
+			req.Header.Set("Authorization", authHeader)
+		}
+		w := httptest.NewRecorder()
+		server.Handler().ServeHTTP(w, req)
+		return w
+	}
+
+	if w := post("Bearer wrong-key", `{"jsonrpc":"2.0","id":1,"method":"initialize"}`); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status 401, got %d", w.Code)
+	}
+	if w := post("Bearer "+key, `{"jsonrpc":"2.0","id":1,"method":"initialize"}`); w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+
+	// Tool calls outside the key's allowlist are rejected before reaching upstream
+	w := post("Bearer "+key, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"ls"}}`)
+	var resp Response
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if resp.Error == nil || resp.Error.Code != -32001 {
+		t.Fatalf("expected insufficient scope error, got %+v", resp.Error)
+	}
+
+	entries, err := database.ListAuditLogs(db.AuditModeBridge, 10, 0)
+	if err != nil {
+		t.Fatalf("ListAuditLogs: %v", err)
+	}
+	for _, entry := range entries {
+		if entry.Principal != "api_key:ci" || entry.AuthMethod != db.AuthMethodAPIKey {
+			t.Errorf("expected api_key:ci authenticated by API key, got %+v", entry.AuditCaller)
+		}
+		if entry.ClientIP != "192.0.2.1" {
+			t.Errorf("expected client IP 192.0.2.1, got %q", entry.ClientIP)
+		}
+	}
+	if len(entries) != 2 {
+		t.Errorf("expected 2 audit entries, got %d", len(entries))
+	}
+}
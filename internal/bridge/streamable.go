@@ -72,7 +72,7 @@ func (h *StreamableHandler) HandlePost(w http.ResponseWriter, r *http.Request) {
 			},
 		}
 		h.writeJSONRPC(w, resp)
-		h.server.logAudit("", "", resp, err, startTime)
+		h.server.logAudit(ctx, "", "", resp, err, startTime)
 		return
 	}
 
@@ -86,7 +86,7 @@ func (h *StreamableHandler) HandlePost(w http.ResponseWriter, r *http.Request) {
 			},
 		}
 		h.writeJSONRPC(w, resp)
-		h.server.logAudit("", string(rawReq), resp, err, startTime)
+		h.server.logAudit(ctx, "", string(rawReq), resp, err, startTime)
 		return
 	}
 
@@ -137,6 +137,14 @@ func (h *StreamableHandler) HandlePost(w http.ResponseWriter, r *http.Request) {
 
 	// Touch session
 	h.sessionManager.Touch(sessionID)
+	ctx = withSessionID(ctx, sessionID)
+
+	// Enforce the caller's tool scopes before forwarding tool calls
+	if errResp := checkToolScope(ctx, &req); errResp != nil {
+		h.writeJSONRPC(w, errResp)
+		h.server.logAudit(ctx, req.Method, string(rawReq), errResp, fmt.Errorf("insufficient scope"), startTime)
+		return
+	}
 
 	// Handle notifications - forward to upstream
 	if req.ID == nil || string(req.ID) == "null" {
@@ -145,7 +153,7 @@ func (h *StreamableHandler) HandlePost(w http.ResponseWriter, r *http.Request) {
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "[bridge] notification forward error: %v\n", err)
 		}
-		h.server.logAudit(req.Method, string(rawReq), nil, err, startTime)
+		h.server.logAudit(ctx, req.Method, string(rawReq), nil, err, startTime)
 		w.WriteHeader(http.StatusAccepted)
 		return
 	}
@@ -163,17 +171,20 @@ func (h *StreamableHandler) HandlePost(w http.ResponseWriter, r *http.Request) {
 			},
 		}
 		h.writeJSONRPC(w, errResp)
-		h.server.logAudit(req.Method, string(rawReq), errResp, err, startTime)
+		h.server.logAudit(ctx, req.Method, string(rawReq), errResp, err, startTime)
 		return
 	}
 
 	// Notification (no response)
 	if resp == nil {
 		w.WriteHeader(http.StatusAccepted)
-		h.server.logAudit(req.Method, string(rawReq), nil, nil, startTime)
+		h.server.logAudit(ctx, req.Method, string(rawReq), nil, nil, startTime)
 		return
 	}
 
+	// Hide tools outside the token's scopes
+	resp = filterToolsList(ctx, req.Method, resp)
+
 	// Externalize large content
 	originalResp := resp
 	resp = h.server.externalizeLargeContent(resp, r.Host)
@@ -190,7 +201,7 @@ func (h *StreamableHandler) HandlePost(w http.ResponseWriter, r *http.Request) {
 			},
 		}
 		h.writeJSONRPC(w, errResp)
-		h.server.logAudit(req.Method, string(rawReq), errResp, err, startTime)
+		h.server.logAudit(ctx, req.Method, string(rawReq), errResp, err, startTime)
 		return
 	}
 
@@ -205,12 +216,12 @@ func (h *StreamableHandler) HandlePost(w http.ResponseWriter, r *http.Request) {
 			},
 		}
 		h.writeJSONRPC(w, errResp)
-		h.server.logAudit(req.Method, string(rawReq), errResp, fmt.Errorf("response too large"), startTime)
+		h.server.logAudit(ctx, req.Method, string(rawReq), errResp, fmt.Errorf("response too large"), startTime)
 		return
 	}
 
 	h.writeJSONRPC(w, resp)
-	h.server.logAudit(req.Method, string(rawReq), originalResp, nil, startTime)
+	h.server.logAudit(ctx, req.Method, string(rawReq), originalResp, nil, startTime)
 }
 
 // handleInitialize handles the initialize request - creates new session with upstream
@@ -228,7 +239,7 @@ func (h *StreamableHandler) handleInitialize(w http.ResponseWriter, r *http.Requ
 			},
 		}
 		h.writeJSONRPC(w, resp)
-		h.server.logAudit(req.Method, string(rawReq), resp, err, startTime)
+		h.server.logAudit(r.Context(), req.Method, string(rawReq), resp, err, startTime)
 		return
 	}
 
@@ -243,7 +254,7 @@ func (h *StreamableHandler) handleInitialize(w http.ResponseWriter, r *http.Requ
 			},
 		}
 		h.writeJSONRPC(w, resp)
-		h.server.logAudit(req.Method, string(rawReq), resp, fmt.Errorf("unsupported protocol version"), startTime)
+		h.server.logAudit(r.Context(), req.Method, string(rawReq), resp, fmt.Errorf("unsupported protocol version"), startTime)
 		return
 	}
 
@@ -260,7 +271,7 @@ func (h *StreamableHandler) handleInitialize(w http.ResponseWriter, r *http.Requ
 			},
 		}
 		h.writeJSONRPC(w, resp)
-		h.server.logAudit(req.Method, string(rawReq), resp, err, startTime)
+		h.server.logAudit(r.Context(), req.Method, string(rawReq), resp, err, startTime)
 		return
 	}
 
@@ -297,7 +308,7 @@ func (h *StreamableHandler) handleInitialize(w http.ResponseWriter, r *http.Requ
 	// Set session ID header
 	w.Header().Set(HeaderMcpSessionID, session.ID)
 	h.writeJSONRPC(w, resp)
-	h.server.logAudit(req.Method, string(rawReq), resp, nil, startTime)
+	h.server.logAudit(withSessionID(r.Context(), session.ID), req.Method, string(rawReq), resp, nil, startTime)
 }
 
 // HandleGet handles GET /mcp requests (SSE stream)
@@ -0,0 +1,199 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// APIKey represents a named API key
+type APIKey struct {
+	ID        int64
+	Name      string
+	KeyHash   string
+	Tools     []string // Tool name globs the key may call (empty = unrestricted)
+	RateLimit int      // Requests per rate limit window (0 = server limit only)
+	Status    string
+	ExpiresAt *time.Time
+	CreatedAt time.Time
+	RevokedAt *time.Time
+}
+
+// Scopes returns the key's tool allowlist as "tools:<glob>" scopes
+func (k *APIKey) Scopes() []string {
+	return toolScopes(k.Tools)
+}
+
+// Principal returns the caller identity recorded in audit logs
+func (k *APIKey) Principal() string {
+	return "api_key:" + k.Name
+}
+
+// toolScopes converts tool name globs to "tools:<glob>" scopes
+func toolScopes(tools []string) []string {
+	if len(tools) == 0 {
+		return nil
+	}
+	scopes := make([]string, len(tools))
+	for i, tool := range tools {
+		scopes[i] = ToolScopePrefix + tool
+	}
+	return scopes
+}
+
+// CreateAPIKey creates a new named API key and returns the generated key.
+// Tools restricts the tools the key may call (nil allows every tool), rateLimit
+// of 0 applies only the server limit, and a nil expiresAt never expires.
+func (d *DB) CreateAPIKey(name string, tools []string, rateLimit int, expiresAt *time.Time) (key string, err error) {
+	if strings.TrimSpace(name) == "" {
+		return "", fmt.Errorf("API key name cannot be empty")
+	}
+	if rateLimit < 0 {
+		return "", fmt.Errorf("rate limit cannot be negative")
+	}
+	if err := ValidateScopes(toolScopes(tools)); err != nil {
+		return "", err
+	}
+
+	key, err = generateSecureToken(32)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+
+	_, err = d.db.Exec(`
+		INSERT INTO api_keys (name, key_hash, tools, rate_limit, status, expires_at)
+		VALUES (?, ?, ?, ?, 'active', ?)
+	`, name, hashToken(key), strings.Join(tools, " "), rateLimit, expiresAt)
+	if err != nil {
+		return "", fmt.Errorf("failed to insert API key: %w", err)
+	}
+
+	return key, nil
+}
+
+// scanAPIKey scans an api_keys row
+func scanAPIKey(scan func(dest ...interface{}) error) (*APIKey, error) {
+	key := &APIKey{}
+	var tools string
+	var expiresAt, revokedAt sql.NullTime
+	if err := scan(&key.ID, &key.Name, &key.KeyHash, &tools, &key.RateLimit, &key.Status, &expiresAt, &key.CreatedAt, &revokedAt); err != nil {
+		return nil, err
+	}
+	key.Tools = strings.Fields(tools)
+	if expiresAt.Valid {
+		key.ExpiresAt = &expiresAt.Time
+	}
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+	return key, nil
+}
+
+// GetAPIKey retrieves an API key by name
+func (d *DB) GetAPIKey(name string) (*APIKey, error) {
+	row := d.db.QueryRow(`
+		SELECT id, name, key_hash, tools, rate_limit, status, expires_at, created_at, revoked_at
+		FROM api_keys
+		WHERE name = ?
+	`, name)
+
+	key, err := scanAPIKey(row.Scan)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+	return key, nil
+}
+
+// ListAPIKeys retrieves all API keys
+func (d *DB) ListAPIKeys() ([]*APIKey, error) {
+	rows, err := d.db.Query(`
+		SELECT id, name, key_hash, tools, rate_limit, status, expires_at, created_at, revoked_at
+		FROM api_keys
+		ORDER BY created_at DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []*APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows.Scan)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan API key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// RevokeAPIKey revokes an API key
+func (d *DB) RevokeAPIKey(id int64) error {
+	result, err := d.db.Exec(`
+		UPDATE api_keys
+		SET status = 'revoked', revoked_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND status = 'active'
+	`, id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("API key not found or already revoked")
+	}
+
+	return nil
+}
+
+// DeleteAPIKey permanently deletes an API key
+func (d *DB) DeleteAPIKey(id int64) error {
+	result, err := d.db.Exec(`DELETE FROM api_keys WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete API key: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("API key not found")
+	}
+
+	return nil
+}
+
+// ValidateAPIKey returns the active, unexpired API key matching key, or nil if there is none
+func (d *DB) ValidateAPIKey(key string) (*APIKey, error) {
+	row := d.db.QueryRow(`
+		SELECT id, name, key_hash, tools, rate_limit, status, expires_at, created_at, revoked_at
+		FROM api_keys
+		WHERE key_hash = ?
+	`, hashToken(key))
+
+	apiKey, err := scanAPIKey(row.Scan)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to validate API key: %w", err)
+	}
+
+	if apiKey.Status != "active" {
+		return nil, nil
+	}
+	if apiKey.ExpiresAt != nil && time.Now().After(*apiKey.ExpiresAt) {
+		return nil, nil
+	}
+
+	return apiKey, nil
+}
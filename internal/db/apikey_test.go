@@ -0,0 +1,142 @@
+package db
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestAPIKeyCRUD(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "test-apikey-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpFile.Close()
+	defer os.Remove(tmpFile.Name())
+
+	db, err := Open(tmpFile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// Invalid tool globs and names are rejected
+	if _, err := db.CreateAPIKey("bad-glob", []string{"[git"}, 0, nil); err == nil {
+		t.Fatal("Expected error for invalid tool glob")
+	}
+	if _, err := db.CreateAPIKey(" ", nil, 0, nil); err == nil {
+		t.Fatal("Expected error for empty name")
+	}
+
+	key, err := db.CreateAPIKey("ci", []string{"git-*", "ls"}, 10, nil)
+	if err != nil {
+		t.Fatalf("CreateAPIKey failed: %v", err)
+	}
+	if key == "" {
+		t.Fatal("Expected non-empty API key")
+	}
+
+	// Duplicate names are rejected
+	if _, err := db.CreateAPIKey("ci", nil, 0, nil); err == nil {
+		t.Fatal("Expected error for duplicate name")
+	}
+
+	stored, err := db.GetAPIKey("ci")
+	if err != nil {
+		t.Fatalf("GetAPIKey failed: %v", err)
+	}
+	if stored.KeyHash == key {
+		t.Error("Expected key to be stored hashed")
+	}
+	if len(stored.Tools) != 2 || stored.Tools[0] != "git-*" || stored.Tools[1] != "ls" {
+		t.Errorf("Expected tools to round-trip, got %v", stored.Tools)
+	}
+	if stored.RateLimit != 10 {
+		t.Errorf("Expected rate limit 10, got %d", stored.RateLimit)
+	}
+	if scopes := stored.Scopes(); len(scopes) != 2 || scopes[0] != "tools:git-*" {
+		t.Errorf("Expected tool scopes, got %v", scopes)
+	}
+	if stored.Principal() != "api_key:ci" {
+		t.Errorf("Expected principal 'api_key:ci', got %q", stored.Principal())
+	}
+
+	// Validate
+	validated, err := db.ValidateAPIKey(key)
+	if err != nil {
+		t.Fatalf("ValidateAPIKey failed: %v", err)
+	}
+	if validated == nil || validated.Name != "ci" {
+		t.Fatalf("Expected key 'ci' to be valid, got %+v", validated)
+	}
+	validated, err = db.ValidateAPIKey("wrong-key")
+	if err != nil {
+		t.Fatalf("ValidateAPIKey failed: %v", err)
+	}
+	if validated != nil {
+		t.Fatal("Expected wrong key to fail validation")
+	}
+
+	// Revoke
+	if err := db.RevokeAPIKey(stored.ID); err != nil {
+		t.Fatalf("RevokeAPIKey failed: %v", err)
+	}
+	if err := db.RevokeAPIKey(stored.ID); err == nil {
+		t.Error("Expected error revoking already revoked key")
+	}
+	validated, err = db.ValidateAPIKey(key)
+	if err != nil {
+		t.Fatalf("ValidateAPIKey failed: %v", err)
+	}
+	if validated != nil {
+		t.Fatal("Expected revoked key to fail validation")
+	}
+
+	// List and delete
+	keys, err := db.ListAPIKeys()
+	if err != nil {
+		t.Fatalf("ListAPIKeys failed: %v", err)
+	}
+	if len(keys) != 1 {
+		t.Fatalf("Expected 1 key, got %d", len(keys))
+	}
+	if err := db.DeleteAPIKey(stored.ID); err != nil {
+		t.Fatalf("DeleteAPIKey failed: %v", err)
+	}
+	if err := db.DeleteAPIKey(stored.ID); err == nil {
+		t.Error("Expected error deleting missing key")
+	}
+}
+
+func TestAPIKeyExpiry(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "test-apikey-expiry-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpFile.Close()
+	defer os.Remove(tmpFile.Name())
+
+	db, err := Open(tmpFile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	past := time.Now().Add(-time.Hour)
+	expiredKey, err := db.CreateAPIKey("expired", nil, 0, &past)
+	if err != nil {
+		t.Fatalf("CreateAPIKey failed: %v", err)
+	}
+	future := time.Now().Add(time.Hour)
+	activeKey, err := db.CreateAPIKey("active", nil, 0, &future)
+	if err != nil {
+		t.Fatalf("CreateAPIKey failed: %v", err)
+	}
+
+	if validated, err := db.ValidateAPIKey(expiredKey); err != nil || validated != nil {
+		t.Errorf("Expected expired key to fail validation, got %+v, %v", validated, err)
+	}
+	if validated, err := db.ValidateAPIKey(activeKey); err != nil || validated == nil {
+		t.Errorf("Expected unexpired key to be valid, got %+v, %v", validated, err)
+	}
+}
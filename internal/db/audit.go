@@ -1,7 +1,10 @@
 package db
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"strings"
 	"time"
 )
 
@@ -14,6 +17,21 @@ const (
 	AuditModeStdio  AuditMode = "stdio"
 )
 
+// Authentication methods recorded in audit logs
+const (
+	AuthMethodAPIKey = "api_key"
+	AuthMethodOAuth  = "oauth"
+)
+
+// AuditCaller identifies who made an audited request
+type AuditCaller struct {
+	Principal  string // Caller identity (e.g. "api_key:ci", "oauth:myclient"), empty if unauthenticated
+	AuthMethod string // AuthMethodAPIKey or AuthMethodOAuth, empty if unauthenticated
+	ClientIP   string
+	UserAgent  string
+	SessionID  string // MCP session ID (Streamable HTTP)
+}
+
 // AuditEntry represents a single audit log entry
 type AuditEntry struct {
 	ID           int64
@@ -27,58 +45,133 @@ type AuditEntry struct {
 	ResponseSize int
 	DurationMs   int64
 	CreatedAt    time.Time
+	AuditCaller
 }
 
-// LogAudit creates an audit log entry
-func (d *DB) LogAudit(mode AuditMode, method string, toolName string, params interface{}, response interface{}, err error, startTime time.Time) error {
+// AuditDigest stands in for a request or response body that is stored only as its
+// size and SHA-256 hash
+type AuditDigest struct {
+	Bytes  int    `json:"bytes"`
+	SHA256 string `json:"sha256"`
+}
+
+// NewAuditDigest returns the size and hash of v marshaled to JSON
+func NewAuditDigest(v interface{}) *AuditDigest {
+	data, _ := json.Marshal(v)
+	sum := sha256.Sum256(data)
+	return &AuditDigest{Bytes: len(data), SHA256: hex.EncodeToString(sum[:])}
+}
+
+// LogAudit creates an audit log entry.
+// Caller identifies who made the request and may be nil.
+// Secrets are redacted from the params, response and error before they are stored;
+// request and response sizes are those of the bodies before redaction.
+// A params or response given as an *AuditDigest is stored as is, with its size.
+func (d *DB) LogAudit(mode AuditMode, caller *AuditCaller, method string, toolName string, params interface{}, response interface{}, err error, startTime time.Time) error {
 	duration := time.Since(startTime).Milliseconds()
 
-	var paramsJSON string
-	var responseJSON string
-	var errorStr string
-	var requestSize int
-	var responseSize int
+	paramsJSON, requestSize := d.auditBody(params)
+	responseJSON, responseSize := d.auditBody(response)
 
-	if params != nil {
-		if data, marshalErr := json.Marshal(params); marshalErr == nil {
-			paramsJSON = string(data)
-			requestSize = len(data)
-		}
+	var errorStr string
+	if err != nil {
+		errorStr = d.redactor.redact(err.Error())
 	}
 
-	if response != nil {
-		if data, marshalErr := json.Marshal(response); marshalErr == nil {
-			responseJSON = string(data)
-			responseSize = len(data)
-		}
+	if caller == nil {
+		caller = &AuditCaller{}
 	}
 
+	_, execErr := d.db.Exec(`
+		INSERT INTO audit_logs (mode, principal, auth_method, client_ip, user_agent, session_id, method, tool_name, params, response, error, request_size, response_size, duration_ms)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, string(mode), nullString(caller.Principal), nullString(caller.AuthMethod), nullString(caller.ClientIP), nullString(caller.UserAgent), nullString(caller.SessionID), method, toolName, paramsJSON, responseJSON, errorStr, requestSize, responseSize, duration)
+
+	return execErr
+}
+
+// auditBody marshals a request or response body for the audit log and returns it with
+// its size; a nil body is stored as an empty string
+func (d *DB) auditBody(body interface{}) (string, int) {
+	if body == nil {
+		return "", 0
+	}
+	data, err := json.Marshal(body)
 	if err != nil {
-		errorStr = err.Error()
+		return "", 0
 	}
+	if digest, ok := body.(*AuditDigest); ok {
+		return string(data), digest.Bytes
+	}
+	return d.redactor.redactJSON(string(data)), len(data)
+}
 
-	_, execErr := d.db.Exec(`
-		INSERT INTO audit_logs (mode, method, tool_name, params, response, error, request_size, response_size, duration_ms)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`, string(mode), method, toolName, paramsJSON, responseJSON, errorStr, requestSize, responseSize, duration)
+// nullString converts an empty string to NULL
+func nullString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
 
-	return execErr
+// AuditFilter selects audit log entries; zero-valued fields match every entry
+type AuditFilter struct {
+	Mode      AuditMode
+	Principal string
+	SessionID string
+	ClientIP  string
+	ToolName  string
+	Since     time.Time // Entries created at or after Since
+	Until     time.Time // Entries created before Until
 }
 
+// auditTimeFormat matches the format of CURRENT_TIMESTAMP (UTC)
+const auditTimeFormat = "2006-01-02 15:04:05"
+
 // ListAuditLogs retrieves audit logs with optional filtering
 func (d *DB) ListAuditLogs(mode AuditMode, limit int, offset int) ([]*AuditEntry, error) {
+	return d.QueryAuditLogs(AuditFilter{Mode: mode}, limit, offset)
+}
+
+// QueryAuditLogs retrieves audit logs matching the filter, newest first
+func (d *DB) QueryAuditLogs(filter AuditFilter, limit int, offset int) ([]*AuditEntry, error) {
 	query := `
-		SELECT id, mode, method, tool_name, params, response, error, request_size, response_size, duration_ms, created_at
+		SELECT id, mode, principal, auth_method, client_ip, user_agent, session_id, method, tool_name, params, response, error, request_size, response_size, duration_ms, created_at
 		FROM audit_logs
 	`
+	var conditions []string
 	var args []interface{}
 
-	if mode != "" {
-		query += " WHERE mode = ?"
-		args = append(args, string(mode))
+	addCondition := func(condition string, arg interface{}) {
+		conditions = append(conditions, condition)
+		args = append(args, arg)
+	}
+	if filter.Mode != "" {
+		addCondition("mode = ?", string(filter.Mode))
+	}
+	if filter.Principal != "" {
+		addCondition("principal = ?", filter.Principal)
+	}
+	if filter.SessionID != "" {
+		addCondition("session_id = ?", filter.SessionID)
+	}
+	if filter.ClientIP != "" {
+		addCondition("client_ip = ?", filter.ClientIP)
+	}
+	if filter.ToolName != "" {
+		addCondition("tool_name = ?", filter.ToolName)
+	}
+	if !filter.Since.IsZero() {
+		addCondition("created_at >= ?", filter.Since.UTC().Format(auditTimeFormat))
+	}
+	if !filter.Until.IsZero() {
+		addCondition("created_at < ?", filter.Until.UTC().Format(auditTimeFormat))
+	}
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
 	}
 
-	query += " ORDER BY created_at DESC LIMIT ? OFFSET ?"
+	query += " ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?"
 	args = append(args, limit, offset)
 
 	rows, err := d.db.Query(query, args...)
@@ -90,10 +183,15 @@ func (d *DB) ListAuditLogs(mode AuditMode, limit int, offset int) ([]*AuditEntry
 	var entries []*AuditEntry
 	for rows.Next() {
 		entry := &AuditEntry{}
-		var toolName, params, response, errorStr *string
+		var principal, authMethod, clientIP, userAgent, sessionID, toolName, params, response, errorStr *string
 		if err := rows.Scan(
 			&entry.ID,
 			&entry.Mode,
+			&principal,
+			&authMethod,
+			&clientIP,
+			&userAgent,
+			&sessionID,
 			&entry.Method,
 			&toolName,
 			&params,
@@ -106,24 +204,29 @@ func (d *DB) ListAuditLogs(mode AuditMode, limit int, offset int) ([]*AuditEntry
 		); err != nil {
 			return nil, err
 		}
-		if toolName != nil {
-			entry.ToolName = *toolName
-		}
-		if params != nil {
-			entry.Params = *params
-		}
-		if response != nil {
-			entry.Response = *response
-		}
-		if errorStr != nil {
-			entry.Error = *errorStr
-		}
+		entry.Principal = derefString(principal)
+		entry.AuthMethod = derefString(authMethod)
+		entry.ClientIP = derefString(clientIP)
+		entry.UserAgent = derefString(userAgent)
+		entry.SessionID = derefString(sessionID)
+		entry.ToolName = derefString(toolName)
+		entry.Params = derefString(params)
+		entry.Response = derefString(response)
+		entry.Error = derefString(errorStr)
 		entries = append(entries, entry)
 	}
 
 	return entries, nil
 }
 
+// derefString returns the value of a nullable string column
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
 // GetAuditStats returns statistics about audit logs
 func (d *DB) GetAuditStats() (map[AuditMode]int64, error) {
 	rows, err := d.db.Query(`
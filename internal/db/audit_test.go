@@ -0,0 +1,116 @@
+package db
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestAuditCaller(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "test-audit-caller-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpFile.Close()
+	defer os.Remove(tmpFile.Name())
+
+	db, err := Open(tmpFile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	caller := &AuditCaller{
+		Principal:  "api_key:ci",
+		AuthMethod: AuthMethodAPIKey,
+		ClientIP:   "192.0.2.1",
+		UserAgent:  "test-agent/1.0",
+		SessionID:  "session-1",
+	}
+	if err := db.LogAudit(AuditModeHTTP, caller, "tools/call", "ls", nil, nil, nil, time.Now()); err != nil {
+		t.Fatalf("LogAudit failed: %v", err)
+	}
+	if err := db.LogAudit(AuditModeHTTP, &AuditCaller{Principal: "oauth:other", AuthMethod: AuthMethodOAuth}, "tools/call", "cat", nil, nil, nil, time.Now()); err != nil {
+		t.Fatalf("LogAudit failed: %v", err)
+	}
+	if err := db.LogAudit(AuditModeStdio, nil, "tools/call", "ls", nil, nil, nil, time.Now()); err != nil {
+		t.Fatalf("LogAudit failed: %v", err)
+	}
+
+	entries, err := db.QueryAuditLogs(AuditFilter{Principal: "api_key:ci"}, 10, 0)
+	if err != nil {
+		t.Fatalf("QueryAuditLogs failed: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %d", len(entries))
+	}
+	if entries[0].AuditCaller != *caller {
+		t.Errorf("Expected caller %+v, got %+v", *caller, entries[0].AuditCaller)
+	}
+
+	entries, err = db.ListAuditLogs(AuditModeStdio, 10, 0)
+	if err != nil {
+		t.Fatalf("ListAuditLogs failed: %v", err)
+	}
+	if len(entries) != 1 || entries[0].AuditCaller != (AuditCaller{}) {
+		t.Fatalf("Expected empty caller, got %+v", entries)
+	}
+}
+
+func TestQueryAuditLogs(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "test-audit-query-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpFile.Close()
+	defer os.Remove(tmpFile.Name())
+
+	db, err := Open(tmpFile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	log := func(principal, sessionID, tool string) {
+		t.Helper()
+		caller := &AuditCaller{Principal: principal, SessionID: sessionID, ClientIP: "192.0.2.1"}
+		if err := db.LogAudit(AuditModeHTTP, caller, "tools/call", tool, nil, nil, nil, time.Now()); err != nil {
+			t.Fatalf("LogAudit failed: %v", err)
+		}
+	}
+	log("api_key:ci", "s1", "ls")
+	log("api_key:ci", "s2", "cat")
+	log("oauth:other", "s3", "ls")
+
+	// Backdate one entry to yesterday
+	if _, err := db.db.Exec(`UPDATE audit_logs SET created_at = datetime('now', '-1 day') WHERE session_id = 's1'`); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	tests := []struct {
+		name   string
+		filter AuditFilter
+		want   int
+	}{
+		{"all", AuditFilter{}, 3},
+		{"principal", AuditFilter{Principal: "api_key:ci"}, 2},
+		{"session", AuditFilter{SessionID: "s3"}, 1},
+		{"client ip", AuditFilter{ClientIP: "192.0.2.1"}, 3},
+		{"tool", AuditFilter{ToolName: "ls"}, 2},
+		{"since", AuditFilter{Since: now.Add(-time.Hour)}, 2},
+		{"principal yesterday", AuditFilter{Principal: "api_key:ci", Since: now.Add(-48 * time.Hour), Until: now.Add(-time.Hour)}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := db.QueryAuditLogs(tt.filter, 10, 0)
+			if err != nil {
+				t.Fatalf("QueryAuditLogs failed: %v", err)
+			}
+			if len(entries) != tt.want {
+				t.Errorf("Expected %d entries, got %d", tt.want, len(entries))
+			}
+		})
+	}
+}
@@ -15,7 +15,8 @@ var migrationsFS embed.FS
 
 // DB wraps a SQLite database connection
 type DB struct {
-	db *sql.DB
+	db       *sql.DB
+	redactor *auditRedactor // Applied to audit log entries before they are stored
 }
 
 // Open opens or creates a SQLite database at the given path
@@ -31,7 +32,7 @@ func Open(path string) (*DB, error) {
 		return nil, fmt.Errorf("failed to enable WAL mode: %w", err)
 	}
 
-	d := &DB{db: db}
+	d := &DB{db: db, redactor: newAuditRedactor()}
 
 	if err := d.migrate(); err != nil {
 		db.Close()
@@ -0,0 +1,5 @@
+-- Scopes a client may request (space-separated, empty = unrestricted)
+ALTER TABLE oauth_clients ADD COLUMN scopes TEXT NOT NULL DEFAULT '';
+
+-- Scopes granted to a token (space-separated, empty = unrestricted)
+ALTER TABLE oauth_tokens ADD COLUMN scopes TEXT NOT NULL DEFAULT '';
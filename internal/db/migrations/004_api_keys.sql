@@ -0,0 +1,19 @@
+-- Named API keys
+CREATE TABLE IF NOT EXISTS api_keys (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    key_hash TEXT NOT NULL UNIQUE,
+    tools TEXT NOT NULL DEFAULT '',   -- Tool name globs the key may call (space-separated, empty = unrestricted)
+    rate_limit INTEGER NOT NULL DEFAULT 0, -- Requests per rate limit window (0 = server limit only)
+    status TEXT NOT NULL DEFAULT 'active' CHECK(status IN ('active', 'revoked')),
+    expires_at DATETIME,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    revoked_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_key_hash ON api_keys(key_hash);
+
+-- Caller identity of each audit entry (e.g. 'api_key:ci', 'oauth:myclient')
+ALTER TABLE audit_logs ADD COLUMN principal TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_principal ON audit_logs(principal);
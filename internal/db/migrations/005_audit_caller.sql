@@ -0,0 +1,11 @@
+-- Caller details of each audit entry
+ALTER TABLE audit_logs ADD COLUMN auth_method TEXT;   -- 'api_key', 'oauth', or NULL if unauthenticated
+ALTER TABLE audit_logs ADD COLUMN client_ip TEXT;
+ALTER TABLE audit_logs ADD COLUMN user_agent TEXT;
+ALTER TABLE audit_logs ADD COLUMN session_id TEXT;    -- MCP session ID (Streamable HTTP)
+
+-- Per-caller history ("what did client X run yesterday")
+DROP INDEX IF EXISTS idx_audit_logs_principal;
+CREATE INDEX IF NOT EXISTS idx_audit_logs_principal_created_at ON audit_logs(principal, created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_session_id ON audit_logs(session_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_client_ip ON audit_logs(client_ip);
@@ -23,16 +23,23 @@ type OAuthClient struct {
 	ClientID         string
 	ClientSecretHash string
 	Status           string
+	Scopes           []string // Scopes the client may request (empty = unrestricted)
 	CreatedAt        time.Time
 	RevokedAt        *time.Time
 }
 
+// Principal returns the caller identity recorded in audit logs
+func (c *OAuthClient) Principal() string {
+	return "oauth:" + c.ClientID
+}
+
 // OAuthToken represents an OAuth token pair
 type OAuthToken struct {
 	ID               int64
 	OAuthClientID    int64
 	AccessTokenHash  string
 	RefreshTokenHash string
+	Scopes           []string // Scopes granted to the token (empty = unrestricted)
 	ExpiresAt        time.Time
 	CreatedAt        time.Time
 }
@@ -52,8 +59,13 @@ func hashToken(token string) string {
 	return hex.EncodeToString(hash[:])
 }
 
-// CreateOAuthClient creates a new OAuth client and returns the generated client secret
-func (d *DB) CreateOAuthClient(clientID string) (clientSecret string, err error) {
+// CreateOAuthClient creates a new OAuth client and returns the generated client secret.
+// Scopes restrict the tools the client may access; nil allows every tool.
+func (d *DB) CreateOAuthClient(clientID string, scopes []string) (clientSecret string, err error) {
+	if err := ValidateScopes(scopes); err != nil {
+		return "", err
+	}
+
 	// Generate a secure client secret
 	clientSecret, err = generateSecureToken(32)
 	if err != nil {
@@ -67,9 +79,9 @@ func (d *DB) CreateOAuthClient(clientID string) (clientSecret string, err error)
 	}
 
 	_, err = d.db.Exec(`
-		INSERT INTO oauth_clients (client_id, client_secret_hash, status)
-		VALUES (?, ?, 'active')
-	`, clientID, string(hashedSecret))
+		INSERT INTO oauth_clients (client_id, client_secret_hash, status, scopes)
+		VALUES (?, ?, 'active', ?)
+	`, clientID, string(hashedSecret), FormatScope(scopes))
 	if err != nil {
 		return "", fmt.Errorf("failed to insert OAuth client: %w", err)
 	}
@@ -80,20 +92,22 @@ func (d *DB) CreateOAuthClient(clientID string) (clientSecret string, err error)
 // GetOAuthClient retrieves an OAuth client by client_id
 func (d *DB) GetOAuthClient(clientID string) (*OAuthClient, error) {
 	row := d.db.QueryRow(`
-		SELECT id, client_id, client_secret_hash, status, created_at, revoked_at
+		SELECT id, client_id, client_secret_hash, status, scopes, created_at, revoked_at
 		FROM oauth_clients
 		WHERE client_id = ?
 	`, clientID)
 
 	client := &OAuthClient{}
+	var scopes string
 	var revokedAt sql.NullTime
-	if err := row.Scan(&client.ID, &client.ClientID, &client.ClientSecretHash, &client.Status, &client.CreatedAt, &revokedAt); err != nil {
+	if err := row.Scan(&client.ID, &client.ClientID, &client.ClientSecretHash, &client.Status, &scopes, &client.CreatedAt, &revokedAt); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
 		return nil, fmt.Errorf("failed to get OAuth client: %w", err)
 	}
 
+	client.Scopes = ParseScope(scopes)
 	if revokedAt.Valid {
 		client.RevokedAt = &revokedAt.Time
 	}
@@ -104,20 +118,22 @@ func (d *DB) GetOAuthClient(clientID string) (*OAuthClient, error) {
 // GetOAuthClientByID retrieves an OAuth client by internal ID
 func (d *DB) GetOAuthClientByID(id int64) (*OAuthClient, error) {
 	row := d.db.QueryRow(`
-		SELECT id, client_id, client_secret_hash, status, created_at, revoked_at
+		SELECT id, client_id, client_secret_hash, status, scopes, created_at, revoked_at
 		FROM oauth_clients
 		WHERE id = ?
 	`, id)
 
 	client := &OAuthClient{}
+	var scopes string
 	var revokedAt sql.NullTime
-	if err := row.Scan(&client.ID, &client.ClientID, &client.ClientSecretHash, &client.Status, &client.CreatedAt, &revokedAt); err != nil {
+	if err := row.Scan(&client.ID, &client.ClientID, &client.ClientSecretHash, &client.Status, &scopes, &client.CreatedAt, &revokedAt); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
 		return nil, fmt.Errorf("failed to get OAuth client: %w", err)
 	}
 
+	client.Scopes = ParseScope(scopes)
 	if revokedAt.Valid {
 		client.RevokedAt = &revokedAt.Time
 	}
@@ -128,7 +144,7 @@ func (d *DB) GetOAuthClientByID(id int64) (*OAuthClient, error) {
 // ListOAuthClients retrieves all OAuth clients
 func (d *DB) ListOAuthClients() ([]*OAuthClient, error) {
 	rows, err := d.db.Query(`
-		SELECT id, client_id, client_secret_hash, status, created_at, revoked_at
+		SELECT id, client_id, client_secret_hash, status, scopes, created_at, revoked_at
 		FROM oauth_clients
 		ORDER BY created_at DESC
 	`)
@@ -140,10 +156,12 @@ func (d *DB) ListOAuthClients() ([]*OAuthClient, error) {
 	var clients []*OAuthClient
 	for rows.Next() {
 		client := &OAuthClient{}
+		var scopes string
 		var revokedAt sql.NullTime
-		if err := rows.Scan(&client.ID, &client.ClientID, &client.ClientSecretHash, &client.Status, &client.CreatedAt, &revokedAt); err != nil {
+		if err := rows.Scan(&client.ID, &client.ClientID, &client.ClientSecretHash, &client.Status, &scopes, &client.CreatedAt, &revokedAt); err != nil {
 			return nil, fmt.Errorf("failed to scan OAuth client: %w", err)
 		}
+		client.Scopes = ParseScope(scopes)
 		if revokedAt.Valid {
 			client.RevokedAt = &revokedAt.Time
 		}
@@ -220,8 +238,8 @@ func (d *DB) ValidateClientCredentials(clientID, clientSecret string) (*OAuthCli
 	return client, nil
 }
 
-// CreateToken creates a new OAuth token pair
-func (d *DB) CreateToken(clientID int64) (accessToken, refreshToken string, err error) {
+// CreateToken creates a new OAuth token pair granting the given scopes
+func (d *DB) CreateToken(clientID int64, scopes []string) (accessToken, refreshToken string, err error) {
 	// Generate tokens
 	accessToken, err = generateSecureToken(32)
 	if err != nil {
@@ -240,9 +258,9 @@ func (d *DB) CreateToken(clientID int64) (accessToken, refreshToken string, err
 	expiresAt := time.Now().Add(AccessTokenExpiration)
 
 	_, err = d.db.Exec(`
-		INSERT INTO oauth_tokens (oauth_client_id, access_token_hash, refresh_token_hash, expires_at)
-		VALUES (?, ?, ?, ?)
-	`, clientID, accessTokenHash, refreshTokenHash, expiresAt)
+		INSERT INTO oauth_tokens (oauth_client_id, access_token_hash, refresh_token_hash, scopes, expires_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, clientID, accessTokenHash, refreshTokenHash, FormatScope(scopes), expiresAt)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to insert token: %w", err)
 	}
@@ -251,54 +269,65 @@ func (d *DB) CreateToken(clientID int64) (accessToken, refreshToken string, err
 }
 
 // ValidateAccessToken validates an access token and returns the associated client
-func (d *DB) ValidateAccessToken(token string) (*OAuthClient, error) {
+// and the scopes granted to the token
+func (d *DB) ValidateAccessToken(token string) (*OAuthClient, []string, error) {
 	tokenHash := hashToken(token)
 
 	row := d.db.QueryRow(`
-		SELECT t.oauth_client_id, t.expires_at
+		SELECT t.oauth_client_id, t.scopes, t.expires_at
 		FROM oauth_tokens t
 		WHERE t.access_token_hash = ?
 	`, tokenHash)
 
 	var clientID int64
+	var scopes string
 	var expiresAt time.Time
-	if err := row.Scan(&clientID, &expiresAt); err != nil {
+	if err := row.Scan(&clientID, &scopes, &expiresAt); err != nil {
 		if err == sql.ErrNoRows {
-			return nil, nil
+			return nil, nil, nil
 		}
-		return nil, fmt.Errorf("failed to validate access token: %w", err)
+		return nil, nil, fmt.Errorf("failed to validate access token: %w", err)
 	}
 
 	// Check if token is expired
 	if time.Now().After(expiresAt) {
-		return nil, nil
+		return nil, nil, nil
 	}
 
 	// Get the client and check if it's still active
 	client, err := d.GetOAuthClientByID(clientID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if client == nil || client.Status != "active" {
-		return nil, nil
+		return nil, nil, nil
 	}
 
-	return client, nil
+	// Scopes removed from the client since the token was issued no longer apply
+	granted, ok := IntersectScopes(ParseScope(scopes), client.Scopes)
+	if !ok {
+		return nil, nil, nil
+	}
+
+	return client, granted, nil
 }
 
-// RefreshToken exchanges a refresh token for new access and refresh tokens
-func (d *DB) RefreshToken(refreshToken string, clientID int64) (newAccessToken, newRefreshToken string, err error) {
+// RefreshToken exchanges a refresh token for new access and refresh tokens.
+// The new tokens keep the original scopes unless narrower scopes are requested;
+// requesting scopes outside the original grant returns ErrInvalidScope.
+func (d *DB) RefreshToken(refreshToken string, clientID int64, scopes []string) (newAccessToken, newRefreshToken string, err error) {
 	refreshTokenHash := hashToken(refreshToken)
 
 	// Get the token record
 	row := d.db.QueryRow(`
-		SELECT id, oauth_client_id
+		SELECT id, oauth_client_id, scopes
 		FROM oauth_tokens
 		WHERE refresh_token_hash = ? AND oauth_client_id = ?
 	`, refreshTokenHash, clientID)
 
 	var tokenID, tokenClientID int64
-	if err := row.Scan(&tokenID, &tokenClientID); err != nil {
+	var granted string
+	if err := row.Scan(&tokenID, &tokenClientID, &granted); err != nil {
 		if err == sql.ErrNoRows {
 			return "", "", fmt.Errorf("invalid refresh token")
 		}
@@ -314,6 +343,17 @@ func (d *DB) RefreshToken(refreshToken string, clientID int64) (newAccessToken,
 		return "", "", fmt.Errorf("client is inactive or revoked")
 	}
 
+	// Scopes removed from the client since the token was issued are not renewed
+	grantedScopes, ok := IntersectScopes(ParseScope(granted), client.Scopes)
+	if !ok {
+		return "", "", ErrInvalidScope
+	}
+	if len(scopes) == 0 {
+		scopes = grantedScopes
+	} else if !ScopesCover(grantedScopes, scopes) {
+		return "", "", ErrInvalidScope
+	}
+
 	// Delete the old token
 	_, err = d.db.Exec(`DELETE FROM oauth_tokens WHERE id = ?`, tokenID)
 	if err != nil {
@@ -321,7 +361,7 @@ func (d *DB) RefreshToken(refreshToken string, clientID int64) (newAccessToken,
 	}
 
 	// Create new token pair
-	return d.CreateToken(tokenClientID)
+	return d.CreateToken(tokenClientID, scopes)
 }
 
 // CleanupExpiredTokens removes expired authorization codes and tokens
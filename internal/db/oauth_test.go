@@ -1,6 +1,7 @@
 package db
 
 import (
+	"errors"
 	"os"
 	"testing"
 	"time"
@@ -22,7 +23,7 @@ func TestOAuthClientCRUD(t *testing.T) {
 	defer db.Close()
 
 	// Test CreateOAuthClient
-	clientSecret, err := db.CreateOAuthClient("test-client")
+	clientSecret, err := db.CreateOAuthClient("test-client", nil)
 	if err != nil {
 		t.Fatalf("CreateOAuthClient failed: %v", err)
 	}
@@ -126,7 +127,7 @@ func TestOAuthTokenFlow(t *testing.T) {
 	defer db.Close()
 
 	// Create client
-	_, err = db.CreateOAuthClient("token-test-client")
+	_, err = db.CreateOAuthClient("token-test-client", nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -137,7 +138,7 @@ func TestOAuthTokenFlow(t *testing.T) {
 	}
 
 	// Test CreateToken
-	accessToken, refreshToken, err := db.CreateToken(client.ID)
+	accessToken, refreshToken, err := db.CreateToken(client.ID, nil)
 	if err != nil {
 		t.Fatalf("CreateToken failed: %v", err)
 	}
@@ -149,7 +150,7 @@ func TestOAuthTokenFlow(t *testing.T) {
 	}
 
 	// Test ValidateAccessToken
-	validated, err := db.ValidateAccessToken(accessToken)
+	validated, _, err := db.ValidateAccessToken(accessToken)
 	if err != nil {
 		t.Fatalf("ValidateAccessToken failed: %v", err)
 	}
@@ -161,7 +162,7 @@ func TestOAuthTokenFlow(t *testing.T) {
 	}
 
 	// Test ValidateAccessToken with invalid token
-	validated, err = db.ValidateAccessToken("invalid-token")
+	validated, _, err = db.ValidateAccessToken("invalid-token")
 	if err != nil {
 		t.Fatalf("ValidateAccessToken failed: %v", err)
 	}
@@ -170,7 +171,7 @@ func TestOAuthTokenFlow(t *testing.T) {
 	}
 
 	// Test RefreshToken
-	newAccessToken, newRefreshToken, err := db.RefreshToken(refreshToken, client.ID)
+	newAccessToken, newRefreshToken, err := db.RefreshToken(refreshToken, client.ID, nil)
 	if err != nil {
 		t.Fatalf("RefreshToken failed: %v", err)
 	}
@@ -182,7 +183,7 @@ func TestOAuthTokenFlow(t *testing.T) {
 	}
 
 	// Old access token should be invalid
-	validated, err = db.ValidateAccessToken(accessToken)
+	validated, _, err = db.ValidateAccessToken(accessToken)
 	if err != nil {
 		t.Fatalf("ValidateAccessToken failed: %v", err)
 	}
@@ -191,7 +192,7 @@ func TestOAuthTokenFlow(t *testing.T) {
 	}
 
 	// New access token should be valid
-	validated, err = db.ValidateAccessToken(newAccessToken)
+	validated, _, err = db.ValidateAccessToken(newAccessToken)
 	if err != nil {
 		t.Fatalf("ValidateAccessToken failed: %v", err)
 	}
@@ -200,6 +201,130 @@ func TestOAuthTokenFlow(t *testing.T) {
 	}
 }
 
+func TestOAuthScopes(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "test-oauth-scopes-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpFile.Close()
+	defer os.Remove(tmpFile.Name())
+
+	db, err := Open(tmpFile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// Invalid scopes are rejected
+	if _, err := db.CreateOAuthClient("bad-scope-client", []string{"admin"}); err == nil {
+		t.Fatal("Expected error for invalid scope")
+	}
+
+	if _, err := db.CreateOAuthClient("scoped-client", []string{"tools:git-*", "tools:ls"}); err != nil {
+		t.Fatalf("CreateOAuthClient failed: %v", err)
+	}
+	client, err := db.GetOAuthClient("scoped-client")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(client.Scopes) != 2 || client.Scopes[0] != "tools:git-*" || client.Scopes[1] != "tools:ls" {
+		t.Fatalf("Expected client scopes to round-trip, got %v", client.Scopes)
+	}
+
+	_, refreshToken, err := db.CreateToken(client.ID, []string{"tools:git-*"})
+	if err != nil {
+		t.Fatalf("CreateToken failed: %v", err)
+	}
+
+	// Refreshing with a wider scope fails
+	if _, _, err := db.RefreshToken(refreshToken, client.ID, []string{"tools:ls"}); !errors.Is(err, ErrInvalidScope) {
+		t.Fatalf("Expected ErrInvalidScope, got %v", err)
+	}
+
+	// Refreshing with a narrower scope succeeds
+	accessToken, _, err := db.RefreshToken(refreshToken, client.ID, []string{"tools:git-status"})
+	if err != nil {
+		t.Fatalf("RefreshToken failed: %v", err)
+	}
+	validated, scopes, err := db.ValidateAccessToken(accessToken)
+	if err != nil {
+		t.Fatalf("ValidateAccessToken failed: %v", err)
+	}
+	if validated == nil {
+		t.Fatal("Expected access token to be valid")
+	}
+	if len(scopes) != 1 || scopes[0] != "tools:git-status" {
+		t.Errorf("Expected narrowed scopes [tools:git-status], got %v", scopes)
+	}
+}
+
+func TestOAuthScopesFollowClient(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "test-oauth-client-scopes-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpFile.Close()
+	defer os.Remove(tmpFile.Name())
+
+	db, err := Open(tmpFile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if _, err := db.CreateOAuthClient("narrowed-client", []string{"tools:git-*"}); err != nil {
+		t.Fatalf("CreateOAuthClient failed: %v", err)
+	}
+	client, err := db.GetOAuthClient("narrowed-client")
+	if err != nil {
+		t.Fatal(err)
+	}
+	accessToken, refreshToken, err := db.CreateToken(client.ID, []string{"tools:git-*"})
+	if err != nil {
+		t.Fatalf("CreateToken failed: %v", err)
+	}
+
+	// Narrow the client after the token was issued
+	if _, err := db.db.Exec(`UPDATE oauth_clients SET scopes = ? WHERE id = ?`, "tools:git-log", client.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	_, scopes, err := db.ValidateAccessToken(accessToken)
+	if err != nil {
+		t.Fatalf("ValidateAccessToken failed: %v", err)
+	}
+	if len(scopes) != 1 || scopes[0] != "tools:git-log" {
+		t.Errorf("Expected scopes limited to the client [tools:git-log], got %v", scopes)
+	}
+
+	if _, _, err := db.RefreshToken(refreshToken, client.ID, []string{"tools:git-status"}); !errors.Is(err, ErrInvalidScope) {
+		t.Fatalf("Expected ErrInvalidScope for a scope the client lost, got %v", err)
+	}
+	accessToken, _, err = db.RefreshToken(refreshToken, client.ID, nil)
+	if err != nil {
+		t.Fatalf("RefreshToken failed: %v", err)
+	}
+	_, scopes, err = db.ValidateAccessToken(accessToken)
+	if err != nil {
+		t.Fatalf("ValidateAccessToken failed: %v", err)
+	}
+	if len(scopes) != 1 || scopes[0] != "tools:git-log" {
+		t.Errorf("Expected refreshed scopes [tools:git-log], got %v", scopes)
+	}
+
+	// A token with no scope left in common with the client is rejected
+	if _, err := db.db.Exec(`UPDATE oauth_clients SET scopes = ? WHERE id = ?`, "tools:ls", client.ID); err != nil {
+		t.Fatal(err)
+	}
+	validated, _, err := db.ValidateAccessToken(accessToken)
+	if err != nil {
+		t.Fatalf("ValidateAccessToken failed: %v", err)
+	}
+	if validated != nil {
+		t.Error("Expected access token without common scopes to be rejected")
+	}
+}
+
 func TestOAuthCleanup(t *testing.T) {
 	// Create temp database
 	tmpFile, err := os.CreateTemp("", "test-oauth-cleanup-*.db")
@@ -216,7 +341,7 @@ func TestOAuthCleanup(t *testing.T) {
 	defer db.Close()
 
 	// Create client
-	_, err = db.CreateOAuthClient("cleanup-test-client")
+	_, err = db.CreateOAuthClient("cleanup-test-client", nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -227,7 +352,7 @@ func TestOAuthCleanup(t *testing.T) {
 	}
 
 	// Create token
-	_, _, err = db.CreateToken(client.ID)
+	_, _, err = db.CreateToken(client.ID, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
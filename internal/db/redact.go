@@ -0,0 +1,213 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// AuditRedaction replaces secrets in audit logs
+const AuditRedaction = "[REDACTED]"
+
+// DefaultAuditRedactPatterns match well-known credential formats. In a pattern with a
+// capture group only the first group is redacted, so the surrounding text stays readable.
+var DefaultAuditRedactPatterns = []string{
+	`\b(?:ghp|gho|ghu|ghs|ghr)_[A-Za-z0-9]{36,}`,                                 // GitHub tokens
+	`\bgithub_pat_[A-Za-z0-9_]{22,}`,                                             // GitHub fine-grained tokens
+	`\bglpat-[A-Za-z0-9_-]{20,}`,                                                 // GitLab tokens
+	`\bxox[abposr]-[A-Za-z0-9-]{10,}`,                                            // Slack tokens
+	`\b(?:AKIA|ASIA)[A-Z0-9]{16}\b`,                                              // AWS access key IDs
+	`\bsk-[A-Za-z0-9_-]{20,}`,                                                    // OpenAI and Anthropic API keys
+	`\bAIza[0-9A-Za-z_-]{35}`,                                                    // Google API keys
+	`\bnpm_[A-Za-z0-9]{36}`,                                                      // npm tokens
+	`\beyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}`,            // JWTs
+	`(?i)\bbearer\s+([A-Za-z0-9._~+/-]{16,}=*)`,                                  // Bearer credentials
+	`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`, // PEM private keys
+	`\b[A-Za-z][A-Za-z0-9+.-]*://[^/\s:@]*:([^/\s@]+)@`,                          // Passwords in URLs (e.g. git remote -v)
+}
+
+// DefaultAuditEnvKeys are the glob patterns of environment variable names whose values
+// are redacted from audit logs by default
+var DefaultAuditEnvKeys = []string{"*TOKEN*", "*SECRET*", "*KEY*", "*PASSWORD*"}
+
+// DefaultAuditEnvMinLength is the shortest environment variable value redacted from audit
+// logs by default; shorter values such as "1" or "en" would replace unrelated text
+const DefaultAuditEnvMinLength = 8
+
+// LoadAuditRedactPatterns reads an audit redact file of regular expressions, one per line.
+// Blank lines and lines starting with # are skipped.
+func LoadAuditRedactPatterns(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit redact file: %w", err)
+	}
+	var patterns []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns, nil
+}
+
+// auditRedactor removes secrets from audit log entries before they are stored
+type auditRedactor struct {
+	mu       sync.RWMutex
+	patterns []*regexp.Regexp
+	values   []string // Literal secrets, longest first
+
+	envKeys      []string // Environment variables whose values are redacted (empty = none)
+	envMinLength int      // Shortest environment variable value redacted
+}
+
+// newAuditRedactor returns a redactor for the default patterns and environment variables
+func newAuditRedactor() *auditRedactor {
+	r := &auditRedactor{envKeys: DefaultAuditEnvKeys, envMinLength: DefaultAuditEnvMinLength}
+	for _, p := range DefaultAuditRedactPatterns {
+		r.patterns = append(r.patterns, regexp.MustCompile(p))
+	}
+	return r
+}
+
+// SetAuditRedactPatterns sets the regular expressions redacted from audit logs in
+// addition to DefaultAuditRedactPatterns
+func (d *DB) SetAuditRedactPatterns(patterns []string) error {
+	compiled := make([]*regexp.Regexp, 0, len(DefaultAuditRedactPatterns)+len(patterns))
+	for _, p := range append(append([]string{}, DefaultAuditRedactPatterns...), patterns...) {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return fmt.Errorf("invalid audit redact pattern %q: %w", p, err)
+		}
+		compiled = append(compiled, re)
+	}
+	d.redactor.mu.Lock()
+	d.redactor.patterns = compiled
+	d.redactor.mu.Unlock()
+	return nil
+}
+
+// SetAuditEnvRedaction sets the glob patterns (empty = none) and minimum length selecting
+// the environment variable values redacted from audit logs
+func (d *DB) SetAuditEnvRedaction(keys []string, minLength int) {
+	d.redactor.mu.Lock()
+	d.redactor.envKeys, d.redactor.envMinLength = keys, minLength
+	d.redactor.mu.Unlock()
+}
+
+// AuditEnvRedaction returns the glob patterns (empty = none) and minimum length selecting
+// the environment variable values to pass to SetAuditRedactValues
+func (d *DB) AuditEnvRedaction() ([]string, int) {
+	d.redactor.mu.RLock()
+	defer d.redactor.mu.RUnlock()
+	return d.redactor.envKeys, d.redactor.envMinLength
+}
+
+// SetAuditRedactValues sets the literal secrets, such as environment variable values,
+// redacted from audit logs; empty values are ignored
+func (d *DB) SetAuditRedactValues(values []string) {
+	sorted := make([]string, 0, len(values))
+	for _, v := range values {
+		if v != "" {
+			sorted = append(sorted, v)
+		}
+	}
+	// Longer values first, so a value containing another is replaced whole
+	sort.Slice(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+	d.redactor.mu.Lock()
+	d.redactor.values = sorted
+	d.redactor.mu.Unlock()
+}
+
+// redact replaces the secret values and pattern matches in s
+func (r *auditRedactor) redact(s string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, v := range r.values {
+		s = strings.ReplaceAll(s, v, AuditRedaction)
+	}
+	for _, re := range r.patterns {
+		s = redactPattern(re, s)
+	}
+	return s
+}
+
+// redactPattern replaces the matches of re in s, or only their first group if re has one
+func redactPattern(re *regexp.Regexp, s string) string {
+	if re.NumSubexp() == 0 {
+		return re.ReplaceAllLiteralString(s, AuditRedaction)
+	}
+	matches := re.FindAllStringSubmatchIndex(s, -1)
+	if matches == nil {
+		return s
+	}
+	var sb strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := m[0], m[1]
+		if m[2] >= 0 {
+			start, end = m[2], m[3]
+		}
+		sb.WriteString(s[last:start])
+		sb.WriteString(AuditRedaction)
+		last = end
+	}
+	sb.WriteString(s[last:])
+	return sb.String()
+}
+
+// redactJSON redacts every string in a JSON document, so that secrets are found in their
+// unescaped form and the result stays valid JSON. A document without secrets is returned as is.
+func (r *auditRedactor) redactJSON(data string) string {
+	dec := json.NewDecoder(strings.NewReader(data))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return r.redact(data)
+	}
+	doc, changed := r.redactValue(doc)
+	if !changed {
+		return data
+	}
+	redacted, err := json.Marshal(doc)
+	if err != nil {
+		return r.redact(data)
+	}
+	return string(redacted)
+}
+
+// redactValue redacts the strings, including object keys, in a decoded JSON value
+func (r *auditRedactor) redactValue(v interface{}) (interface{}, bool) {
+	switch val := v.(type) {
+	case string:
+		redacted := r.redact(val)
+		return redacted, redacted != val
+	case []interface{}:
+		changed := false
+		for i := range val {
+			var c bool
+			if val[i], c = r.redactValue(val[i]); c {
+				changed = true
+			}
+		}
+		return val, changed
+	case map[string]interface{}:
+		changed := false
+		redacted := make(map[string]interface{}, len(val))
+		for k, elem := range val {
+			elem, c := r.redactValue(elem)
+			key := r.redact(k)
+			if c || key != k {
+				changed = true
+			}
+			redacted[key] = elem
+		}
+		return redacted, changed
+	}
+	return v, false
+}
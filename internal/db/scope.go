@@ -0,0 +1,120 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gobwas/glob"
+)
+
+// ToolScopePrefix is the prefix of scopes that grant access to tools ("tools:<glob>")
+const ToolScopePrefix = "tools:"
+
+// ErrInvalidScope is returned when a requested scope is not covered by the granted scopes
+var ErrInvalidScope = errors.New("requested scope exceeds granted scope")
+
+// ParseScope splits a space-separated OAuth scope string
+func ParseScope(scope string) []string {
+	return strings.Fields(scope)
+}
+
+// FormatScope joins scopes into a space-separated OAuth scope string
+func FormatScope(scopes []string) string {
+	return strings.Join(scopes, " ")
+}
+
+// ValidateScopes checks that every scope is a "tools:<glob>" scope with a valid pattern
+func ValidateScopes(scopes []string) error {
+	for _, scope := range scopes {
+		pattern, ok := strings.CutPrefix(scope, ToolScopePrefix)
+		if !ok || pattern == "" {
+			return fmt.Errorf("invalid scope %q (expected %s<tool glob>)", scope, ToolScopePrefix)
+		}
+		if _, err := glob.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid scope %q: %w", scope, err)
+		}
+	}
+	return nil
+}
+
+// ScopesAllowTool reports whether scopes grant access to a tool.
+// Empty scopes are unrestricted.
+func ScopesAllowTool(scopes []string, toolName string) bool {
+	if len(scopes) == 0 {
+		return true
+	}
+	return scopesMatch(scopes, ToolScopePrefix+toolName)
+}
+
+// ScopesCover reports whether every requested scope is within the granted scopes.
+// A requested glob must be granted verbatim or start with the literal prefix of a granted
+// "prefix*" glob; a literal scope may be matched by a granted glob.
+// Empty granted scopes are unrestricted.
+func ScopesCover(granted, requested []string) bool {
+	if len(granted) == 0 {
+		return true
+	}
+	for _, scope := range requested {
+		if !scopeCovered(granted, scope) {
+			return false
+		}
+	}
+	return true
+}
+
+// IntersectScopes returns the scopes within both a and b, and false if they have none in
+// common. Empty scopes are unrestricted. Overlapping globs where neither covers the other
+// are dropped, so the result may be narrower than the exact intersection.
+func IntersectScopes(a, b []string) ([]string, bool) {
+	if len(a) == 0 {
+		return b, true
+	}
+	if len(b) == 0 {
+		return a, true
+	}
+	var scopes []string
+	for _, scope := range append(append([]string{}, a...), b...) {
+		if scopeCovered(a, scope) && scopeCovered(b, scope) && !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes, len(scopes) > 0
+}
+
+// scopeCovered reports whether every value a scope matches is matched by a granted scope
+func scopeCovered(granted []string, scope string) bool {
+	if !isScopeGlob(scope) {
+		return scopesMatch(granted, scope)
+	}
+	for _, g := range granted {
+		if g == scope {
+			return true
+		}
+		// "tools:git-*" covers every glob starting with "tools:git-"
+		if prefix, ok := strings.CutSuffix(g, "*"); ok && !isScopeGlob(prefix) && strings.HasPrefix(scope, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// isScopeGlob reports whether a scope contains glob syntax
+func isScopeGlob(scope string) bool {
+	return strings.ContainsAny(scope, `*?[]{}\`)
+}
+
+// scopesMatch reports whether any scope pattern matches the value
+func scopesMatch(scopes []string, value string) bool {
+	for _, scope := range scopes {
+		g, err := glob.Compile(scope)
+		if err != nil {
+			continue
+		}
+		if g.Match(value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateScopes(t *testing.T) {
+	tests := []struct {
+		name    string
+		scopes  []string
+		wantErr bool
+	}{
+		{"empty", nil, false},
+		{"literal", []string{"tools:git-status"}, false},
+		{"glob", []string{"tools:git-*"}, false},
+		{"missing prefix", []string{"git-status"}, true},
+		{"empty pattern", []string{"tools:"}, true},
+		{"bad glob", []string{"tools:[git"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateScopes(tt.scopes)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateScopes(%v) error = %v, wantErr %v", tt.scopes, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestScopesAllowTool(t *testing.T) {
+	tests := []struct {
+		name   string
+		scopes []string
+		tool   string
+		want   bool
+	}{
+		{"unrestricted", nil, "anything", true},
+		{"literal match", []string{"tools:git-status"}, "git-status", true},
+		{"literal mismatch", []string{"tools:git-status"}, "git-log", false},
+		{"glob match", []string{"tools:git-*"}, "git-log", true},
+		{"glob mismatch", []string{"tools:git-*"}, "ls", false},
+		{"any scope", []string{"tools:ls", "tools:git-*"}, "ls", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ScopesAllowTool(tt.scopes, tt.tool); got != tt.want {
+				t.Errorf("ScopesAllowTool(%v, %q) = %v, want %v", tt.scopes, tt.tool, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScopesCover(t *testing.T) {
+	tests := []struct {
+		name      string
+		granted   []string
+		requested []string
+		want      bool
+	}{
+		{"unrestricted", nil, []string{"tools:*"}, true},
+		{"same literal", []string{"tools:ls"}, []string{"tools:ls"}, true},
+		{"literal within glob", []string{"tools:git-*"}, []string{"tools:git-log"}, true},
+		{"literal outside glob", []string{"tools:git-*"}, []string{"tools:ls"}, false},
+		{"same glob", []string{"tools:git-*"}, []string{"tools:git-*"}, true},
+		{"wider glob", []string{"tools:git-*"}, []string{"tools:*"}, false},
+		{"partial", []string{"tools:ls"}, []string{"tools:ls", "tools:cat"}, false},
+		{"glob within prefix glob", []string{"tools:*"}, []string{"tools:git-*"}, true},
+		{"glob outside prefix glob", []string{"tools:git-*"}, []string{"tools:gi*"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ScopesCover(tt.granted, tt.requested); got != tt.want {
+				t.Errorf("ScopesCover(%v, %v) = %v, want %v", tt.granted, tt.requested, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIntersectScopes(t *testing.T) {
+	tests := []struct {
+		name   string
+		a, b   []string
+		want   []string
+		wantOK bool
+	}{
+		{"unrestricted token", nil, []string{"tools:ls"}, []string{"tools:ls"}, true},
+		{"unrestricted client", []string{"tools:ls"}, nil, []string{"tools:ls"}, true},
+		{"narrower client", []string{"tools:git-*"}, []string{"tools:git-log", "tools:ls"}, []string{"tools:git-log"}, true},
+		{"narrower token", []string{"tools:git-*"}, []string{"tools:*"}, []string{"tools:git-*"}, true},
+		{"overlapping globs", []string{"tools:git-*"}, []string{"tools:*-log"}, nil, false},
+		{"disjoint", []string{"tools:ls"}, []string{"tools:cat"}, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := IntersectScopes(tt.a, tt.b)
+			if !reflect.DeepEqual(got, tt.want) || ok != tt.wantOK {
+				t.Errorf("IntersectScopes(%v, %v) = %v, %v, want %v, %v", tt.a, tt.b, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package executor
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/takeshy/mcp-gatekeeper/internal/plugin"
+)
+
+// SetBindPaths sets the host paths outside the root directory that tool bwrap binds may use; call it before executing
+func (e *Executor) SetBindPaths(paths []string) {
+	e.config.BindPaths = paths
+	if e.sandbox != nil {
+		e.sandbox.bindPaths = paths
+	}
+}
+
+// ValidateBwrapBinds checks that a tool's bind sources exist and stay under the
+// root directory or one of the allowed bind paths
+func ValidateBwrapBinds(cfg *plugin.BwrapConfig, rootDir string, bindPaths []string) error {
+	if cfg == nil {
+		return nil
+	}
+	for _, b := range append(append([]plugin.BindMount(nil), cfg.ROBinds...), cfg.RWBinds...) {
+		if _, err := resolveBindSource(b.Src, rootDir, bindPaths); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveBindSource returns the symlink-resolved host path of a bind source.
+// Relative sources are relative to the root directory.
+func resolveBindSource(src, rootDir string, bindPaths []string) (string, error) {
+	if !filepath.IsAbs(src) {
+		src = filepath.Join(rootDir, src)
+	}
+	real, err := filepath.EvalSymlinks(src)
+	if err != nil {
+		return "", fmt.Errorf("bind source %q: %w", src, err)
+	}
+
+	for _, allowed := range append([]string{rootDir}, bindPaths...) {
+		allowedReal, err := filepath.EvalSymlinks(allowed)
+		if err != nil {
+			continue
+		}
+		if IsPathWithinRoot(allowedReal, real) {
+			return real, nil
+		}
+	}
+	return "", fmt.Errorf("bind source %q is outside the root directory and the allowed bind paths", src)
+}
+
+// sandboxMount is a host path bound into the sandbox
+type sandboxMount struct {
+	Src      string `json:"src"`
+	Dest     string `json:"dest"`
+	ReadOnly bool   `json:"read_only,omitempty"`
+}
+
+// toolMounts resolves a tool's read-only and read-write binds
+func (s *Sandbox) toolMounts(cfg *plugin.BwrapConfig) ([]sandboxMount, error) {
+	var mounts []sandboxMount
+	binds := []struct {
+		readOnly bool
+		mounts   []plugin.BindMount
+	}{
+		{true, cfg.ROBinds},
+		{false, cfg.RWBinds},
+	}
+	for _, group := range binds {
+		for _, b := range group.mounts {
+			src, err := resolveBindSource(b.Src, s.rootDir, s.bindPaths)
+			if err != nil {
+				return nil, err
+			}
+			dest := b.Dest
+			if dest == "" {
+				dest = s.toSandboxPath(src)
+			}
+			if dest == "/" {
+				return nil, fmt.Errorf("bind source %q cannot be mounted over the sandbox root", b.Src)
+			}
+			mounts = append(mounts, sandboxMount{Src: src, Dest: dest, ReadOnly: group.readOnly})
+		}
+	}
+	return mounts, nil
+}
+
+// bwrapMountArgs returns the bwrap arguments for a tool's extra binds and tmpfs mounts
+func (s *Sandbox) bwrapMountArgs(cfg *plugin.BwrapConfig) ([]string, error) {
+	mounts, err := s.toolMounts(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var args []string
+	for _, m := range mounts {
+		flag := "--bind"
+		if m.ReadOnly {
+			flag = "--ro-bind"
+		}
+		args = append(args, flag, m.Src, m.Dest)
+	}
+	for _, p := range cfg.Tmpfs {
+		args = append(args, "--tmpfs", p)
+	}
+	return args, nil
+}
@@ -0,0 +1,151 @@
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/takeshy/mcp-gatekeeper/internal/plugin"
+)
+
+// containsSeq reports whether seq appears contiguously in args
+func containsSeq(args []string, seq ...string) bool {
+	for i := 0; i+len(seq) <= len(args); i++ {
+		match := true
+		for j, s := range seq {
+			if args[i+j] != s {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSandbox_WrapWithBwrapProfile(t *testing.T) {
+	rootDir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(rootDir, "scratch"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	dataDir := t.TempDir()
+	// A fake bwrap path is enough since only the arguments are checked
+	s := &Sandbox{mode: SandboxBwrap, rootDir: rootDir, bwrap: "/usr/bin/bwrap", bindPaths: []string{dataDir}}
+
+	t.Run("default profile", func(t *testing.T) {
+		_, args, err := s.WrapCommand(rootDir, "ls", nil, nil, nil)
+		if err != nil {
+			t.Fatalf("WrapCommand() error = %v", err)
+		}
+		if !containsSeq(args, "--bind", rootDir, "/") {
+			t.Errorf("expected read-write root bind, got %v", args)
+		}
+		if !containsSeq(args, "--unshare-net") {
+			t.Errorf("expected --unshare-net, got %v", args)
+		}
+		if args[len(args)-1] != "ls" {
+			t.Errorf("expected command last, got %v", args)
+		}
+	})
+
+	t.Run("overlay workspace", func(t *testing.T) {
+		ws, err := newWorkspace(rootDir, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer ws.Close()
+		_, args, err := s.WrapCommand(rootDir, "ls", nil, nil, &CallMounts{Workspace: ws})
+		if err != nil {
+			t.Fatalf("WrapCommand() error = %v", err)
+		}
+		if !containsSeq(args, "--overlay-src", rootDir, "--overlay", ws.upperDir(), ws.workDir(), "/") {
+			t.Errorf("expected an overlay root, got %v", args)
+		}
+		if containsSeq(args, "--bind", rootDir, "/") {
+			t.Errorf("expected no root bind, got %v", args)
+		}
+	})
+
+	t.Run("custom profile", func(t *testing.T) {
+		cfg := &plugin.BwrapConfig{
+			ROBinds:      []plugin.BindMount{{Src: dataDir, Dest: "/data"}},
+			RWBinds:      []plugin.BindMount{{Src: "scratch"}},
+			Tmpfs:        []string{"/etc"},
+			ShareNet:     true,
+			ReadonlyRoot: true,
+			Hostname:     "sandbox",
+		}
+		_, args, err := s.WrapCommand(rootDir, "ls", []string{"-l"}, cfg, nil)
+		if err != nil {
+			t.Fatalf("WrapCommand() error = %v", err)
+		}
+		realData, _ := filepath.EvalSymlinks(dataDir)
+		realScratch, _ := filepath.EvalSymlinks(filepath.Join(rootDir, "scratch"))
+		for _, seq := range [][]string{
+			{"--ro-bind", rootDir, "/"},
+			{"--ro-bind", realData, "/data"},
+			{"--bind", realScratch, "/scratch"},
+			{"--hostname", "sandbox"},
+			{"ls", "-l"},
+		} {
+			if !containsSeq(args, seq...) {
+				t.Errorf("expected %v in %v", seq, args)
+			}
+		}
+		if containsSeq(args, "--unshare-net") {
+			t.Errorf("expected network to be shared, got %v", args)
+		}
+
+		// tmpfs must come after the default /etc bind to hide it
+		joined := strings.Join(args, " ")
+		if strings.Index(joined, "--tmpfs /etc") < strings.Index(joined, "--ro-bind /etc /etc") {
+			t.Errorf("expected --tmpfs /etc after the /etc bind, got %v", args)
+		}
+	})
+
+	t.Run("bind outside allowed paths", func(t *testing.T) {
+		cfg := &plugin.BwrapConfig{ROBinds: []plugin.BindMount{{Src: "/usr"}}}
+		if _, _, err := s.WrapCommand(rootDir, "ls", nil, cfg, nil); err == nil {
+			t.Error("expected an error for a bind outside the allowed paths")
+		}
+	})
+}
+
+func TestValidateBwrapBinds(t *testing.T) {
+	rootDir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(rootDir, "data"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	allowedDir := t.TempDir()
+	otherDir := t.TempDir()
+	// A symlink inside the root pointing outside it must not pass
+	if err := os.Symlink(otherDir, filepath.Join(rootDir, "escape")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		cfg     *plugin.BwrapConfig
+		wantErr bool
+	}{
+		{"nil", nil, false},
+		{"relative under root", &plugin.BwrapConfig{ROBinds: []plugin.BindMount{{Src: "data"}}}, false},
+		{"absolute under root", &plugin.BwrapConfig{RWBinds: []plugin.BindMount{{Src: filepath.Join(rootDir, "data")}}}, false},
+		{"allowed bind path", &plugin.BwrapConfig{ROBinds: []plugin.BindMount{{Src: allowedDir, Dest: "/allowed"}}}, false},
+		{"outside", &plugin.BwrapConfig{RWBinds: []plugin.BindMount{{Src: otherDir}}}, true},
+		{"symlink escape", &plugin.BwrapConfig{ROBinds: []plugin.BindMount{{Src: "escape"}}}, true},
+		{"missing source", &plugin.BwrapConfig{ROBinds: []plugin.BindMount{{Src: "missing"}}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateBwrapBinds(tt.cfg, rootDir, []string{allowedDir})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateBwrapBinds() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -18,14 +19,23 @@ const (
 	DefaultTimeout = 30 * time.Second
 	// DefaultMaxOutput is the default maximum output size in bytes
 	DefaultMaxOutput = 1024 * 1024 // 1MB
+
+	// processWaitDelay bounds how long to wait for output pipes after a command is killed
+	processWaitDelay = time.Second
 )
 
 // ExecutorConfig holds executor configuration
 type ExecutorConfig struct {
-	Timeout   time.Duration
-	MaxOutput int
-	RootDir   string // If set, restricts execution to this directory (jail/sandbox)
-	WasmDir   string // Optional: directory containing WASM binaries (mounted as /.wasm)
+	Timeout        time.Duration
+	MaxOutput      int
+	TimeoutCeiling time.Duration // Upper bound for per-tool timeouts (0 = DefaultTimeoutCeiling)
+	OutputCeiling  int           // Upper bound for per-tool output limits (0 = DefaultOutputCeiling)
+	MaxConcurrency int           // Maximum concurrent executions across all tools (0 = unlimited)
+	MaxQueue       int           // Maximum executions waiting for a slot
+	QueueTimeout   time.Duration // Maximum time to wait for a slot (0 = until the request ends)
+	RootDir        string        // If set, restricts execution to this directory (jail/sandbox)
+	WasmDir        string        // Optional: directory containing WASM binaries (mounted as /.wasm)
+	BindPaths      []string      // Host paths outside RootDir that tool bwrap binds may use
 }
 
 // DefaultConfig returns the default executor configuration
@@ -38,18 +48,40 @@ func DefaultConfig() *ExecutorConfig {
 
 // ExecuteResult represents the result of command execution
 type ExecuteResult struct {
-	Stdout     string
-	Stderr     string
-	ExitCode   int
-	DurationMs int64
-	TimedOut   bool
+	Stdout          string
+	Stderr          string
+	ExitCode        int
+	DurationMs      int64
+	TimedOut        bool
+	Cancelled       bool             // The caller cancelled the execution context
+	LimitExceeded   string           // Resource limit that ended the command (LimitMemory, LimitCPU, LimitFileSize), if detected
+	StdoutTruncated bool             // Stdout exceeded the output limit and was cut short
+	Workspace       *WorkspaceResult // Changes made in the overlay workspace (nil = no workspace)
+}
+
+// ExecuteOptions holds optional per-call execution settings
+type ExecuteOptions struct {
+	OnOutput        OutputFunc             // Called with stdout/stderr chunks while the command runs
+	Timeout         time.Duration          // Overrides the configured timeout, capped at the ceiling (0 = default)
+	MaxOutput       int                    // Overrides the stdout limit, and the stderr limit unless MaxStderr is set (0 = default)
+	MaxStderr       int                    // Overrides the stderr limit (0 = same as stdout)
+	ToolName        string                 // Tool being executed; keys the per-tool concurrency limit
+	MaxConcurrency  int                    // Maximum concurrent executions of ToolName (0 = unlimited)
+	Resources       *plugin.ResourceLimits // Memory, CPU, process and file limits (nil = unlimited)
+	Bwrap           *plugin.BwrapConfig    // Bubblewrap mount profile (nil = default profile)
+	Stdin           io.Reader              // Standard input of the command (nil = no input)
+	InputFiles      *InputFiles            // File arguments made visible to the command (nil = none)
+	Workspace       string                 // "overlay" runs a bubblewrap command against an overlay of the root directory
+	CommitWorkspace bool                   // Apply the overlay's changes to the root directory if the command succeeds
 }
 
 // Executor executes commands with timeout and output limits
 type Executor struct {
 	config       *ExecutorConfig
 	sandbox      *Sandbox
+	sandboxErr   error // Why the sandbox could not be initialized, if it was not
 	wasmExecutor *WasmExecutor
+	queue        *execQueue
 }
 
 // NewExecutor creates a new Executor
@@ -58,20 +90,25 @@ func NewExecutor(config *ExecutorConfig) *Executor {
 		config = DefaultConfig()
 	}
 
-	e := &Executor{config: config}
+	e := &Executor{
+		config: config,
+		queue:  newExecQueue(config.MaxConcurrency, config.MaxQueue, config.QueueTimeout),
+	}
 
 	// Initialize sandbox if root directory is set
 	if config.RootDir != "" {
 		// Initialize sandbox to check bwrap availability
 		sandboxConfig := &SandboxConfig{
-			Mode:    SandboxAuto,
-			RootDir: config.RootDir,
+			Mode:      SandboxAuto,
+			RootDir:   config.RootDir,
+			BindPaths: config.BindPaths,
 		}
 
 		sandbox, err := NewSandbox(sandboxConfig)
 		if err != nil {
 			// Log error but continue with basic path validation
 			fmt.Fprintf(os.Stderr, "WARNING: Failed to initialize sandbox: %v\n", err)
+			e.sandboxErr = err
 		} else {
 			e.sandbox = sandbox
 		}
@@ -85,8 +122,14 @@ func NewExecutor(config *ExecutorConfig) *Executor {
 
 // Execute executes a command with the given parameters
 func (e *Executor) Execute(ctx context.Context, cwd, cmd string, args []string, env []string) (*ExecuteResult, error) {
+	return e.execute(ctx, cwd, cmd, args, env, nil)
+}
+
+// execute executes a command using the executor's configured sandbox mode
+func (e *Executor) execute(ctx context.Context, cwd, cmd string, args []string, env []string, opts *ExecuteOptions) (*ExecuteResult, error) {
 	result := &ExecuteResult{}
 	startTime := time.Now()
+	limits := e.resolveLimits(opts)
 
 	var actualCmd string
 	var actualArgs []string
@@ -94,7 +137,7 @@ func (e *Executor) Execute(ctx context.Context, cwd, cmd string, args []string,
 	// Use sandbox if available
 	if e.sandbox != nil {
 		var err error
-		actualCmd, actualArgs, err = e.sandbox.WrapCommand(cwd, cmd, args)
+		actualCmd, actualArgs, err = e.sandbox.WrapCommand(cwd, cmd, args, bwrapConfig(opts), &CallMounts{Inputs: inputFiles(opts)})
 		if err != nil {
 			return nil, fmt.Errorf("sandbox validation failed: %w", err)
 		}
@@ -111,28 +154,37 @@ func (e *Executor) Execute(ctx context.Context, cwd, cmd string, args []string,
 	}
 
 	// Create context with timeout
-	execCtx, cancel := context.WithTimeout(ctx, e.config.Timeout)
+	execCtx, cancel := context.WithTimeout(ctx, limits.timeout)
 	defer cancel()
 
 	// Create command
 	execCmd := exec.CommandContext(execCtx, actualCmd, actualArgs...)
-	// Only set Dir if not using bwrap (bwrap sets it via --chdir)
-	if e.sandbox == nil || e.sandbox.Mode() != SandboxBwrap {
+	// Only set Dir if not using bwrap or namespaces (they change into cwd themselves)
+	if e.sandbox == nil || !e.sandbox.IsIsolated() {
 		execCmd.Dir = cwd
 	}
 	if len(env) > 0 {
 		execCmd.Env = env
 	}
+	execCmd.Stdin = stdinReader(opts)
+	setProcessGroup(execCmd)
+	closeIsolation, err := e.isolateCommand(execCmd, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer closeIsolation()
+	if err := applyResourceLimits(execCmd, limits.resources); err != nil {
+		return nil, err
+	}
 
 	// Capture output with limits
 	var stdout, stderr limitedBuffer
-	stdout.maxSize = e.config.MaxOutput
-	stderr.maxSize = e.config.MaxOutput
-	execCmd.Stdout = &stdout
-	execCmd.Stderr = &stderr
+	stdout.maxSize = limits.maxStdout
+	stderr.maxSize = limits.maxStderr
+	execCmd.Stdout, execCmd.Stderr = outputWriters(&stdout, &stderr, opts)
 
 	// Execute
-	err := execCmd.Run()
+	err = execCmd.Run()
 
 	// Calculate duration
 	result.DurationMs = time.Since(startTime).Milliseconds()
@@ -144,9 +196,14 @@ func (e *Executor) Execute(ctx context.Context, cwd, cmd string, args []string,
 		if execCtx.Err() == context.DeadlineExceeded {
 			result.TimedOut = true
 			result.ExitCode = -1
-			result.Stderr = fmt.Sprintf("%s\n[execution timed out after %v]", result.Stderr, e.config.Timeout)
+			result.Stderr = fmt.Sprintf("%s\n[execution timed out after %v]", result.Stderr, limits.timeout)
+		} else if execCtx.Err() == context.Canceled {
+			result.Cancelled = true
+			result.ExitCode = -1
+			result.Stderr = fmt.Sprintf("%s\n[execution cancelled]", result.Stderr)
 		} else if exitErr, ok := err.(*exec.ExitError); ok {
 			result.ExitCode = exitErr.ExitCode()
+			recordLimitExceeded(result, exitErr.ProcessState, limits.resources)
 		} else {
 			return nil, fmt.Errorf("failed to execute command: %w", err)
 		}
@@ -154,15 +211,46 @@ func (e *Executor) Execute(ctx context.Context, cwd, cmd string, args []string,
 
 	// Add truncation notice if output was limited
 	if stdout.truncated {
-		result.Stdout += fmt.Sprintf("\n[output truncated, exceeded %d bytes]", e.config.MaxOutput)
+		result.StdoutTruncated = true
+		result.Stdout += fmt.Sprintf("\n[output truncated, exceeded %d bytes]", limits.maxStdout)
 	}
 	if stderr.truncated {
-		result.Stderr += fmt.Sprintf("\n[output truncated, exceeded %d bytes]", e.config.MaxOutput)
+		result.Stderr += fmt.Sprintf("\n[output truncated, exceeded %d bytes]", limits.maxStderr)
 	}
 
 	return result, nil
 }
 
+// bwrapConfig returns the bubblewrap mount profile requested by opts, if any
+func bwrapConfig(opts *ExecuteOptions) *plugin.BwrapConfig {
+	if opts == nil {
+		return nil
+	}
+	return opts.Bwrap
+}
+
+// stdinReader returns the standard input requested by opts, if any
+func stdinReader(opts *ExecuteOptions) io.Reader {
+	if opts == nil {
+		return nil
+	}
+	return opts.Stdin
+}
+
+// isolateCommand applies the per-command part of the sandbox: the seccomp filter for
+// bwrap and the clone flags for the namespace sandbox. Call it after setProcessGroup.
+func (e *Executor) isolateCommand(cmd *exec.Cmd, opts *ExecuteOptions) (func(), error) {
+	if e.sandbox != nil {
+		switch e.sandbox.Mode() {
+		case SandboxBwrap:
+			return applySeccomp(cmd, bwrapConfig(opts))
+		case SandboxNamespace:
+			applyNamespaces(cmd, bwrapConfig(opts))
+		}
+	}
+	return func() {}, nil
+}
+
 // ExecuteWithEnvFilter executes a command with filtered environment variables
 func (e *Executor) ExecuteWithEnvFilter(ctx context.Context, cwd, cmd string, args []string, baseEnv []string, allowedKeys []string) (*ExecuteResult, error) {
 	var filteredEnv []string
@@ -220,7 +308,8 @@ func (b *limitedBuffer) Write(p []byte) (n int, err error) {
 
 	if len(p) > remaining {
 		b.truncated = true
-		return b.buf.Write(p[:remaining])
+		b.buf.Write(p[:remaining])
+		return len(p), nil // Report the whole write so the copy does not fail with a short write
 	}
 
 	return b.buf.Write(p)
@@ -268,6 +357,14 @@ func (e *Executor) validatePath(path string) error {
 	return nil
 }
 
+// ValidateCwd returns an error if the working directory of a call is outside the root directory
+func (e *Executor) ValidateCwd(cwd string) error {
+	if err := e.validatePath(cwd); err != nil {
+		return fmt.Errorf("cwd validation failed: %w", err)
+	}
+	return nil
+}
+
 // IsPathWithinRoot checks if a path is within or equal to the root directory
 func IsPathWithinRoot(root, path string) bool {
 	// Clean and normalize paths
@@ -296,9 +393,9 @@ func (e *Executor) GetSandboxMode() SandboxMode {
 	return SandboxNone
 }
 
-// IsSandboxed returns true if commands are sandboxed with bwrap
+// IsSandboxed returns true if commands are sandboxed with bwrap or namespaces
 func (e *Executor) IsSandboxed() bool {
-	return e.sandbox != nil && e.sandbox.Mode() == SandboxBwrap
+	return e.sandbox != nil && e.sandbox.IsIsolated()
 }
 
 // PrepareSandbox prepares sandbox mount directories if using bubblewrap
@@ -325,8 +422,30 @@ func (e *Executor) GetSandboxCreatedDirs() []string {
 	return nil
 }
 
-// ExecuteWithSandbox executes a command using the specified sandbox type from the tool
-func (e *Executor) ExecuteWithSandbox(ctx context.Context, cwd, cmd string, args []string, env []string, sandboxType plugin.SandboxType, wasmBinary string) (*ExecuteResult, error) {
+// ExecuteWithSandbox executes a command using the specified sandbox type from the tool.
+// It waits for an execution slot first and returns ErrQueueFull or ErrQueueTimeout
+// if none becomes available. opts may be nil.
+func (e *Executor) ExecuteWithSandbox(ctx context.Context, cwd, cmd string, args []string, env []string, sandboxType plugin.SandboxType, wasmBinary string, opts *ExecuteOptions) (*ExecuteResult, error) {
+	var toolName string
+	var toolMaxConcurrency int
+	if opts != nil {
+		toolName, toolMaxConcurrency = opts.ToolName, opts.MaxConcurrency
+	}
+	startTime := time.Now()
+	release, err := e.queue.acquire(ctx, toolName, toolMaxConcurrency)
+	if err != nil {
+		if err == context.Canceled {
+			return &ExecuteResult{
+				ExitCode:   -1,
+				Stderr:     "[execution cancelled]",
+				DurationMs: time.Since(startTime).Milliseconds(),
+				Cancelled:  true,
+			}, nil
+		}
+		return nil, err
+	}
+	defer release()
+
 	switch sandboxType {
 	case plugin.SandboxTypeWasm:
 		if e.wasmExecutor == nil {
@@ -336,26 +455,28 @@ func (e *Executor) ExecuteWithSandbox(ctx context.Context, cwd, cmd string, args
 			return nil, fmt.Errorf("WASM binary path is required for WASM sandbox")
 		}
 		// For WASM, the wasmBinary is the WASM file to execute, and args are passed to it
-		return e.wasmExecutor.Execute(ctx, wasmBinary, cwd, args, env, e.config.Timeout, e.config.MaxOutput)
+		limits := e.resolveLimits(opts)
+		return e.wasmExecutor.execute(ctx, wasmBinary, cwd, args, env, limits, opts)
 
 	case plugin.SandboxTypeNone:
 		// Execute without any sandbox
-		return e.executeWithoutSandbox(ctx, cwd, cmd, args, env)
+		return e.executeWithoutSandbox(ctx, cwd, cmd, args, env, opts)
 
 	case plugin.SandboxTypeBubblewrap:
 		// Execute with bubblewrap sandbox
-		return e.executeWithBwrap(ctx, cwd, cmd, args, env)
+		return e.executeWithBwrap(ctx, cwd, cmd, args, env, opts)
 
 	default:
 		// Default to using the executor's configured sandbox mode
-		return e.Execute(ctx, cwd, cmd, args, env)
+		return e.execute(ctx, cwd, cmd, args, env, opts)
 	}
 }
 
 // executeWithoutSandbox executes a command without any sandboxing
-func (e *Executor) executeWithoutSandbox(ctx context.Context, cwd, cmd string, args []string, env []string) (*ExecuteResult, error) {
+func (e *Executor) executeWithoutSandbox(ctx context.Context, cwd, cmd string, args []string, env []string, opts *ExecuteOptions) (*ExecuteResult, error) {
 	result := &ExecuteResult{}
 	startTime := time.Now()
+	limits := e.resolveLimits(opts)
 
 	// Basic path validation if root directory is configured
 	if e.config.RootDir != "" {
@@ -365,7 +486,7 @@ func (e *Executor) executeWithoutSandbox(ctx context.Context, cwd, cmd string, a
 	}
 
 	// Create context with timeout
-	execCtx, cancel := context.WithTimeout(ctx, e.config.Timeout)
+	execCtx, cancel := context.WithTimeout(ctx, limits.timeout)
 	defer cancel()
 
 	// Create command
@@ -374,13 +495,17 @@ func (e *Executor) executeWithoutSandbox(ctx context.Context, cwd, cmd string, a
 	if len(env) > 0 {
 		execCmd.Env = env
 	}
+	execCmd.Stdin = stdinReader(opts)
+	setProcessGroup(execCmd)
+	if err := applyResourceLimits(execCmd, limits.resources); err != nil {
+		return nil, err
+	}
 
 	// Capture output with limits
 	var stdout, stderr limitedBuffer
-	stdout.maxSize = e.config.MaxOutput
-	stderr.maxSize = e.config.MaxOutput
-	execCmd.Stdout = &stdout
-	execCmd.Stderr = &stderr
+	stdout.maxSize = limits.maxStdout
+	stderr.maxSize = limits.maxStderr
+	execCmd.Stdout, execCmd.Stderr = outputWriters(&stdout, &stderr, opts)
 
 	// Execute
 	err := execCmd.Run()
@@ -395,9 +520,14 @@ func (e *Executor) executeWithoutSandbox(ctx context.Context, cwd, cmd string, a
 		if execCtx.Err() == context.DeadlineExceeded {
 			result.TimedOut = true
 			result.ExitCode = -1
-			result.Stderr = fmt.Sprintf("%s\n[execution timed out after %v]", result.Stderr, e.config.Timeout)
+			result.Stderr = fmt.Sprintf("%s\n[execution timed out after %v]", result.Stderr, limits.timeout)
+		} else if execCtx.Err() == context.Canceled {
+			result.Cancelled = true
+			result.ExitCode = -1
+			result.Stderr = fmt.Sprintf("%s\n[execution cancelled]", result.Stderr)
 		} else if exitErr, ok := err.(*exec.ExitError); ok {
 			result.ExitCode = exitErr.ExitCode()
+			recordLimitExceeded(result, exitErr.ProcessState, limits.resources)
 		} else {
 			return nil, fmt.Errorf("failed to execute command: %w", err)
 		}
@@ -405,32 +535,41 @@ func (e *Executor) executeWithoutSandbox(ctx context.Context, cwd, cmd string, a
 
 	// Add truncation notice if output was limited
 	if stdout.truncated {
-		result.Stdout += fmt.Sprintf("\n[output truncated, exceeded %d bytes]", e.config.MaxOutput)
+		result.StdoutTruncated = true
+		result.Stdout += fmt.Sprintf("\n[output truncated, exceeded %d bytes]", limits.maxStdout)
 	}
 	if stderr.truncated {
-		result.Stderr += fmt.Sprintf("\n[output truncated, exceeded %d bytes]", e.config.MaxOutput)
+		result.Stderr += fmt.Sprintf("\n[output truncated, exceeded %d bytes]", limits.maxStderr)
 	}
 
 	return result, nil
 }
 
-// executeWithBwrap executes a command with bubblewrap sandbox
-func (e *Executor) executeWithBwrap(ctx context.Context, cwd, cmd string, args []string, env []string) (*ExecuteResult, error) {
-	if e.sandbox == nil || !e.sandbox.IsBwrapAvailable() {
-		return nil, fmt.Errorf("bubblewrap (bwrap) is required but not installed")
+// executeWithBwrap executes a command with bubblewrap sandbox, or the native namespace
+// sandbox when bwrap is not installed
+func (e *Executor) executeWithBwrap(ctx context.Context, cwd, cmd string, args []string, env []string, opts *ExecuteOptions) (*ExecuteResult, error) {
+	if e.sandbox == nil || !e.sandbox.IsIsolated() {
+		return nil, fmt.Errorf("bubblewrap (bwrap) is required but not installed, and Linux namespaces are not available")
 	}
 
 	result := &ExecuteResult{}
 	startTime := time.Now()
+	limits := e.resolveLimits(opts)
+
+	workspace, err := e.newToolWorkspace(opts)
+	if err != nil {
+		return nil, err
+	}
+	defer workspace.Close()
 
 	// Wrap command with bwrap
-	actualCmd, actualArgs, err := e.sandbox.WrapCommand(cwd, cmd, args)
+	actualCmd, actualArgs, err := e.sandbox.WrapCommand(cwd, cmd, args, bwrapConfig(opts), &CallMounts{Inputs: inputFiles(opts), Workspace: workspace})
 	if err != nil {
 		return nil, fmt.Errorf("sandbox validation failed: %w", err)
 	}
 
 	// Create context with timeout
-	execCtx, cancel := context.WithTimeout(ctx, e.config.Timeout)
+	execCtx, cancel := context.WithTimeout(ctx, limits.timeout)
 	defer cancel()
 
 	// Create command
@@ -439,13 +578,22 @@ func (e *Executor) executeWithBwrap(ctx context.Context, cwd, cmd string, args [
 	if len(env) > 0 {
 		execCmd.Env = env
 	}
+	execCmd.Stdin = stdinReader(opts)
+	setProcessGroup(execCmd)
+	closeIsolation, err := e.isolateCommand(execCmd, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer closeIsolation()
+	if err := applyResourceLimits(execCmd, limits.resources); err != nil {
+		return nil, err
+	}
 
 	// Capture output with limits
 	var stdout, stderr limitedBuffer
-	stdout.maxSize = e.config.MaxOutput
-	stderr.maxSize = e.config.MaxOutput
-	execCmd.Stdout = &stdout
-	execCmd.Stderr = &stderr
+	stdout.maxSize = limits.maxStdout
+	stderr.maxSize = limits.maxStderr
+	execCmd.Stdout, execCmd.Stderr = outputWriters(&stdout, &stderr, opts)
 
 	// Execute
 	err = execCmd.Run()
@@ -460,9 +608,14 @@ func (e *Executor) executeWithBwrap(ctx context.Context, cwd, cmd string, args [
 		if execCtx.Err() == context.DeadlineExceeded {
 			result.TimedOut = true
 			result.ExitCode = -1
-			result.Stderr = fmt.Sprintf("%s\n[execution timed out after %v]", result.Stderr, e.config.Timeout)
+			result.Stderr = fmt.Sprintf("%s\n[execution timed out after %v]", result.Stderr, limits.timeout)
+		} else if execCtx.Err() == context.Canceled {
+			result.Cancelled = true
+			result.ExitCode = -1
+			result.Stderr = fmt.Sprintf("%s\n[execution cancelled]", result.Stderr)
 		} else if exitErr, ok := err.(*exec.ExitError); ok {
 			result.ExitCode = exitErr.ExitCode()
+			recordLimitExceeded(result, exitErr.ProcessState, limits.resources)
 		} else {
 			return nil, fmt.Errorf("failed to execute command: %w", err)
 		}
@@ -470,10 +623,17 @@ func (e *Executor) executeWithBwrap(ctx context.Context, cwd, cmd string, args [
 
 	// Add truncation notice if output was limited
 	if stdout.truncated {
-		result.Stdout += fmt.Sprintf("\n[output truncated, exceeded %d bytes]", e.config.MaxOutput)
+		result.StdoutTruncated = true
+		result.Stdout += fmt.Sprintf("\n[output truncated, exceeded %d bytes]", limits.maxStdout)
 	}
 	if stderr.truncated {
-		result.Stderr += fmt.Sprintf("\n[output truncated, exceeded %d bytes]", e.config.MaxOutput)
+		result.Stderr += fmt.Sprintf("\n[output truncated, exceeded %d bytes]", limits.maxStderr)
+	}
+
+	if workspace != nil {
+		if result.Workspace, err = workspace.Finish(result, opts.CommitWorkspace); err != nil {
+			return nil, err
+		}
 	}
 
 	return result, nil
@@ -3,8 +3,12 @@ package executor
 import (
 	"context"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/takeshy/mcp-gatekeeper/internal/plugin"
 )
 
 func TestExecutor_Execute(t *testing.T) {
@@ -415,3 +419,70 @@ func TestExecutor_GetRootDir(t *testing.T) {
 		})
 	}
 }
+
+// catWasm is a WASI module that copies stdin to stdout
+var catWasm = []byte{
+	0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00, // magic, version
+	0x01, 0x0c, 0x02, // types
+	0x60, 0x04, 0x7f, 0x7f, 0x7f, 0x7f, 0x01, 0x7f, // (i32, i32, i32, i32) -> i32
+	0x60, 0x00, 0x00, // () -> ()
+	0x02, 0x44, 0x02, // imports: fd_read and fd_write from wasi_snapshot_preview1
+	0x16, 'w', 'a', 's', 'i', '_', 's', 'n', 'a', 'p', 's', 'h', 'o', 't', '_', 'p', 'r', 'e', 'v', 'i', 'e', 'w', '1',
+	0x07, 'f', 'd', '_', 'r', 'e', 'a', 'd', 0x00, 0x00,
+	0x16, 'w', 'a', 's', 'i', '_', 's', 'n', 'a', 'p', 's', 'h', 'o', 't', '_', 'p', 'r', 'e', 'v', 'i', 'e', 'w', '1',
+	0x08, 'f', 'd', '_', 'w', 'r', 'i', 't', 'e', 0x00, 0x00,
+	0x03, 0x02, 0x01, 0x01, // function 2: type 1
+	0x05, 0x03, 0x01, 0x00, 0x01, // memory: min 1 page
+	0x07, 0x13, 0x02, // exports
+	0x06, 'm', 'e', 'm', 'o', 'r', 'y', 0x02, 0x00,
+	0x06, '_', 's', 't', 'a', 'r', 't', 0x00, 0x02,
+	0x0a, 0x43, 0x01, 0x41, 0x00, // code: one body, no locals
+	0x41, 0x00, 0x41, 0x10, 0x36, 0x02, 0x00, // iov.buf = 16
+	0x02, 0x40, 0x03, 0x40, // block, loop
+	0x41, 0x04, 0x41, 0x80, 0x08, 0x36, 0x02, 0x00, // iov.len = 1024
+	0x41, 0x00, 0x41, 0x00, 0x41, 0x01, 0x41, 0x08, 0x10, 0x00, 0x1a, // fd_read(0, iov, 1, &n)
+	0x41, 0x08, 0x28, 0x02, 0x00, 0x45, 0x0d, 0x01, // break if n == 0
+	0x41, 0x04, 0x41, 0x08, 0x28, 0x02, 0x00, 0x36, 0x02, 0x00, // iov.len = n
+	0x41, 0x01, 0x41, 0x00, 0x41, 0x01, 0x41, 0x0c, 0x10, 0x01, 0x1a, // fd_write(1, iov, 1, &written)
+	0x0c, 0x00, 0x0b, 0x0b, 0x0b, // continue, end loop, end block, end
+}
+
+func TestExecutor_Stdin(t *testing.T) {
+	dir := t.TempDir()
+	wasmPath := filepath.Join(dir, "cat.wasm")
+	if err := os.WriteFile(wasmPath, catWasm, 0644); err != nil {
+		t.Fatalf("write wasm: %v", err)
+	}
+	e := NewExecutor(&ExecutorConfig{RootDir: dir})
+
+	tests := []struct {
+		name    string
+		sandbox plugin.SandboxType
+		cmd     string
+		wasm    string
+	}{
+		{name: "none", sandbox: plugin.SandboxTypeNone, cmd: "cat"},
+		{name: "wasm", sandbox: plugin.SandboxTypeWasm, wasm: wasmPath},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := strings.Repeat("line of input\n", 200)
+			opts := &ExecuteOptions{Stdin: strings.NewReader(input)}
+			result, err := e.ExecuteWithSandbox(context.Background(), dir, tt.cmd, nil, nil, tt.sandbox, tt.wasm, opts)
+			if err != nil {
+				t.Fatalf("ExecuteWithSandbox() error = %v", err)
+			}
+			if result.ExitCode != 0 || result.Stdout != input {
+				t.Errorf("got exit code %d, %d bytes of stdout; want the %d input bytes (stderr: %s)", result.ExitCode, len(result.Stdout), len(input), result.Stderr)
+			}
+
+			result, err = e.ExecuteWithSandbox(context.Background(), dir, tt.cmd, nil, nil, tt.sandbox, tt.wasm, nil)
+			if err != nil {
+				t.Fatalf("ExecuteWithSandbox() error = %v", err)
+			}
+			if result.ExitCode != 0 || result.Stdout != "" {
+				t.Errorf("expected empty output without stdin, got %q (exit code %d)", result.Stdout, result.ExitCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/takeshy/mcp-gatekeeper/internal/plugin"
+)
+
+// InputFilesDir is where commands of sandboxed tools find their file arguments.
+// It is a tmpfs for bubblewrap and a read-only mount for WASM.
+const InputFilesDir = "/tmp/mcp-files"
+
+// InputFiles is the per-call scratch directory holding the file arguments of a tool call
+type InputFiles struct {
+	hostDir string
+	dir     string   // Directory as seen by the command
+	files   []string // Paths relative to the directory, in the order added
+}
+
+// NewInputFiles creates the scratch directory for a call to the tool, or returns nil
+// if the tool has no file parameters. Close removes it.
+func NewInputFiles(tool *plugin.Tool) (*InputFiles, error) {
+	if !tool.HasFileParameters() {
+		return nil, nil
+	}
+	hostDir, err := os.MkdirTemp("", "mcp-gatekeeper-files-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create input file directory: %w", err)
+	}
+	return &InputFiles{hostDir: hostDir, dir: inputFilesDir(tool.Sandbox, hostDir)}, nil
+}
+
+// inputFilesDir returns the directory a command of the sandbox type sees the host directory as
+func inputFilesDir(sandbox plugin.SandboxType, hostDir string) string {
+	if sandbox == plugin.SandboxTypeBubblewrap || sandbox == plugin.SandboxTypeWasm {
+		return InputFilesDir
+	}
+	return hostDir
+}
+
+// ExplainInputFiles returns a FileFunc reporting where a tool's file arguments would be
+// written, without writing them
+func ExplainInputFiles(tool *plugin.Tool) plugin.FileFunc {
+	dir := inputFilesDir(tool.Sandbox, filepath.Join(os.TempDir(), "mcp-gatekeeper-files-*"))
+	return func(param string, file plugin.InputFile) (string, error) {
+		return commandPath(dir, path.Join(param, file.Name)), nil
+	}
+}
+
+// commandPath joins a slash-separated relative path to the directory the command sees
+func commandPath(dir, rel string) string {
+	if dir == InputFilesDir {
+		return path.Join(dir, rel)
+	}
+	return filepath.Join(dir, filepath.FromSlash(rel))
+}
+
+// Add writes a file argument to the directory and returns the path the command opens it by
+func (f *InputFiles) Add(param string, file plugin.InputFile) (string, error) {
+	if f == nil {
+		return "", fmt.Errorf("tool has no file parameters")
+	}
+	rel := path.Join(param, file.Name)
+	if err := os.MkdirAll(filepath.Join(f.hostDir, param), 0755); err != nil {
+		return "", fmt.Errorf("failed to create input file directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(f.hostDir, filepath.FromSlash(rel)), file.Data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write input file: %w", err)
+	}
+	f.files = append(f.files, rel)
+	return commandPath(f.dir, rel), nil
+}
+
+// Close removes the directory and its files
+func (f *InputFiles) Close() error {
+	if f == nil {
+		return nil
+	}
+	return os.RemoveAll(f.hostDir)
+}
+
+// bwrapArgs returns the bwrap arguments that mount a tmpfs at InputFilesDir and bind
+// each file into it read-only
+func (f *InputFiles) bwrapArgs() []string {
+	args := []string{"--tmpfs", InputFilesDir}
+	for _, rel := range f.files {
+		args = append(args,
+			"--dir", path.Join(InputFilesDir, path.Dir(rel)),
+			"--ro-bind", filepath.Join(f.hostDir, filepath.FromSlash(rel)), path.Join(InputFilesDir, rel))
+	}
+	return args
+}
+
+// inputFiles returns the file arguments passed in opts, if any
+func inputFiles(opts *ExecuteOptions) *InputFiles {
+	if opts == nil {
+		return nil
+	}
+	return opts.InputFiles
+}
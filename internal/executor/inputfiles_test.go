@@ -0,0 +1,148 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/takeshy/mcp-gatekeeper/internal/plugin"
+)
+
+// catFileWasm is a WASI module that copies f/f, relative to its second preopened
+// directory, to stdout
+var catFileWasm = []byte{
+	0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00, // magic, version
+	0x01, 0x19, 0x03, // types
+	0x60, 0x04, 0x7f, 0x7f, 0x7f, 0x7f, 0x01, 0x7f, // (i32, i32, i32, i32) -> i32
+	0x60, 0x00, 0x00, // () -> ()
+	0x60, 0x09, 0x7f, 0x7f, 0x7f, 0x7f, 0x7f, 0x7e, 0x7e, 0x7f, 0x7f, 0x01, 0x7f, // path_open
+	0x02, 0x67, 0x03, // imports: fd_read, fd_write and path_open from wasi_snapshot_preview1
+	0x16, 'w', 'a', 's', 'i', '_', 's', 'n', 'a', 'p', 's', 'h', 'o', 't', '_', 'p', 'r', 'e', 'v', 'i', 'e', 'w', '1',
+	0x07, 'f', 'd', '_', 'r', 'e', 'a', 'd', 0x00, 0x00,
+	0x16, 'w', 'a', 's', 'i', '_', 's', 'n', 'a', 'p', 's', 'h', 'o', 't', '_', 'p', 'r', 'e', 'v', 'i', 'e', 'w', '1',
+	0x08, 'f', 'd', '_', 'w', 'r', 'i', 't', 'e', 0x00, 0x00,
+	0x16, 'w', 'a', 's', 'i', '_', 's', 'n', 'a', 'p', 's', 'h', 'o', 't', '_', 'p', 'r', 'e', 'v', 'i', 'e', 'w', '1',
+	0x09, 'p', 'a', 't', 'h', '_', 'o', 'p', 'e', 'n', 0x00, 0x02,
+	0x03, 0x02, 0x01, 0x01, // function 3: type 1
+	0x05, 0x03, 0x01, 0x00, 0x01, // memory: min 1 page
+	0x07, 0x13, 0x02, // exports
+	0x06, 'm', 'e', 'm', 'o', 'r', 'y', 0x02, 0x00,
+	0x06, '_', 's', 't', 'a', 'r', 't', 0x00, 0x03,
+	0x0a, 0x61, 0x01, 0x5f, 0x00, // code: one body, no locals
+	0x41, 0x00, 0x41, 0x10, 0x36, 0x02, 0x00, // iov.buf = 16
+	0x41, 0x04, 0x41, 0x00, 0x41, 0xb0, 0x09, 0x41, 0x03, 0x41, 0x00, // path_open(4, 0, "f/f", 3, 0,
+	0x42, 0x02, 0x42, 0x00, 0x41, 0x00, 0x41, 0xcc, 0x08, 0x10, 0x02, //   FD_READ, 0, 0, &fd)
+	0x04, 0x40, 0x00, 0x0b, // trap on error
+	0x02, 0x40, 0x03, 0x40, // block, loop
+	0x41, 0x04, 0x41, 0x80, 0x08, 0x36, 0x02, 0x00, // iov.len = 1024
+	0x41, 0xcc, 0x08, 0x28, 0x02, 0x00, 0x41, 0x00, 0x41, 0x01, 0x41, 0x08, 0x10, 0x00, 0x1a, // fd_read(fd, iov, 1, &n)
+	0x41, 0x08, 0x28, 0x02, 0x00, 0x45, 0x0d, 0x01, // break if n == 0
+	0x41, 0x04, 0x41, 0x08, 0x28, 0x02, 0x00, 0x36, 0x02, 0x00, // iov.len = n
+	0x41, 0x01, 0x41, 0x00, 0x41, 0x01, 0x41, 0x0c, 0x10, 0x01, 0x1a, // fd_write(1, iov, 1, &written)
+	0x0c, 0x00, 0x0b, 0x0b, 0x0b, // continue, end loop, end block, end
+	0x0b, 0x0a, 0x01, 0x00, 0x41, 0xb0, 0x09, 0x0b, 0x03, 'f', '/', 'f', // data: "f/f" at 1200
+}
+
+func fileTool(sandbox plugin.SandboxType) *plugin.Tool {
+	return &plugin.Tool{Name: "cat", Sandbox: sandbox, Parameters: []plugin.Parameter{{Name: "f", Type: plugin.ParameterTypeFile}}}
+}
+
+func TestInputFiles(t *testing.T) {
+	if inputs, err := NewInputFiles(&plugin.Tool{Name: "ls"}); inputs != nil || err != nil {
+		t.Fatalf("NewInputFiles() = %v, %v; want nil for a tool without file parameters", inputs, err)
+	}
+
+	t.Run("none", func(t *testing.T) {
+		inputs, err := NewInputFiles(fileTool(plugin.SandboxTypeNone))
+		if err != nil {
+			t.Fatalf("NewInputFiles() error = %v", err)
+		}
+		p, err := inputs.Add("f", plugin.InputFile{Name: "patch.diff", Data: []byte("data")})
+		if err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+		if p != filepath.Join(inputs.hostDir, "f", "patch.diff") {
+			t.Errorf("Add() = %q, want a host path", p)
+		}
+		if data, err := os.ReadFile(p); err != nil || string(data) != "data" {
+			t.Errorf("expected the file to be written, got %q, %v", data, err)
+		}
+		if err := inputs.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+		if _, err := os.Stat(inputs.hostDir); !os.IsNotExist(err) {
+			t.Errorf("expected the directory to be removed, got %v", err)
+		}
+	})
+
+	t.Run("bubblewrap", func(t *testing.T) {
+		inputs, err := NewInputFiles(fileTool(plugin.SandboxTypeBubblewrap))
+		if err != nil {
+			t.Fatalf("NewInputFiles() error = %v", err)
+		}
+		defer inputs.Close()
+		p, err := inputs.Add("f", plugin.InputFile{Name: "f", Data: []byte("data")})
+		if err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+		if p != "/tmp/mcp-files/f/f" {
+			t.Errorf("Add() = %q, want a path under %s", p, InputFilesDir)
+		}
+		want := []string{"--tmpfs", "/tmp/mcp-files", "--dir", "/tmp/mcp-files/f", "--ro-bind", filepath.Join(inputs.hostDir, "f", "f"), "/tmp/mcp-files/f/f"}
+		if got := inputs.bwrapArgs(); !reflect.DeepEqual(got, want) {
+			t.Errorf("bwrapArgs() = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("explain", func(t *testing.T) {
+		p, _ := ExplainInputFiles(fileTool(plugin.SandboxTypeWasm))("f", plugin.InputFile{Name: "x.csv"})
+		if p != "/tmp/mcp-files/f/x.csv" {
+			t.Errorf("ExplainInputFiles() path = %q", p)
+		}
+	})
+}
+
+func TestExecutor_InputFiles(t *testing.T) {
+	dir := t.TempDir()
+	wasmPath := filepath.Join(dir, "cat.wasm")
+	if err := os.WriteFile(wasmPath, catFileWasm, 0644); err != nil {
+		t.Fatalf("write wasm: %v", err)
+	}
+	e := NewExecutor(&ExecutorConfig{RootDir: dir})
+
+	tests := []struct {
+		sandbox plugin.SandboxType
+		cmd     string
+		wasm    string
+	}{
+		{sandbox: plugin.SandboxTypeNone, cmd: "cat"},
+		{sandbox: plugin.SandboxTypeWasm, wasm: wasmPath},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.sandbox), func(t *testing.T) {
+			inputs, err := NewInputFiles(fileTool(tt.sandbox))
+			if err != nil {
+				t.Fatalf("NewInputFiles() error = %v", err)
+			}
+			defer inputs.Close()
+			p, err := inputs.Add("f", plugin.InputFile{Name: "f", Data: []byte("uploaded")})
+			if err != nil {
+				t.Fatalf("Add() error = %v", err)
+			}
+
+			var args []string
+			if tt.sandbox == plugin.SandboxTypeNone {
+				args = []string{p}
+			}
+			result, err := e.ExecuteWithSandbox(context.Background(), dir, tt.cmd, args, nil, tt.sandbox, tt.wasm, &ExecuteOptions{InputFiles: inputs})
+			if err != nil {
+				t.Fatalf("ExecuteWithSandbox() error = %v", err)
+			}
+			if result.ExitCode != 0 || result.Stdout != "uploaded" {
+				t.Errorf("got %q (exit code %d, stderr %s), want the file content", result.Stdout, result.ExitCode, result.Stderr)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/takeshy/mcp-gatekeeper/internal/plugin"
+)
+
+// Effective isolation of a tool, as reported by ToolIsolation
+const (
+	IsolationNone        = "none"        // Path validation only
+	IsolationBwrap       = "bwrap"       // bubblewrap
+	IsolationNamespace   = "namespace"   // Built-in Linux namespace sandbox
+	IsolationWasm        = "wasm"        // wazero
+	IsolationUnavailable = "unavailable" // The declared sandbox cannot run the tool
+)
+
+// ToolIsolation returns the isolation the tool's commands actually run with
+func (e *Executor) ToolIsolation(tool *plugin.Tool) string {
+	switch tool.Sandbox {
+	case plugin.SandboxTypeBubblewrap:
+		if e.sandbox == nil || !e.sandbox.IsIsolated() {
+			return IsolationUnavailable
+		}
+		if e.sandbox.Mode() == SandboxNamespace {
+			return IsolationNamespace
+		}
+		return IsolationBwrap
+	case plugin.SandboxTypeWasm:
+		if e.wasmExecutor == nil {
+			return IsolationUnavailable
+		}
+		return IsolationWasm
+	}
+	return IsolationNone
+}
+
+// CheckSandbox returns an error if the tool's declared sandbox cannot run it: bubblewrap
+// tools need bwrap or Linux namespaces, and WASM tools a binary that compiles.
+// A compiled WASM module is cached for later executions.
+func (e *Executor) CheckSandbox(ctx context.Context, tool *plugin.Tool) error {
+	switch tool.Sandbox {
+	case plugin.SandboxTypeBubblewrap:
+		if e.sandboxErr != nil {
+			return fmt.Errorf("sandbox initialization failed: %w", e.sandboxErr)
+		}
+		if e.ToolIsolation(tool) == IsolationUnavailable {
+			return fmt.Errorf("bubblewrap sandbox requires bwrap or Linux namespaces, and neither is available")
+		}
+		if tool.Bwrap != nil && tool.Bwrap.Seccomp != "" {
+			if _, err := compileSeccomp(tool.Bwrap.Seccomp, hostSeccompArch); err != nil {
+				return err
+			}
+		}
+	case plugin.SandboxTypeWasm:
+		if e.wasmExecutor == nil {
+			return fmt.Errorf("WASM executor not initialized (root directory not set)")
+		}
+		if _, _, err := e.wasmExecutor.getOrCompile(ctx, tool.WasmBinary, tool.Limits.WasmMemoryPages()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/takeshy/mcp-gatekeeper/internal/plugin"
+)
+
+func TestToolIsolation(t *testing.T) {
+	bwrapTool := &plugin.Tool{Name: "b", Sandbox: plugin.SandboxTypeBubblewrap}
+	wasmTool := &plugin.Tool{Name: "w", Sandbox: plugin.SandboxTypeWasm}
+	noneTool := &plugin.Tool{Name: "n", Sandbox: plugin.SandboxTypeNone}
+
+	tests := []struct {
+		name string
+		e    *Executor
+		tool *plugin.Tool
+		want string
+	}{
+		{"bwrap", &Executor{sandbox: &Sandbox{mode: SandboxBwrap, bwrap: "/usr/bin/bwrap"}}, bwrapTool, IsolationBwrap},
+		{"namespace", &Executor{sandbox: &Sandbox{mode: SandboxNamespace}}, bwrapTool, IsolationNamespace},
+		{"bubblewrap unavailable", &Executor{sandbox: &Sandbox{mode: SandboxNone}}, bwrapTool, IsolationUnavailable},
+		{"no sandbox", &Executor{}, bwrapTool, IsolationUnavailable},
+		{"wasm", &Executor{wasmExecutor: NewWasmExecutor("/tmp", "")}, wasmTool, IsolationWasm},
+		{"wasm without root", &Executor{}, wasmTool, IsolationUnavailable},
+		{"none", &Executor{}, noneTool, IsolationNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.e.ToolIsolation(tt.tool); got != tt.want {
+				t.Errorf("ToolIsolation() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckSandbox(t *testing.T) {
+	dir := t.TempDir()
+	validWasm := filepath.Join(dir, "hog.wasm")
+	if err := os.WriteFile(validWasm, memoryHogWasm, 0644); err != nil {
+		t.Fatal(err)
+	}
+	invalidWasm := filepath.Join(dir, "bad.wasm")
+	if err := os.WriteFile(invalidWasm, []byte("not wasm"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	wasmExec := &Executor{wasmExecutor: NewWasmExecutor(dir, "")}
+	defer wasmExec.wasmExecutor.Close()
+
+	tests := []struct {
+		name    string
+		e       *Executor
+		tool    *plugin.Tool
+		wantErr bool
+	}{
+		{"none", &Executor{}, &plugin.Tool{Sandbox: plugin.SandboxTypeNone}, false},
+		{"bwrap available", &Executor{sandbox: &Sandbox{mode: SandboxBwrap, bwrap: "/usr/bin/bwrap"}}, &plugin.Tool{Sandbox: plugin.SandboxTypeBubblewrap}, false},
+		{"bwrap missing", &Executor{sandbox: &Sandbox{mode: SandboxNone}}, &plugin.Tool{Sandbox: plugin.SandboxTypeBubblewrap}, true},
+		{"sandbox init failed", &Executor{sandboxErr: errors.New("root directory does not exist")}, &plugin.Tool{Sandbox: plugin.SandboxTypeBubblewrap}, true},
+		{"wasm compiles", wasmExec, &plugin.Tool{Sandbox: plugin.SandboxTypeWasm, WasmBinary: validWasm}, false},
+		{"wasm invalid", wasmExec, &plugin.Tool{Sandbox: plugin.SandboxTypeWasm, WasmBinary: invalidWasm}, true},
+		{"wasm missing", wasmExec, &plugin.Tool{Sandbox: plugin.SandboxTypeWasm, WasmBinary: filepath.Join(dir, "missing.wasm")}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.e.CheckSandbox(context.Background(), tt.tool)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckSandbox() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package executor
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/takeshy/mcp-gatekeeper/internal/plugin"
+)
+
+const (
+	// DefaultTimeoutCeiling is the default upper bound for per-tool timeouts
+	DefaultTimeoutCeiling = 10 * time.Minute
+	// DefaultOutputCeiling is the default upper bound for per-tool output limits in bytes
+	DefaultOutputCeiling = 10 * 1024 * 1024 // 10MB
+	// DefaultMaxConcurrency is the default maximum number of concurrent executions
+	DefaultMaxConcurrency = 16
+	// DefaultMaxQueue is the default maximum number of executions waiting for a slot
+	DefaultMaxQueue = 64
+	// DefaultQueueTimeout is the default maximum time to wait for an execution slot
+	DefaultQueueTimeout = 30 * time.Second
+)
+
+// Limits holds the server-wide execution limits
+type Limits struct {
+	Timeout        time.Duration // Timeout for tools that do not set their own
+	MaxOutput      int           // Per-stream output limit for tools that do not set their own
+	TimeoutCeiling time.Duration // Largest timeout a tool may set
+	OutputCeiling  int           // Largest output limit a tool may set
+	MaxConcurrency int           // Maximum concurrent executions across all tools (0 = unlimited)
+	MaxQueue       int           // Maximum executions waiting for a slot
+	QueueTimeout   time.Duration // Maximum time to wait for a slot (0 = until the request ends)
+}
+
+// DefaultLimits returns the default execution limits
+func DefaultLimits() Limits {
+	return Limits{
+		Timeout:        DefaultTimeout,
+		MaxOutput:      DefaultMaxOutput,
+		TimeoutCeiling: DefaultTimeoutCeiling,
+		OutputCeiling:  DefaultOutputCeiling,
+		MaxConcurrency: DefaultMaxConcurrency,
+		MaxQueue:       DefaultMaxQueue,
+		QueueTimeout:   DefaultQueueTimeout,
+	}
+}
+
+// Validate checks that the limits are positive, the defaults do not exceed the
+// ceilings and the concurrency settings are not negative
+func (l Limits) Validate() error {
+	if l.MaxConcurrency < 0 || l.MaxQueue < 0 || l.QueueTimeout < 0 {
+		return fmt.Errorf("concurrency and queue limits must not be negative")
+	}
+	if l.Timeout <= 0 || l.TimeoutCeiling <= 0 {
+		return fmt.Errorf("timeouts must be positive")
+	}
+	if l.MaxOutput <= 0 || l.OutputCeiling <= 0 {
+		return fmt.Errorf("output limits must be positive")
+	}
+	if l.Timeout > l.TimeoutCeiling {
+		return fmt.Errorf("timeout %v exceeds timeout ceiling %v", l.Timeout, l.TimeoutCeiling)
+	}
+	if l.MaxOutput > l.OutputCeiling {
+		return fmt.Errorf("max output %d exceeds output ceiling %d", l.MaxOutput, l.OutputCeiling)
+	}
+	return nil
+}
+
+// ValidateTool checks a tool's timeout and output limits against the ceilings
+func (l Limits) ValidateTool(t *plugin.Tool) error {
+	if timeout := t.TimeoutDuration(); timeout > l.TimeoutCeiling {
+		return fmt.Errorf("timeout %v exceeds the server ceiling %v", timeout, l.TimeoutCeiling)
+	}
+	if t.MaxOutputBytes > l.OutputCeiling {
+		return fmt.Errorf("max_output_bytes %d exceeds the server ceiling %d", t.MaxOutputBytes, l.OutputCeiling)
+	}
+	if t.MaxStderrBytes > l.OutputCeiling {
+		return fmt.Errorf("max_stderr_bytes %d exceeds the server ceiling %d", t.MaxStderrBytes, l.OutputCeiling)
+	}
+	return nil
+}
+
+// SetLimits replaces the executor's execution and concurrency limits; call it before executing
+func (e *Executor) SetLimits(l Limits) {
+	e.config.Timeout = l.Timeout
+	e.config.MaxOutput = l.MaxOutput
+	e.config.TimeoutCeiling = l.TimeoutCeiling
+	e.config.OutputCeiling = l.OutputCeiling
+	e.config.MaxConcurrency = l.MaxConcurrency
+	e.config.MaxQueue = l.MaxQueue
+	e.config.QueueTimeout = l.QueueTimeout
+	e.queue = newExecQueue(l.MaxConcurrency, l.MaxQueue, l.QueueTimeout)
+}
+
+// QueueStats returns the number of running and queued executions
+func (e *Executor) QueueStats() QueueStats {
+	return e.queue.stats()
+}
+
+// ToolOptions returns execute options applying a tool's own limits and sandbox profile
+func ToolOptions(t *plugin.Tool) *ExecuteOptions {
+	return &ExecuteOptions{
+		Timeout:         t.TimeoutDuration(),
+		MaxOutput:       t.MaxOutputBytes,
+		MaxStderr:       t.MaxStderrBytes,
+		ToolName:        t.Name,
+		MaxConcurrency:  t.MaxConcurrency,
+		Resources:       t.Limits,
+		Bwrap:           t.Bwrap,
+		Workspace:       t.Workspace,
+		CommitWorkspace: t.CommitsWorkspace(),
+	}
+}
+
+// execLimits holds the limits resolved for a single execution
+type execLimits struct {
+	timeout   time.Duration
+	maxStdout int
+	maxStderr int
+	resources *plugin.ResourceLimits
+}
+
+// resolveLimits applies per-call overrides to the configured limits, capped at the ceilings
+func (e *Executor) resolveLimits(opts *ExecuteOptions) execLimits {
+	l := execLimits{timeout: e.config.Timeout, maxStdout: e.config.MaxOutput}
+	if l.timeout <= 0 {
+		l.timeout = DefaultTimeout
+	}
+	if l.maxStdout <= 0 {
+		l.maxStdout = DefaultMaxOutput
+	}
+	timeoutCeiling := e.config.TimeoutCeiling
+	if timeoutCeiling <= 0 {
+		timeoutCeiling = DefaultTimeoutCeiling
+	}
+	outputCeiling := e.config.OutputCeiling
+	if outputCeiling <= 0 {
+		outputCeiling = DefaultOutputCeiling
+	}
+
+	if opts != nil {
+		if opts.Timeout > 0 {
+			l.timeout = min(opts.Timeout, timeoutCeiling)
+		}
+		if opts.MaxOutput > 0 {
+			l.maxStdout = min(opts.MaxOutput, outputCeiling)
+		}
+		l.resources = opts.Resources
+	}
+	l.maxStderr = l.maxStdout
+	if opts != nil && opts.MaxStderr > 0 {
+		l.maxStderr = min(opts.MaxStderr, outputCeiling)
+	}
+	return l
+}
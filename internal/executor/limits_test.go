@@ -0,0 +1,159 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/takeshy/mcp-gatekeeper/internal/plugin"
+)
+
+func TestLimitsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		limits  Limits
+		wantErr bool
+	}{
+		{"defaults", DefaultLimits(), false},
+		{"zero timeout", Limits{MaxOutput: 1, TimeoutCeiling: time.Second, OutputCeiling: 1}, true},
+		{"zero output", Limits{Timeout: time.Second, TimeoutCeiling: time.Second, OutputCeiling: 1}, true},
+		{"timeout above ceiling", Limits{Timeout: time.Minute, MaxOutput: 1, TimeoutCeiling: time.Second, OutputCeiling: 1}, true},
+		{"output above ceiling", Limits{Timeout: time.Second, MaxOutput: 2, TimeoutCeiling: time.Second, OutputCeiling: 1}, true},
+		{"unlimited concurrency", Limits{Timeout: time.Second, MaxOutput: 1, TimeoutCeiling: time.Second, OutputCeiling: 1}, false},
+		{"negative concurrency", Limits{Timeout: time.Second, MaxOutput: 1, TimeoutCeiling: time.Second, OutputCeiling: 1, MaxConcurrency: -1}, true},
+		{"negative queue timeout", Limits{Timeout: time.Second, MaxOutput: 1, TimeoutCeiling: time.Second, OutputCeiling: 1, QueueTimeout: -time.Second}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.limits.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLimitsValidateTool(t *testing.T) {
+	limits := Limits{Timeout: time.Second, MaxOutput: 100, TimeoutCeiling: time.Minute, OutputCeiling: 1000}
+
+	tests := []struct {
+		name    string
+		tool    plugin.Tool
+		wantErr string
+	}{
+		{"no limits", plugin.Tool{}, ""},
+		{"within ceilings", plugin.Tool{Timeout: "1m", MaxOutputBytes: 1000, MaxStderrBytes: 10}, ""},
+		{"timeout above ceiling", plugin.Tool{Timeout: "2m"}, "timeout"},
+		{"output above ceiling", plugin.Tool{MaxOutputBytes: 1001}, "max_output_bytes"},
+		{"stderr above ceiling", plugin.Tool{MaxStderrBytes: 1001}, "max_stderr_bytes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := limits.ValidateTool(&tt.tool)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateTool() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateTool() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResolveLimits(t *testing.T) {
+	e := NewExecutor(&ExecutorConfig{Timeout: 30 * time.Second, MaxOutput: 100, TimeoutCeiling: time.Minute, OutputCeiling: 1000})
+	resources := &plugin.ResourceLimits{MaxCPUSeconds: 5}
+
+	tests := []struct {
+		name string
+		opts *ExecuteOptions
+		want execLimits
+	}{
+		{"no options", nil, execLimits{30 * time.Second, 100, 100, nil}},
+		{"tool timeout", &ExecuteOptions{Timeout: 5 * time.Second}, execLimits{5 * time.Second, 100, 100, nil}},
+		{"timeout capped at ceiling", &ExecuteOptions{Timeout: time.Hour}, execLimits{time.Minute, 100, 100, nil}},
+		{"output applies to stderr", &ExecuteOptions{MaxOutput: 500}, execLimits{30 * time.Second, 500, 500, nil}},
+		{"separate stderr limit", &ExecuteOptions{MaxOutput: 500, MaxStderr: 50}, execLimits{30 * time.Second, 500, 50, nil}},
+		{"output capped at ceiling", &ExecuteOptions{MaxOutput: 5000, MaxStderr: 5000}, execLimits{30 * time.Second, 1000, 1000, nil}},
+		{"resource limits", &ExecuteOptions{Resources: resources}, execLimits{30 * time.Second, 100, 100, resources}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := e.resolveLimits(tt.opts); got != tt.want {
+				t.Errorf("resolveLimits() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteWithSandbox_ToolLimits(t *testing.T) {
+	e := NewExecutor(DefaultConfig())
+	tool := &plugin.Tool{Timeout: "200ms", MaxOutputBytes: 4, MaxStderrBytes: 2}
+
+	result, err := e.ExecuteWithSandbox(context.Background(), "/tmp", "sh", []string{"-c", "echo stdout; echo stderr >&2"}, nil, plugin.SandboxTypeNone, "", ToolOptions(tool))
+	if err != nil {
+		t.Fatalf("ExecuteWithSandbox() error = %v", err)
+	}
+	if !strings.HasPrefix(result.Stdout, "stdo\n[output truncated, exceeded 4 bytes]") {
+		t.Errorf("unexpected stdout: %q", result.Stdout)
+	}
+	if !strings.HasPrefix(result.Stderr, "st\n[output truncated, exceeded 2 bytes]") {
+		t.Errorf("unexpected stderr: %q", result.Stderr)
+	}
+
+	start := time.Now()
+	result, err = e.ExecuteWithSandbox(context.Background(), "/tmp", "sleep", []string{"5"}, nil, plugin.SandboxTypeNone, "", ToolOptions(tool))
+	if err != nil {
+		t.Fatalf("ExecuteWithSandbox() error = %v", err)
+	}
+	if !result.TimedOut || !strings.Contains(result.Stderr, "timed out after 200ms") {
+		t.Errorf("expected timeout after 200ms, got %+v", result)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("tool timeout not applied, took %v", elapsed)
+	}
+}
+
+// memoryHogWasm is a module whose _start grows its memory one page at a time
+// until memory.grow fails, then traps
+var memoryHogWasm = []byte{
+	0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00, // magic, version
+	0x01, 0x04, 0x01, 0x60, 0x00, 0x00, // type: func () -> ()
+	0x03, 0x02, 0x01, 0x00, // function 0: type 0
+	0x05, 0x03, 0x01, 0x00, 0x01, // memory: min 1 page, no max
+	0x07, 0x13, 0x02, // exports
+	0x06, 'm', 'e', 'm', 'o', 'r', 'y', 0x02, 0x00,
+	0x06, '_', 's', 't', 'a', 'r', 't', 0x00, 0x00,
+	0x0a, 0x11, 0x01, 0x0f, 0x00, // code: one body, no locals
+	0x03, 0x40, // loop
+	0x41, 0x01, 0x40, 0x00, // memory.grow 1
+	0x41, 0x7f, 0x47, 0x0d, 0x00, // br_if 0 while the result is not -1
+	0x0b,       // end loop
+	0x00, 0x0b, // unreachable, end
+}
+
+func TestWasmMemoryLimit(t *testing.T) {
+	dir := t.TempDir()
+	wasmPath := filepath.Join(dir, "hog.wasm")
+	if err := os.WriteFile(wasmPath, memoryHogWasm, 0644); err != nil {
+		t.Fatalf("write wasm: %v", err)
+	}
+
+	e := NewExecutor(&ExecutorConfig{RootDir: dir})
+	opts := &ExecuteOptions{Resources: &plugin.ResourceLimits{MaxMemoryBytes: 16 * 64 * 1024}}
+	result, err := e.ExecuteWithSandbox(context.Background(), dir, "", nil, nil, plugin.SandboxTypeWasm, wasmPath, opts)
+	if err != nil {
+		t.Fatalf("ExecuteWithSandbox() error = %v", err)
+	}
+	if result.ExitCode == 0 || result.LimitExceeded != LimitMemory {
+		t.Errorf("got exit code %d, LimitExceeded %q; want failure with %q", result.ExitCode, result.LimitExceeded, LimitMemory)
+	}
+}
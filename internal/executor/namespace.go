@@ -0,0 +1,97 @@
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/takeshy/mcp-gatekeeper/internal/plugin"
+)
+
+// namespaceHelperArg is the first argument that makes the binary act as the namespace helper
+const namespaceHelperArg = "__namespace-exec"
+
+// namespaceSystemDirs are the host directories bound read-only into the native sandbox,
+// the same set bwrap binds
+var namespaceSystemDirs = []string{"/usr", "/bin", "/lib", "/lib64", "/etc", "/sbin"}
+
+// namespaceSpec describes the sandbox the namespace helper sets up before executing the command
+type namespaceSpec struct {
+	Root         string         `json:"root"`                    // Host directory that becomes /
+	Cwd          string         `json:"cwd"`                     // Working directory inside the sandbox
+	ReadonlyRoot bool           `json:"readonly_root,omitempty"` // Remount the root read-only
+	Mounts       []sandboxMount `json:"mounts,omitempty"`        // System directories and tool binds
+	Tmpfs        []string       `json:"tmpfs,omitempty"`         // Sandbox paths replaced by an empty tmpfs
+	ShareNet     bool           `json:"share_net,omitempty"`     // The network namespace is the host's
+	Hostname     string         `json:"hostname,omitempty"`      // Hostname inside the sandbox
+	Seccomp      string         `json:"seccomp,omitempty"`       // Seccomp profile to install
+	InputDir     string         `json:"input_dir,omitempty"`     // Host directory copied to a tmpfs at InputFilesDir
+	Overlay      *overlayDirs   `json:"overlay,omitempty"`       // Mount an overlay of the root instead of binding it
+}
+
+// overlayDirs are the host directories of an overlay workspace
+type overlayDirs struct {
+	Upper string `json:"upper"`
+	Work  string `json:"work"`
+}
+
+// wrapWithNamespaces runs the command through the namespace helper, which mounts the
+// same file system view as bwrap and pivots into the root directory
+func (s *Sandbox) wrapWithNamespaces(cwd, cmd string, args []string, cfg *plugin.BwrapConfig, call *CallMounts) (string, []string, error) {
+	if cfg == nil {
+		cfg = &plugin.BwrapConfig{}
+	}
+	if call == nil {
+		call = &CallMounts{}
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to locate the namespace helper: %w", err)
+	}
+
+	var mounts []sandboxMount
+	for _, dir := range namespaceSystemDirs {
+		if _, err := os.Stat(dir); err == nil {
+			mounts = append(mounts, sandboxMount{Src: dir, Dest: dir, ReadOnly: true})
+		}
+	}
+	toolMounts, err := s.toolMounts(cfg)
+	if err != nil {
+		return "", nil, err
+	}
+	// Reject an unsupported seccomp profile here rather than in the helper
+	if cfg.Seccomp != "" {
+		if _, err := compileSeccomp(cfg.Seccomp, hostSeccompArch); err != nil {
+			return "", nil, err
+		}
+	}
+
+	var inputDir string
+	if call.Inputs != nil {
+		inputDir = call.Inputs.hostDir
+	}
+	var overlay *overlayDirs
+	if ws := call.Workspace; ws != nil {
+		overlay = &overlayDirs{Upper: ws.upperDir(), Work: ws.workDir()}
+	}
+
+	spec, err := json.Marshal(namespaceSpec{
+		Root:         s.rootDir,
+		Cwd:          s.toSandboxPath(cwd),
+		ReadonlyRoot: cfg.ReadonlyRoot,
+		Mounts:       append(mounts, toolMounts...),
+		Tmpfs:        cfg.Tmpfs,
+		ShareNet:     cfg.ShareNet,
+		Hostname:     cfg.Hostname,
+		Seccomp:      cfg.Seccomp,
+		InputDir:     inputDir,
+		Overlay:      overlay,
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to encode namespace sandbox: %w", err)
+	}
+
+	// argv: self, helper arg, spec (JSON), command, command argv...
+	return self, append([]string{namespaceHelperArg, string(spec), cmd}, args...), nil
+}
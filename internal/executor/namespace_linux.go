@@ -0,0 +1,453 @@
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/takeshy/mcp-gatekeeper/internal/plugin"
+)
+
+// namespaceDevices are the host device nodes bound into the sandbox's /dev
+var namespaceDevices = []string{"null", "zero", "full", "random", "urandom", "tty"}
+
+var (
+	namespacesOnce  sync.Once
+	namespacesReady bool
+)
+
+// namespacesAvailable reports whether the namespace helper can create user and mount
+// namespaces, which unprivileged users or container runtimes may forbid
+func namespacesAvailable() bool {
+	namespacesOnce.Do(func() {
+		self, err := os.Executable()
+		if err != nil {
+			return
+		}
+		cmd := exec.Command(self, namespaceHelperArg)
+		applyNamespaces(cmd, nil)
+		namespacesReady = cmd.Run() == nil
+	})
+	return namespacesReady
+}
+
+// applyNamespaces makes cmd start in new user, mount, PID, UTS, cgroup and (unless the
+// tool shares it) network namespaces, mapping the current user to root inside.
+// Call it after setProcessGroup.
+func applyNamespaces(cmd *exec.Cmd, cfg *plugin.BwrapConfig) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	flags := uintptr(unix.CLONE_NEWUSER | unix.CLONE_NEWNS | unix.CLONE_NEWPID | unix.CLONE_NEWUTS | unix.CLONE_NEWCGROUP)
+	if cfg == nil || !cfg.ShareNet {
+		flags |= unix.CLONE_NEWNET
+	}
+	cmd.SysProcAttr.Cloneflags = flags
+	cmd.SysProcAttr.UidMappings = []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getuid(), Size: 1}}
+	cmd.SysProcAttr.GidMappings = []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getgid(), Size: 1}}
+}
+
+// RunNamespaceHelper sets up the native namespace sandbox and runs the tool's command under
+// it when the process was started as the namespace helper; otherwise it returns at once.
+// Binaries that execute tools must call it at the start of main.
+func RunNamespaceHelper() {
+	if len(os.Args) < 2 || os.Args[1] != namespaceHelperArg {
+		return
+	}
+	// Capabilities and the seccomp filter are per thread, so set up and fork on one thread
+	runtime.LockOSThread()
+
+	// Without a command, only check that mounts are possible (see namespacesAvailable)
+	if len(os.Args) == 2 {
+		if err := unix.Mount("", "/", "", unix.MS_REC|unix.MS_PRIVATE, ""); err != nil {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	// argv: self, helper arg, spec (JSON), command, command argv...
+	if len(os.Args) < 4 {
+		fmt.Fprintf(os.Stderr, "[ERROR] Invalid namespace helper arguments\n")
+		os.Exit(127)
+	}
+	var spec namespaceSpec
+	if err := json.Unmarshal([]byte(os.Args[2]), &spec); err != nil {
+		fmt.Fprintf(os.Stderr, "[ERROR] Invalid namespace sandbox: %v\n", err)
+		os.Exit(127)
+	}
+	if err := setupNamespace(&spec); err != nil {
+		fmt.Fprintf(os.Stderr, "[ERROR] Failed to set up namespace sandbox: %v\n", err)
+		os.Exit(127)
+	}
+
+	os.Exit(runNamespaceInit(lookPathInSandbox(os.Args[3]), os.Args[3:]))
+}
+
+// namespaceForwardedSignals are passed on from the sandbox's init to the command
+var namespaceForwardedSignals = []os.Signal{unix.SIGTERM, unix.SIGINT, unix.SIGHUP, unix.SIGQUIT, unix.SIGUSR1, unix.SIGUSR2}
+
+// runNamespaceInit starts the command and stays behind as PID 1 of the sandbox, like
+// bubblewrap's init: as PID 1 the command itself would ignore signals it has no handler
+// for and leave orphaned processes unreaped. It forwards signals to the command, reaps
+// every child and returns once the command exits, which makes the kernel kill any process
+// left in the namespace. A command killed by a signal gives 128 plus the signal number.
+func runNamespaceInit(path string, argv []string) int {
+	signals := make(chan os.Signal, len(namespaceForwardedSignals))
+	signal.Notify(signals, namespaceForwardedSignals...)
+
+	proc, err := os.StartProcess(path, argv, &os.ProcAttr{
+		Env:   os.Environ(),
+		Files: []*os.File{os.Stdin, os.Stdout, os.Stderr},
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[ERROR] Failed to execute %s: %v\n", argv[0], err)
+		return 127
+	}
+	go func() {
+		for sig := range signals {
+			proc.Signal(sig)
+		}
+	}()
+
+	for {
+		var status unix.WaitStatus
+		pid, err := unix.Wait4(-1, &status, 0, nil)
+		if err == unix.EINTR {
+			continue
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[ERROR] Failed to wait for %s: %v\n", argv[0], err)
+			return 127
+		}
+		if pid != proc.Pid {
+			continue // An orphan reparented to the sandbox's init
+		}
+		if status.Signaled() {
+			return 128 + int(status.Signal())
+		}
+		return status.ExitStatus()
+	}
+}
+
+// setupNamespace mounts the sandbox under spec.Root, pivots into it and drops privileges
+func setupNamespace(spec *namespaceSpec) error {
+	root, err := filepath.EvalSymlinks(spec.Root)
+	if err != nil {
+		return fmt.Errorf("root directory: %w", err)
+	}
+
+	// Keep the mounts below from propagating back to the host
+	if err := unix.Mount("", "/", "", unix.MS_REC|unix.MS_PRIVATE, ""); err != nil {
+		return fmt.Errorf("make mounts private: %w", err)
+	}
+	if spec.Overlay != nil {
+		if err := mountOverlay(root, spec.Overlay); err != nil {
+			return err
+		}
+	} else if err := unix.Mount(root, root, "", unix.MS_BIND|unix.MS_REC, ""); err != nil {
+		return fmt.Errorf("bind root directory: %w", err)
+	}
+	for _, m := range spec.Mounts {
+		if err := bindMount(root, m); err != nil {
+			return err
+		}
+	}
+	if err := mountDev(root); err != nil {
+		return err
+	}
+	for _, p := range append([]string{"/tmp"}, spec.Tmpfs...) {
+		if err := mountTmpfs(root, p); err != nil {
+			return err
+		}
+	}
+	if spec.InputDir != "" {
+		if err := copyInputFiles(root, spec.InputDir); err != nil {
+			return fmt.Errorf("input files: %w", err)
+		}
+	}
+	if spec.ReadonlyRoot {
+		if err := remountReadOnly(root); err != nil {
+			return fmt.Errorf("remount root read-only: %w", err)
+		}
+	}
+
+	if spec.Hostname != "" {
+		if err := unix.Sethostname([]byte(spec.Hostname)); err != nil {
+			return fmt.Errorf("set hostname: %w", err)
+		}
+	}
+	if !spec.ShareNet {
+		if err := loopbackUp(); err != nil {
+			return fmt.Errorf("bring up loopback: %w", err)
+		}
+	}
+
+	// pivot_root(".", ".") stacks the old root on top of the new one; detaching it leaves only the sandbox
+	if err := unix.Chdir(root); err != nil {
+		return err
+	}
+	if err := unix.PivotRoot(".", "."); err != nil {
+		return fmt.Errorf("pivot_root: %w", err)
+	}
+	if err := unix.Unmount(".", unix.MNT_DETACH); err != nil {
+		return fmt.Errorf("detach old root: %w", err)
+	}
+	if err := unix.Chdir(spec.Cwd); err != nil {
+		return fmt.Errorf("chdir %s: %w", spec.Cwd, err)
+	}
+
+	if err := dropCapabilities(); err != nil {
+		return fmt.Errorf("drop capabilities: %w", err)
+	}
+	if spec.Seccomp != "" {
+		if err := installSeccomp(spec.Seccomp); err != nil {
+			return fmt.Errorf("seccomp: %w", err)
+		}
+	}
+	return nil
+}
+
+// mountTarget creates the mount point of a sandbox path under root and returns its host
+// path, refusing symlinks that lead outside root
+func mountTarget(root, dest string, dir bool) (string, error) {
+	target := filepath.Join(root, dest)
+	if dir {
+		if err := os.MkdirAll(target, 0755); err != nil {
+			return "", fmt.Errorf("create mount point %s: %w", dest, err)
+		}
+	} else {
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return "", fmt.Errorf("create mount point %s: %w", dest, err)
+		}
+		f, err := os.OpenFile(target, os.O_CREATE|os.O_RDONLY, 0644)
+		if err != nil {
+			return "", fmt.Errorf("create mount point %s: %w", dest, err)
+		}
+		f.Close()
+	}
+
+	real, err := filepath.EvalSymlinks(target)
+	if err != nil {
+		return "", fmt.Errorf("mount point %s: %w", dest, err)
+	}
+	if !IsPathWithinRoot(root, real) {
+		return "", fmt.Errorf("mount point %s is outside the root directory", dest)
+	}
+	return real, nil
+}
+
+// bindMount binds a host path into the sandbox
+func bindMount(root string, m sandboxMount) error {
+	info, err := os.Stat(m.Src)
+	if err != nil {
+		return fmt.Errorf("bind %s: %w", m.Src, err)
+	}
+	target, err := mountTarget(root, m.Dest, info.IsDir())
+	if err != nil {
+		return err
+	}
+	if err := unix.Mount(m.Src, target, "", unix.MS_BIND|unix.MS_REC, ""); err != nil {
+		return fmt.Errorf("bind %s to %s: %w", m.Src, m.Dest, err)
+	}
+	if m.ReadOnly {
+		if err := remountReadOnly(target); err != nil {
+			return fmt.Errorf("remount %s read-only: %w", m.Dest, err)
+		}
+	}
+	return nil
+}
+
+// remountReadOnly makes a bind mount read-only, keeping the flags the kernel locks
+// for mounts inherited from the host
+func remountReadOnly(target string) error {
+	var st unix.Statfs_t
+	if err := unix.Statfs(target, &st); err != nil {
+		return err
+	}
+	flags := uintptr(unix.MS_BIND | unix.MS_REMOUNT | unix.MS_RDONLY)
+	for stFlag, msFlag := range map[int64]uintptr{
+		unix.ST_NOSUID:     unix.MS_NOSUID,
+		unix.ST_NODEV:      unix.MS_NODEV,
+		unix.ST_NOEXEC:     unix.MS_NOEXEC,
+		unix.ST_NOATIME:    unix.MS_NOATIME,
+		unix.ST_NODIRATIME: unix.MS_NODIRATIME,
+		unix.ST_RELATIME:   unix.MS_RELATIME,
+	} {
+		if int64(st.Flags)&stFlag != 0 {
+			flags |= msFlag
+		}
+	}
+	return unix.Mount("", target, "", flags, "")
+}
+
+// mountOverlay mounts an overlay of the root directory over itself, so the command's
+// writes go to the upper directory
+func mountOverlay(root string, dirs *overlayDirs) error {
+	for _, dir := range []string{root, dirs.Upper, dirs.Work} {
+		if strings.ContainsAny(dir, ",:") {
+			return fmt.Errorf("overlay directory %q contains ',' or ':'", dir)
+		}
+	}
+	opts := fmt.Sprintf("lowerdir=%s,upperdir=%s,workdir=%s,userxattr", root, dirs.Upper, dirs.Work)
+	if err := unix.Mount("overlay", root, "overlay", 0, opts); err != nil {
+		return fmt.Errorf("overlay root directory: %w", err)
+	}
+	return nil
+}
+
+// mountTmpfs replaces a sandbox path with an empty tmpfs
+func mountTmpfs(root, dest string) error {
+	target, err := mountTarget(root, dest, true)
+	if err != nil {
+		return err
+	}
+	if err := unix.Mount("tmpfs", target, "tmpfs", unix.MS_NOSUID|unix.MS_NODEV, "mode=0755"); err != nil {
+		return fmt.Errorf("tmpfs %s: %w", dest, err)
+	}
+	return nil
+}
+
+// copyInputFiles mounts a tmpfs at InputFilesDir and copies the host input directory into it
+func copyInputFiles(root, src string) error {
+	if err := mountTmpfs(root, InputFilesDir); err != nil {
+		return err
+	}
+	dst := filepath.Join(root, InputFilesDir)
+	return filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return os.MkdirAll(filepath.Join(dst, rel), 0755)
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dst, rel), data, 0644)
+	})
+}
+
+// mountDev creates a minimal /dev with the host's basic device nodes, like bwrap --dev
+func mountDev(root string) error {
+	dev, err := mountTarget(root, "/dev", true)
+	if err != nil {
+		return err
+	}
+	if err := unix.Mount("tmpfs", dev, "tmpfs", unix.MS_NOSUID|unix.MS_NOEXEC, "mode=0755"); err != nil {
+		return fmt.Errorf("tmpfs /dev: %w", err)
+	}
+	for _, name := range namespaceDevices {
+		src := "/dev/" + name
+		if _, err := os.Stat(src); err != nil {
+			continue
+		}
+		if err := bindMount(root, sandboxMount{Src: src, Dest: src}); err != nil {
+			return err
+		}
+	}
+	links := map[string]string{
+		"fd":     "/proc/self/fd",
+		"stdin":  "/proc/self/fd/0",
+		"stdout": "/proc/self/fd/1",
+		"stderr": "/proc/self/fd/2",
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, filepath.Join(dev, name)); err != nil {
+			return fmt.Errorf("/dev/%s: %w", name, err)
+		}
+	}
+	return os.Mkdir(filepath.Join(dev, "shm"), 0755)
+}
+
+// loopbackUp brings up the loopback interface of a new network namespace
+func loopbackUp() error {
+	fd, err := unix.Socket(unix.AF_INET, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return err
+	}
+	defer unix.Close(fd)
+
+	ifr, err := unix.NewIfreq("lo")
+	if err != nil {
+		return err
+	}
+	if err := unix.IoctlIfreq(fd, unix.SIOCGIFFLAGS, ifr); err != nil {
+		return err
+	}
+	ifr.SetUint16(ifr.Uint16() | unix.IFF_UP)
+	return unix.IoctlIfreq(fd, unix.SIOCSIFFLAGS, ifr)
+}
+
+// dropCapabilities clears every capability of the calling thread, including the
+// bounding set, so the command does not regain them as root inside the namespace
+func dropCapabilities() error {
+	for c := 0; ; c++ {
+		if err := unix.Prctl(unix.PR_CAPBSET_DROP, uintptr(c), 0, 0, 0); err != nil {
+			if err == unix.EINVAL {
+				break
+			}
+			return err
+		}
+	}
+	if err := unix.Prctl(unix.PR_CAP_AMBIENT, unix.PR_CAP_AMBIENT_CLEAR_ALL, 0, 0, 0); err != nil && err != unix.EINVAL {
+		return err
+	}
+	hdr := unix.CapUserHeader{Version: unix.LINUX_CAPABILITY_VERSION_3}
+	var data [2]unix.CapUserData
+	return unix.Capset(&hdr, &data[0])
+}
+
+// installSeccomp installs a seccomp profile on every thread of the current process
+func installSeccomp(profile string) error {
+	prog, err := compileSeccomp(profile, hostSeccompArch)
+	if err != nil {
+		return err
+	}
+	filters := make([]unix.SockFilter, len(prog))
+	for i, ins := range prog {
+		filters[i] = unix.SockFilter{Code: ins.Code, Jt: ins.Jt, Jf: ins.Jf, K: ins.K}
+	}
+	fprog := unix.SockFprog{Len: uint16(len(filters)), Filter: &filters[0]}
+	if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+		return err
+	}
+	if _, _, errno := unix.Syscall(unix.SYS_SECCOMP, unix.SECCOMP_SET_MODE_FILTER, unix.SECCOMP_FILTER_FLAG_TSYNC, uintptr(unsafe.Pointer(&fprog))); errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// lookPathInSandbox resolves a bare command name against PATH inside the sandbox,
+// defaulting to the standard directories like execvp
+func lookPathInSandbox(name string) string {
+	if strings.Contains(name, "/") {
+		return name
+	}
+	dirs := os.Getenv("PATH")
+	if dirs == "" {
+		dirs = "/usr/local/bin:/usr/bin:/bin"
+	}
+	for _, dir := range filepath.SplitList(dirs) {
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() && info.Mode()&0111 != 0 {
+			return path
+		}
+	}
+	return name
+}
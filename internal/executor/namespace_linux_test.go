@@ -0,0 +1,214 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/takeshy/mcp-gatekeeper/internal/plugin"
+)
+
+func TestSandbox_WrapWithNamespaces(t *testing.T) {
+	rootDir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(rootDir, "work"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	s := &Sandbox{mode: SandboxNamespace, rootDir: rootDir}
+
+	cfg := &plugin.BwrapConfig{RWBinds: []plugin.BindMount{{Src: "work", Dest: "/data"}}, ShareNet: true, Hostname: "sandbox"}
+	cmd, args, err := s.WrapCommand(filepath.Join(rootDir, "work"), "ls", []string{"-l"}, cfg, nil)
+	if err != nil {
+		t.Fatalf("WrapCommand() error = %v", err)
+	}
+	self, _ := os.Executable()
+	if cmd != self || len(args) != 4 || args[0] != namespaceHelperArg || args[2] != "ls" || args[3] != "-l" {
+		t.Fatalf("expected the namespace helper, got %s %v", cmd, args)
+	}
+
+	var spec namespaceSpec
+	if err := json.Unmarshal([]byte(args[1]), &spec); err != nil {
+		t.Fatal(err)
+	}
+	if spec.Root != rootDir || spec.Cwd != "/work" || !spec.ShareNet || spec.Hostname != "sandbox" {
+		t.Errorf("unexpected spec %+v", spec)
+	}
+	if len(spec.Mounts) == 0 || spec.Mounts[0] != (sandboxMount{Src: "/usr", Dest: "/usr", ReadOnly: true}) {
+		t.Errorf("expected /usr bound read-only first, got %+v", spec.Mounts)
+	}
+	realWork, _ := filepath.EvalSymlinks(filepath.Join(rootDir, "work"))
+	if last := spec.Mounts[len(spec.Mounts)-1]; last != (sandboxMount{Src: realWork, Dest: "/data"}) {
+		t.Errorf("expected the tool bind last, got %+v", last)
+	}
+
+	if _, _, err := s.WrapCommand("/", "ls", nil, nil, nil); err == nil {
+		t.Error("expected an error for cwd outside the root directory")
+	}
+}
+
+func TestExecute_NamespaceSandbox(t *testing.T) {
+	if !namespacesAvailable() {
+		t.Skip("Linux namespaces are not available")
+	}
+
+	rootDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(rootDir, "hello.txt"), []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(rootDir, "out"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	outside := t.TempDir()
+	s := &Sandbox{mode: SandboxNamespace, rootDir: rootDir, bindPaths: []string{outside}}
+	e := &Executor{config: DefaultConfig(), sandbox: s, queue: newExecQueue(DefaultMaxConcurrency, DefaultMaxQueue, DefaultQueueTimeout)}
+
+	run := func(t *testing.T, cfg *plugin.BwrapConfig, script string) *ExecuteResult {
+		t.Helper()
+		result, err := e.executeWithBwrap(context.Background(), rootDir, "sh", []string{"-c", script}, nil, &ExecuteOptions{Bwrap: cfg})
+		if err != nil {
+			t.Fatalf("executeWithBwrap() error = %v", err)
+		}
+		return result
+	}
+
+	t.Run("default profile", func(t *testing.T) {
+		result := run(t, nil, "cat /hello.txt; pwd; test -e "+outside+" && echo visible; echo x > /out/file; mount -t tmpfs none /tmp 2>/dev/null && echo mounted; echo done")
+		if result.ExitCode != 0 {
+			t.Fatalf("exit code = %d, stderr = %s", result.ExitCode, result.Stderr)
+		}
+		if result.Stdout != "hello/\ndone\n" {
+			t.Errorf("stdout = %q, want the root file, cwd / and no host paths or mounts", result.Stdout)
+		}
+		if data, err := os.ReadFile(filepath.Join(rootDir, "out", "file")); err != nil || string(data) != "x\n" {
+			t.Errorf("expected the write to reach the root directory, got %q, %v", data, err)
+		}
+	})
+
+	t.Run("stdin", func(t *testing.T) {
+		result, err := e.executeWithBwrap(context.Background(), rootDir, "cat", nil, nil, &ExecuteOptions{Stdin: strings.NewReader("from stdin")})
+		if err != nil {
+			t.Fatalf("executeWithBwrap() error = %v", err)
+		}
+		if result.ExitCode != 0 || result.Stdout != "from stdin" {
+			t.Errorf("got %q (exit code %d), want the input echoed", result.Stdout, result.ExitCode)
+		}
+	})
+
+	t.Run("input files", func(t *testing.T) {
+		inputs, err := NewInputFiles(fileTool(plugin.SandboxTypeBubblewrap))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer inputs.Close()
+		p, err := inputs.Add("f", plugin.InputFile{Name: "in.txt", Data: []byte("uploaded")})
+		if err != nil {
+			t.Fatal(err)
+		}
+		opts := &ExecuteOptions{Bwrap: &plugin.BwrapConfig{ReadonlyRoot: true}, InputFiles: inputs}
+		result, err := e.executeWithBwrap(context.Background(), rootDir, "cat", []string{p}, nil, opts)
+		if err != nil {
+			t.Fatalf("executeWithBwrap() error = %v", err)
+		}
+		if result.ExitCode != 0 || result.Stdout != "uploaded" {
+			t.Errorf("got %q (exit code %d, stderr %s), want the file content", result.Stdout, result.ExitCode, result.Stderr)
+		}
+	})
+
+	t.Run("overlay workspace", func(t *testing.T) {
+		for name, content := range map[string]string{"keep.txt": "keep\n", "gone.txt": "gone\n"} {
+			if err := os.WriteFile(filepath.Join(rootDir, name), []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		script := "echo more >> /keep.txt; rm /gone.txt; mkdir /made; echo new > /made/new.txt; cat /keep.txt"
+		cfg := &plugin.BwrapConfig{Tmpfs: []string{"/cache/tmp"}}
+		want := []WorkspaceChange{
+			{Path: "/gone.txt", Kind: WorkspaceDeleted},
+			{Path: "/keep.txt", Kind: WorkspaceModified},
+			{Path: "/made", Kind: WorkspaceAdded},
+			{Path: "/made/new.txt", Kind: WorkspaceAdded},
+		}
+
+		result, err := e.executeWithBwrap(context.Background(), rootDir, "sh", []string{"-c", script}, nil, &ExecuteOptions{Bwrap: cfg, Workspace: plugin.WorkspaceOverlay})
+		if err != nil {
+			t.Fatalf("executeWithBwrap() error = %v", err)
+		}
+		if result.ExitCode != 0 || result.Stdout != "keep\nmore\n" {
+			t.Fatalf("got %q (exit code %d, stderr %s)", result.Stdout, result.ExitCode, result.Stderr)
+		}
+		if result.Workspace == nil || result.Workspace.Committed || !reflect.DeepEqual(result.Workspace.Changes, want) {
+			t.Errorf("workspace = %+v, want the changes %v uncommitted", result.Workspace, want)
+		}
+		if data, _ := os.ReadFile(filepath.Join(rootDir, "keep.txt")); string(data) != "keep\n" {
+			t.Errorf("expected the root directory untouched, keep.txt = %q", data)
+		}
+		for _, p := range []string{"gone.txt", "made", "cache"} {
+			if _, err := os.Lstat(filepath.Join(rootDir, p)); (p == "gone.txt") != (err == nil) {
+				t.Errorf("expected the root directory untouched, stat %s: %v", p, err)
+			}
+		}
+
+		opts := &ExecuteOptions{Bwrap: cfg, Workspace: plugin.WorkspaceOverlay, CommitWorkspace: true}
+		result, err = e.executeWithBwrap(context.Background(), rootDir, "sh", []string{"-c", script}, nil, opts)
+		if err != nil {
+			t.Fatalf("executeWithBwrap() error = %v", err)
+		}
+		if result.Workspace == nil || !result.Workspace.Committed || !reflect.DeepEqual(result.Workspace.Changes, want) {
+			t.Fatalf("workspace = %+v, want the changes %v committed", result.Workspace, want)
+		}
+		if data, _ := os.ReadFile(filepath.Join(rootDir, "keep.txt")); string(data) != "keep\nmore\n" {
+			t.Errorf("keep.txt = %q, want the committed change", data)
+		}
+		if data, _ := os.ReadFile(filepath.Join(rootDir, "made", "new.txt")); string(data) != "new\n" {
+			t.Errorf("made/new.txt = %q, want the committed file", data)
+		}
+		if _, err := os.Stat(filepath.Join(rootDir, "gone.txt")); !os.IsNotExist(err) {
+			t.Errorf("expected gone.txt deleted, got %v", err)
+		}
+
+		result, err = e.executeWithBwrap(context.Background(), rootDir, "sh", []string{"-c", "echo x > /keep.txt; exit 3"}, nil, opts)
+		if err != nil {
+			t.Fatalf("executeWithBwrap() error = %v", err)
+		}
+		if result.Workspace == nil || result.Workspace.Committed || len(result.Workspace.Changes) != 1 {
+			t.Errorf("workspace = %+v, want one change left uncommitted after a failure", result.Workspace)
+		}
+	})
+
+	t.Run("init process", func(t *testing.T) {
+		// The command is not PID 1, so SIGTERM without a handler terminates it
+		result := run(t, &plugin.BwrapConfig{Seccomp: plugin.SeccompDefaultDenyDangerous}, "echo $$; (sleep 0.1 &); kill -TERM $$; echo survived")
+		if result.ExitCode != 128+15 {
+			t.Errorf("exit code = %d, want %d (stderr %q)", result.ExitCode, 128+15, result.Stderr)
+		}
+		if result.Stdout == "" || result.Stdout == "1\n" || strings.Contains(result.Stdout, "survived") {
+			t.Errorf("expected the command to run as a child of init, got stdout %q", result.Stdout)
+		}
+	})
+
+	t.Run("custom profile", func(t *testing.T) {
+		cfg := &plugin.BwrapConfig{
+			ROBinds:      []plugin.BindMount{{Src: outside, Dest: "/data"}},
+			Tmpfs:        []string{"/out"},
+			ReadonlyRoot: true,
+			Hostname:     "sandbox",
+			Seccomp:      plugin.SeccompDefaultDenyDangerous,
+		}
+		if err := os.WriteFile(filepath.Join(outside, "input"), []byte("data"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		result := run(t, cfg, "cat /data/input; uname -n; ls /out; touch /new 2>/dev/null || echo readonly; touch /data/x 2>/dev/null || echo readonly")
+		if result.ExitCode != 0 {
+			t.Fatalf("exit code = %d, stderr = %s", result.ExitCode, result.Stderr)
+		}
+		if !strings.HasPrefix(result.Stdout, "data") || !strings.Contains(result.Stdout, "sandbox\nreadonly\nreadonly\n") {
+			t.Errorf("unexpected stdout %q", result.Stdout)
+		}
+		if _, err := os.Stat(filepath.Join(rootDir, "new")); !os.IsNotExist(err) {
+			t.Errorf("expected the read-only root to reject writes, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,20 @@
+//go:build !linux
+
+package executor
+
+import (
+	"os/exec"
+
+	"github.com/takeshy/mcp-gatekeeper/internal/plugin"
+)
+
+// namespacesAvailable reports false; the native namespace sandbox needs Linux
+func namespacesAvailable() bool {
+	return false
+}
+
+// applyNamespaces does nothing; the native namespace sandbox needs Linux
+func applyNamespaces(cmd *exec.Cmd, cfg *plugin.BwrapConfig) {}
+
+// RunNamespaceHelper does nothing; the native namespace sandbox needs Linux
+func RunNamespaceHelper() {}
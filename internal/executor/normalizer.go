@@ -1,6 +1,7 @@
 package executor
 
 import (
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -30,7 +31,7 @@ func (n *Normalizer) Normalize(cwd, cmd string, args []string) (*NormalizedComma
 	}
 
 	// Normalize CWD using realpath
-	normalizedCwd, err := n.normalizePath(cwd)
+	normalizedCwd, err := n.NormalizePath(cwd)
 	if err != nil {
 		// If cwd doesn't exist, use as-is but note it
 		result.Cwd = cwd
@@ -47,25 +48,60 @@ func (n *Normalizer) Normalize(cwd, cmd string, args []string) (*NormalizedComma
 	return result, nil
 }
 
-// normalizePath resolves a path to its real path (following symlinks)
-func (n *Normalizer) normalizePath(path string) (string, error) {
-	// First make it absolute
-	absPath, err := filepath.Abs(path)
-	if err != nil {
+// NormalizePath resolves a path to its real path (following symlinks).
+// For a path that does not exist yet, the existing part is resolved so that a
+// symlinked parent directory cannot hide the real location. ".." is applied
+// after resolving the preceding symlinks, as the kernel does.
+func (n *Normalizer) NormalizePath(path string) (string, error) {
+	// Make it absolute without cleaning, so ".." is not applied lexically
+	if !filepath.IsAbs(path) {
+		wd, err := os.Getwd()
+		if err != nil {
+			return "", err
+		}
+		path = wd + string(filepath.Separator) + path
+	}
+
+	// Fast path: the whole path exists
+	if realPath, err := filepath.EvalSymlinks(path); err == nil {
+		return realPath, nil
+	} else if !os.IsNotExist(err) {
 		return "", err
 	}
 
-	// Then resolve symlinks
-	realPath, err := filepath.EvalSymlinks(absPath)
-	if err != nil {
-		// If path doesn't exist, return absolute path
-		if os.IsNotExist(err) {
-			return absPath, nil
+	// Walk the components, resolving symlinks until the first missing component
+	volume := filepath.VolumeName(path)
+	current := volume + string(filepath.Separator)
+	elems := strings.Split(filepath.ToSlash(path[len(volume):]), "/")
+	for i, elem := range elems {
+		switch elem {
+		case "", ".":
+			continue
+		case "..":
+			current = filepath.Dir(current)
+			continue
 		}
-		return "", err
+
+		next := filepath.Join(current, elem)
+		if _, err := os.Lstat(next); err != nil {
+			if !os.IsNotExist(err) {
+				return "", err
+			}
+			// The rest does not exist and cannot traverse symlinks
+			return filepath.Join(next, filepath.Join(elems[i+1:]...)), nil
+		}
+
+		realNext, err := filepath.EvalSymlinks(next)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return "", fmt.Errorf("cannot resolve %q: dangling symlink", next)
+			}
+			return "", err
+		}
+		current = realNext
 	}
 
-	return realPath, nil
+	return current, nil
 }
 
 // normalizeCommand resolves a command to its full path if possible
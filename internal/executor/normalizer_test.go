@@ -138,6 +138,83 @@ func TestNormalizer_normalizeCommand(t *testing.T) {
 	}
 }
 
+func TestNormalizer_NormalizePath(t *testing.T) {
+	n := NewNormalizer()
+
+	tmpDir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	outside, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(tmpDir, "src"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(tmpDir, "escape")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(outside, "missing"), filepath.Join(tmpDir, "dangling")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "existing directory",
+			path: filepath.Join(tmpDir, "src"),
+			want: filepath.Join(tmpDir, "src"),
+		},
+		{
+			name: "missing file under existing directory",
+			path: filepath.Join(tmpDir, "src", "new", "file.txt"),
+			want: filepath.Join(tmpDir, "src", "new", "file.txt"),
+		},
+		{
+			name: "missing file under symlinked directory",
+			path: filepath.Join(tmpDir, "escape", "new.txt"),
+			want: filepath.Join(outside, "new.txt"),
+		},
+		{
+			name: "dot-dot after symlink follows the link target",
+			path: tmpDir + "/escape/../" + filepath.Base(tmpDir),
+			want: filepath.Join(filepath.Dir(outside), filepath.Base(tmpDir)),
+		},
+		{
+			name:    "dangling symlink",
+			path:    filepath.Join(tmpDir, "dangling"),
+			wantErr: true,
+		},
+		{
+			name: "missing path with dot-dot after symlink",
+			path: tmpDir + "/escape/../missing.txt",
+			want: filepath.Join(filepath.Dir(outside), "missing.txt"),
+		},
+		{
+			name: "relative dot-dot within missing part",
+			path: tmpDir + "/src/new/../other.txt",
+			want: filepath.Join(tmpDir, "src", "other.txt"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := n.NormalizePath(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NormalizePath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("NormalizePath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > 0 && containsString(s, substr))
 }
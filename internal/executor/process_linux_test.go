@@ -0,0 +1,72 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/takeshy/mcp-gatekeeper/internal/plugin"
+)
+
+func TestExecute_CancelKillsProcessGroup(t *testing.T) {
+	e := NewExecutor(DefaultConfig())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The shell prints the PID of a background child, then waits for it
+	pidCh := make(chan int, 1)
+	opts := &ExecuteOptions{OnOutput: func(stream string, chunk []byte) {
+		if pid, err := strconv.Atoi(strings.TrimSpace(string(chunk))); err == nil {
+			pidCh <- pid
+		}
+	}}
+
+	resultCh := make(chan *ExecuteResult, 1)
+	go func() {
+		result, err := e.ExecuteWithSandbox(ctx, "/tmp", "sh", []string{"-c", "sleep 30 & echo $!; wait"}, nil, plugin.SandboxTypeNone, "", opts)
+		if err != nil {
+			t.Errorf("ExecuteWithSandbox() error = %v", err)
+		}
+		resultCh <- result
+	}()
+
+	var childPID int
+	select {
+	case childPID = <-pidCh:
+	case <-time.After(5 * time.Second):
+		t.Fatal("child process did not start")
+	}
+	cancel()
+
+	var result *ExecuteResult
+	select {
+	case result = <-resultCh:
+	case <-time.After(5 * time.Second):
+		t.Fatal("execution did not return after cancellation")
+	}
+	if result == nil || !result.Cancelled || result.ExitCode != -1 {
+		t.Fatalf("expected cancelled result, got %+v", result)
+	}
+	if !strings.Contains(result.Stderr, "[execution cancelled]") {
+		t.Errorf("expected cancellation notice in stderr, got %q", result.Stderr)
+	}
+
+	// The background child must be gone (or a zombie awaiting reaping)
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		stat, err := os.ReadFile("/proc/" + strconv.Itoa(childPID) + "/stat")
+		if err != nil {
+			return
+		}
+		if fields := strings.Fields(string(stat)); len(fields) > 2 && fields[2] == "Z" {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("child process %d still running after cancellation", childPID)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
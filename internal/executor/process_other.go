@@ -0,0 +1,11 @@
+//go:build !unix
+
+package executor
+
+import "os/exec"
+
+// setProcessGroup only bounds how long Wait blocks after the process is killed;
+// process groups are not available on this platform
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.WaitDelay = processWaitDelay
+}
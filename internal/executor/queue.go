@@ -0,0 +1,157 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrQueueFull is returned when all execution slots are busy and the wait queue is full
+	ErrQueueFull = errors.New("execution queue is full")
+	// ErrQueueTimeout is returned when a queued execution does not get a slot in time
+	ErrQueueTimeout = errors.New("timed out waiting for an execution slot")
+)
+
+// QueueStats reports the executor's concurrency state
+type QueueStats struct {
+	Running        int `json:"running"`
+	Waiting        int `json:"waiting"`
+	MaxConcurrency int `json:"max_concurrency"` // 0 = unlimited
+	MaxQueue       int `json:"max_queue"`
+}
+
+// execQueue bounds concurrent executions globally and per tool, with a bounded wait queue
+type execQueue struct {
+	global   chan struct{} // nil = unlimited
+	maxQueue int
+	timeout  time.Duration // 0 = wait until the context is done
+
+	mu      sync.Mutex
+	tools   map[string]chan struct{} // Per-tool slots by tool name
+	running int
+	waiting int
+}
+
+func newExecQueue(maxConcurrency, maxQueue int, timeout time.Duration) *execQueue {
+	q := &execQueue{
+		maxQueue: maxQueue,
+		timeout:  timeout,
+		tools:    make(map[string]chan struct{}),
+	}
+	if maxConcurrency > 0 {
+		q.global = make(chan struct{}, maxConcurrency)
+	}
+	return q
+}
+
+// toolSlots returns the slot channel for a tool, replacing it if its limit changed
+func (q *execQueue) toolSlots(tool string, maxConcurrency int) chan struct{} {
+	if tool == "" || maxConcurrency <= 0 {
+		return nil
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	slots, ok := q.tools[tool]
+	if !ok || cap(slots) != maxConcurrency {
+		slots = make(chan struct{}, maxConcurrency)
+		q.tools[tool] = slots
+	}
+	return slots
+}
+
+// acquire waits for a tool slot and then a global slot. The returned release func
+// must be called when the execution finishes.
+func (q *execQueue) acquire(ctx context.Context, tool string, toolMaxConcurrency int) (func(), error) {
+	// Take a slot from each semaphore in order; the tool slot comes first so a
+	// request waiting on a busy tool does not hold a global slot
+	var held []chan struct{}
+	release := func() {
+		for _, slots := range held {
+			<-slots
+		}
+	}
+	pending := []chan struct{}{}
+	for _, slots := range []chan struct{}{q.toolSlots(tool, toolMaxConcurrency), q.global} {
+		if slots != nil {
+			pending = append(pending, slots)
+		}
+	}
+
+	// Fast path: every slot is free
+	for len(pending) > 0 {
+		select {
+		case pending[0] <- struct{}{}:
+			held = append(held, pending[0])
+			pending = pending[1:]
+			continue
+		default:
+		}
+		break
+	}
+
+	if len(pending) > 0 {
+		if err := q.wait(ctx, pending, &held); err != nil {
+			release()
+			return nil, err
+		}
+	}
+
+	q.mu.Lock()
+	q.running++
+	q.mu.Unlock()
+	return func() {
+		q.mu.Lock()
+		q.running--
+		q.mu.Unlock()
+		release()
+	}, nil
+}
+
+// wait queues the caller until the pending slots are acquired
+func (q *execQueue) wait(ctx context.Context, pending []chan struct{}, held *[]chan struct{}) error {
+	q.mu.Lock()
+	if q.waiting >= q.maxQueue {
+		q.mu.Unlock()
+		return ErrQueueFull
+	}
+	q.waiting++
+	q.mu.Unlock()
+	defer func() {
+		q.mu.Lock()
+		q.waiting--
+		q.mu.Unlock()
+	}()
+
+	var timeout <-chan time.Time
+	if q.timeout > 0 {
+		timer := time.NewTimer(q.timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	for _, slots := range pending {
+		select {
+		case slots <- struct{}{}:
+			*held = append(*held, slots)
+		case <-timeout:
+			return ErrQueueTimeout
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// stats returns the current queue state
+func (q *execQueue) stats() QueueStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return QueueStats{
+		Running:        q.running,
+		Waiting:        q.waiting,
+		MaxConcurrency: cap(q.global),
+		MaxQueue:       q.maxQueue,
+	}
+}
@@ -0,0 +1,187 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/takeshy/mcp-gatekeeper/internal/plugin"
+)
+
+func TestExecQueue_Unlimited(t *testing.T) {
+	q := newExecQueue(0, 0, 0)
+
+	var releases []func()
+	for i := 0; i < 10; i++ {
+		release, err := q.acquire(context.Background(), "tool", 0)
+		if err != nil {
+			t.Fatalf("acquire() error = %v", err)
+		}
+		releases = append(releases, release)
+	}
+	if stats := q.stats(); stats.Running != 10 || stats.Waiting != 0 {
+		t.Errorf("stats() = %+v, want 10 running", stats)
+	}
+	for _, release := range releases {
+		release()
+	}
+	if stats := q.stats(); stats.Running != 0 {
+		t.Errorf("stats() = %+v, want 0 running after release", stats)
+	}
+}
+
+func TestExecQueue_QueueFull(t *testing.T) {
+	q := newExecQueue(1, 0, 0)
+
+	release, err := q.acquire(context.Background(), "", 0)
+	if err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+	if _, err := q.acquire(context.Background(), "", 0); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("acquire() error = %v, want ErrQueueFull", err)
+	}
+	release()
+
+	release, err = q.acquire(context.Background(), "", 0)
+	if err != nil {
+		t.Fatalf("acquire() after release error = %v", err)
+	}
+	release()
+}
+
+func TestExecQueue_QueueTimeout(t *testing.T) {
+	q := newExecQueue(1, 1, 50*time.Millisecond)
+
+	release, err := q.acquire(context.Background(), "", 0)
+	if err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+	defer release()
+
+	if _, err := q.acquire(context.Background(), "", 0); !errors.Is(err, ErrQueueTimeout) {
+		t.Errorf("acquire() error = %v, want ErrQueueTimeout", err)
+	}
+	if stats := q.stats(); stats.Waiting != 0 {
+		t.Errorf("stats().Waiting = %d, want 0 after timeout", stats.Waiting)
+	}
+}
+
+func TestExecQueue_WaitsForSlot(t *testing.T) {
+	q := newExecQueue(1, 1, 0)
+
+	release, err := q.acquire(context.Background(), "", 0)
+	if err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+
+	acquired := make(chan error, 1)
+	go func() {
+		release, err := q.acquire(context.Background(), "", 0)
+		if err == nil {
+			release()
+		}
+		acquired <- err
+	}()
+
+	// Wait until the second caller is queued
+	deadline := time.Now().Add(5 * time.Second)
+	for q.stats().Waiting != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("second caller was not queued")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// The queue holds one waiter, so a third caller is rejected
+	if _, err := q.acquire(context.Background(), "", 0); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("acquire() error = %v, want ErrQueueFull", err)
+	}
+
+	release()
+	select {
+	case err := <-acquired:
+		if err != nil {
+			t.Errorf("queued acquire() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("queued caller did not get a slot")
+	}
+}
+
+func TestExecQueue_ContextCancelled(t *testing.T) {
+	q := newExecQueue(1, 1, 0)
+
+	release, err := q.acquire(context.Background(), "", 0)
+	if err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := q.acquire(ctx, "", 0); !errors.Is(err, context.Canceled) {
+		t.Errorf("acquire() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestExecQueue_PerToolLimit(t *testing.T) {
+	q := newExecQueue(0, 0, 0)
+
+	release, err := q.acquire(context.Background(), "slow", 1)
+	if err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+	defer release()
+
+	// The tool is at its limit, but other tools still run
+	if _, err := q.acquire(context.Background(), "slow", 1); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("acquire(slow) error = %v, want ErrQueueFull", err)
+	}
+	other, err := q.acquire(context.Background(), "fast", 1)
+	if err != nil {
+		t.Fatalf("acquire(fast) error = %v", err)
+	}
+	other()
+}
+
+func TestExecuteWithSandbox_QueueFull(t *testing.T) {
+	e := NewExecutor(DefaultConfig())
+	limits := DefaultLimits()
+	limits.MaxConcurrency = 1
+	limits.MaxQueue = 0
+	e.SetLimits(limits)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Hold the only slot with a long-running command
+	started := make(chan struct{}, 1)
+	opts := &ExecuteOptions{OnOutput: func(stream string, chunk []byte) {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+	}}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		e.ExecuteWithSandbox(ctx, "/tmp", "sh", []string{"-c", "echo started; sleep 30"}, nil, plugin.SandboxTypeNone, "", opts)
+	}()
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("command did not start")
+	}
+	if stats := e.QueueStats(); stats.Running != 1 || stats.MaxConcurrency != 1 {
+		t.Errorf("QueueStats() = %+v, want 1 running of 1", stats)
+	}
+
+	if _, err := e.ExecuteWithSandbox(context.Background(), "/tmp", "echo", []string{"hi"}, nil, plugin.SandboxTypeNone, "", nil); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("ExecuteWithSandbox() error = %v, want ErrQueueFull", err)
+	}
+
+	cancel()
+	<-done
+}
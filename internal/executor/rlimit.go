@@ -0,0 +1,27 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/takeshy/mcp-gatekeeper/internal/plugin"
+)
+
+// Resource limits reported in ExecuteResult.LimitExceeded
+const (
+	LimitMemory   = "memory"
+	LimitCPU      = "cpu"
+	LimitFileSize = "file_size"
+)
+
+// resourceLimitHelperArg is the first argument of the gatekeeper binary when it is
+// re-executed to apply resource limits before executing a tool's command
+const resourceLimitHelperArg = "__rlimit-exec"
+
+// recordLimitExceeded notes in result which resource limit, if any, ended the command
+func recordLimitExceeded(result *ExecuteResult, state *os.ProcessState, rl *plugin.ResourceLimits) {
+	if limit := exceededResourceLimit(state, rl); limit != "" {
+		result.LimitExceeded = limit
+		result.Stderr = fmt.Sprintf("%s\n[resource limit exceeded: %s]", result.Stderr, limit)
+	}
+}
@@ -0,0 +1,100 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/takeshy/mcp-gatekeeper/internal/plugin"
+)
+
+// TestMain lets the test binary act as the resource limit, namespace and seccomp helpers
+func TestMain(m *testing.M) {
+	RunResourceLimitHelper()
+	RunNamespaceHelper()
+	runSeccompHelper()
+	os.Exit(m.Run())
+}
+
+func TestExecute_ResourceLimitsApplied(t *testing.T) {
+	e := NewExecutor(DefaultConfig())
+	opts := &ExecuteOptions{Resources: &plugin.ResourceLimits{
+		MaxMemoryBytes:   512 * 1024 * 1024,
+		MaxProcesses:     4096,
+		MaxFileSizeBytes: 1024 * 1024,
+		MaxOpenFiles:     64,
+	}}
+
+	result, err := e.ExecuteWithSandbox(context.Background(), "/tmp", "cat", []string{"/proc/self/limits"}, nil, plugin.SandboxTypeNone, "", opts)
+	if err != nil {
+		t.Fatalf("ExecuteWithSandbox() error = %v", err)
+	}
+	if result.ExitCode != 0 {
+		t.Fatalf("exit code = %d, stderr = %s", result.ExitCode, result.Stderr)
+	}
+
+	for _, want := range []string{
+		`Max address space\s+536870912\s+536870912`,
+		`Max processes\s+4096\s+4096`,
+		`Max file size\s+1048576\s+1048576`,
+		`Max open files\s+64\s+64`,
+	} {
+		if !regexp.MustCompile(want).MatchString(result.Stdout) {
+			t.Errorf("limits do not match %q:\n%s", want, result.Stdout)
+		}
+	}
+}
+
+func TestExecute_CPULimitExceeded(t *testing.T) {
+	e := NewExecutor(DefaultConfig())
+	opts := &ExecuteOptions{Resources: &plugin.ResourceLimits{MaxCPUSeconds: 1}}
+
+	result, err := e.ExecuteWithSandbox(context.Background(), "/tmp", "sh", []string{"-c", "while :; do :; done"}, nil, plugin.SandboxTypeNone, "", opts)
+	if err != nil {
+		t.Fatalf("ExecuteWithSandbox() error = %v", err)
+	}
+	if result.TimedOut {
+		t.Fatal("expected the CPU limit to end the command before the timeout")
+	}
+	if result.LimitExceeded != LimitCPU {
+		t.Errorf("LimitExceeded = %q, want %q (exit code %d)", result.LimitExceeded, LimitCPU, result.ExitCode)
+	}
+}
+
+func TestExecute_FileSizeLimitExceeded(t *testing.T) {
+	e := NewExecutor(DefaultConfig())
+	opts := &ExecuteOptions{Resources: &plugin.ResourceLimits{MaxFileSizeBytes: 1024}}
+	out := filepath.Join(t.TempDir(), "out")
+
+	// The shell reports head's SIGXFSZ as exit status 128+SIGXFSZ
+	result, err := e.ExecuteWithSandbox(context.Background(), "/tmp", "sh", []string{"-c", "head -c 65536 /dev/zero > " + out}, nil, plugin.SandboxTypeNone, "", opts)
+	if err != nil {
+		t.Fatalf("ExecuteWithSandbox() error = %v", err)
+	}
+	if result.LimitExceeded != LimitFileSize {
+		t.Errorf("LimitExceeded = %q, want %q (exit code %d)", result.LimitExceeded, LimitFileSize, result.ExitCode)
+	}
+	if info, err := os.Stat(out); err != nil || info.Size() > 1024 {
+		t.Errorf("expected output file of at most 1024 bytes, got %v, %v", info, err)
+	}
+}
+
+func TestExecute_NoLimitExceeded(t *testing.T) {
+	e := NewExecutor(DefaultConfig())
+	opts := &ExecuteOptions{Resources: &plugin.ResourceLimits{MaxCPUSeconds: 10}}
+
+	start := time.Now()
+	result, err := e.ExecuteWithSandbox(context.Background(), "/tmp", "sh", []string{"-c", "exit 3"}, nil, plugin.SandboxTypeNone, "", opts)
+	if err != nil {
+		t.Fatalf("ExecuteWithSandbox() error = %v", err)
+	}
+	if result.ExitCode != 3 || result.LimitExceeded != "" {
+		t.Errorf("got exit code %d, LimitExceeded %q; want 3 and none", result.ExitCode, result.LimitExceeded)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("execution took %v", time.Since(start))
+	}
+}
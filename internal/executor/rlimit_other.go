@@ -0,0 +1,27 @@
+//go:build !linux && !darwin
+
+package executor
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/takeshy/mcp-gatekeeper/internal/plugin"
+)
+
+// RunResourceLimitHelper does nothing; resource limits are not available on this platform
+func RunResourceLimitHelper() {}
+
+// applyResourceLimits fails if any limit is set, since it cannot be enforced on this platform
+func applyResourceLimits(cmd *exec.Cmd, rl *plugin.ResourceLimits) error {
+	if rl.IsZero() {
+		return nil
+	}
+	return fmt.Errorf("resource limits are not supported on this platform")
+}
+
+// exceededResourceLimit reports nothing; resource limits are not available on this platform
+func exceededResourceLimit(state *os.ProcessState, rl *plugin.ResourceLimits) string {
+	return ""
+}
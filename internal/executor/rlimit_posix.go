@@ -0,0 +1,137 @@
+//go:build linux || darwin
+
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/takeshy/mcp-gatekeeper/internal/plugin"
+)
+
+// RunResourceLimitHelper applies resource limits and executes the tool's command when
+// the process was started as the resource limit helper; otherwise it returns at once.
+// Binaries that execute tools must call it at the start of main.
+func RunResourceLimitHelper() {
+	// argv: self, helper arg, limits (JSON), command path, command argv...
+	if len(os.Args) < 5 || os.Args[1] != resourceLimitHelperArg {
+		return
+	}
+
+	var rl plugin.ResourceLimits
+	if err := json.Unmarshal([]byte(os.Args[2]), &rl); err != nil {
+		fmt.Fprintf(os.Stderr, "[ERROR] Invalid resource limits: %v\n", err)
+		os.Exit(127)
+	}
+	if err := setResourceLimits(&rl); err != nil {
+		fmt.Fprintf(os.Stderr, "[ERROR] Failed to set resource limits: %v\n", err)
+		os.Exit(127)
+	}
+
+	path := os.Args[3]
+	err := syscall.Exec(path, os.Args[4:], os.Environ())
+	fmt.Fprintf(os.Stderr, "[ERROR] Failed to execute %s: %v\n", path, err)
+	os.Exit(127)
+}
+
+// setResourceLimits applies the limits to the current process, to be inherited across exec
+func setResourceLimits(rl *plugin.ResourceLimits) error {
+	if rl.MaxMemoryBytes > 0 {
+		if err := setrlimit(syscall.RLIMIT_AS, uint64(rl.MaxMemoryBytes), 0); err != nil {
+			return fmt.Errorf("max_memory_bytes: %w", err)
+		}
+	}
+	if rl.MaxCPUSeconds > 0 {
+		// The soft limit sends SIGXCPU; the hard limit one second later kills the process
+		if err := setrlimit(syscall.RLIMIT_CPU, uint64(rl.MaxCPUSeconds), 1); err != nil {
+			return fmt.Errorf("max_cpu_seconds: %w", err)
+		}
+	}
+	if rl.MaxProcesses > 0 {
+		if err := setrlimit(unix.RLIMIT_NPROC, uint64(rl.MaxProcesses), 0); err != nil {
+			return fmt.Errorf("max_processes: %w", err)
+		}
+	}
+	if rl.MaxFileSizeBytes > 0 {
+		if err := setrlimit(syscall.RLIMIT_FSIZE, uint64(rl.MaxFileSizeBytes), 0); err != nil {
+			return fmt.Errorf("max_file_size_bytes: %w", err)
+		}
+	}
+	if rl.MaxOpenFiles > 0 {
+		if err := setrlimit(syscall.RLIMIT_NOFILE, uint64(rl.MaxOpenFiles), 0); err != nil {
+			return fmt.Errorf("max_open_files: %w", err)
+		}
+	}
+	return nil
+}
+
+// setrlimit lowers a resource's soft limit to cur and its hard limit to cur+hardSlack,
+// never raising the hard limit above its current value
+func setrlimit(resource int, cur, hardSlack uint64) error {
+	var lim syscall.Rlimit
+	if err := syscall.Getrlimit(resource, &lim); err != nil {
+		return err
+	}
+	if lim.Max != unix.RLIM_INFINITY {
+		cur = min(cur, lim.Max)
+		lim.Max = min(cur+hardSlack, lim.Max)
+	} else {
+		lim.Max = cur + hardSlack
+	}
+	lim.Cur = cur
+	// syscall.Setrlimit keeps the Go runtime from restoring RLIMIT_NOFILE on exec
+	return syscall.Setrlimit(resource, &lim)
+}
+
+// applyResourceLimits makes cmd run through the resource limit helper when limits are set
+func applyResourceLimits(cmd *exec.Cmd, rl *plugin.ResourceLimits) error {
+	if rl.IsZero() || cmd.Err != nil {
+		return nil
+	}
+	self, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate executable for resource limits: %w", err)
+	}
+	spec, err := json.Marshal(rl)
+	if err != nil {
+		return fmt.Errorf("failed to encode resource limits: %w", err)
+	}
+	cmd.Args = append([]string{self, resourceLimitHelperArg, string(spec), cmd.Path}, cmd.Args...)
+	cmd.Path = self
+	return nil
+}
+
+// exceededResourceLimit returns the resource limit that ended a failed command, if it can be told.
+// Commands wrapped by bwrap or a shell report a fatal signal as exit status 128+signal.
+func exceededResourceLimit(state *os.ProcessState, rl *plugin.ResourceLimits) string {
+	if rl.IsZero() || state == nil || state.Success() {
+		return ""
+	}
+	status, ok := state.Sys().(syscall.WaitStatus)
+	if !ok {
+		return ""
+	}
+	var sig syscall.Signal
+	if status.Signaled() {
+		sig = status.Signal()
+	} else if code := status.ExitStatus(); code > 128 {
+		sig = syscall.Signal(code - 128)
+	}
+
+	if rl.MaxFileSizeBytes > 0 && sig == syscall.SIGXFSZ {
+		return LimitFileSize
+	}
+	if rl.MaxCPUSeconds > 0 {
+		cpu := state.UserTime() + state.SystemTime()
+		if sig == syscall.SIGXCPU || cpu >= time.Duration(rl.MaxCPUSeconds)*time.Second {
+			return LimitCPU
+		}
+	}
+	return ""
+}
@@ -6,6 +6,8 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+
+	"github.com/takeshy/mcp-gatekeeper/internal/plugin"
 )
 
 // SandboxMode represents the sandboxing mode
@@ -16,6 +18,8 @@ const (
 	SandboxNone SandboxMode = "none"
 	// SandboxBwrap uses bubblewrap for sandboxing
 	SandboxBwrap SandboxMode = "bwrap"
+	// SandboxNamespace uses the built-in Linux namespace sandbox when bwrap is not installed
+	SandboxNamespace SandboxMode = "namespace"
 	// SandboxWasm uses wazero for WASM sandboxing
 	SandboxWasm SandboxMode = "wasm"
 	// SandboxAuto automatically selects the best available sandbox
@@ -27,13 +31,15 @@ type Sandbox struct {
 	mode        SandboxMode
 	rootDir     string
 	bwrap       string   // path to bwrap binary
+	bindPaths   []string // host paths outside rootDir that tools may bind
 	createdDirs []string // directories created for bwrap mounts
 }
 
 // SandboxConfig holds sandbox configuration
 type SandboxConfig struct {
-	Mode    SandboxMode
-	RootDir string
+	Mode      SandboxMode
+	RootDir   string
+	BindPaths []string // Host paths outside RootDir that tool bwrap binds may use
 }
 
 // NewSandbox creates a new Sandbox instance
@@ -62,14 +68,17 @@ func NewSandbox(config *SandboxConfig) (*Sandbox, error) {
 	}
 
 	s := &Sandbox{
-		mode:    config.Mode,
-		rootDir: rootDir,
+		mode:      config.Mode,
+		rootDir:   rootDir,
+		bindPaths: config.BindPaths,
 	}
 
 	// Determine effective mode
 	if s.mode == SandboxAuto || s.mode == SandboxBwrap {
 		bwrapPath, err := exec.LookPath("bwrap")
-		if err != nil {
+		if err != nil && namespacesAvailable() {
+			s.mode = SandboxNamespace
+		} else if err != nil {
 			if s.mode == SandboxBwrap {
 				// Explicitly requested bwrap but not available
 				fmt.Fprintf(os.Stderr, "WARNING: bwrap not found, falling back to path validation only\n")
@@ -101,16 +110,25 @@ func (s *Sandbox) IsBwrapAvailable() bool {
 	return s.bwrap != ""
 }
 
-// WrapCommand wraps a command with sandbox if available
+// IsIsolated returns true if commands run in bwrap or the native namespace sandbox
+func (s *Sandbox) IsIsolated() bool {
+	return s.bwrap != "" || s.mode == SandboxNamespace
+}
+
+// WrapCommand wraps a command with sandbox if available, applying the tool's
+// bwrap mount profile (nil = default profile) and the call's mounts (nil = none).
 // Returns the command name and arguments to execute
-func (s *Sandbox) WrapCommand(cwd, cmd string, args []string) (string, []string, error) {
+func (s *Sandbox) WrapCommand(cwd, cmd string, args []string, cfg *plugin.BwrapConfig, call *CallMounts) (string, []string, error) {
 	// Validate cwd is within root (always, as basic check)
 	if err := s.validatePath(cwd); err != nil {
 		return "", nil, err
 	}
 
 	if s.mode == SandboxBwrap && s.bwrap != "" {
-		return s.wrapWithBwrap(cwd, cmd, args)
+		return s.wrapWithBwrap(cwd, cmd, args, cfg, call)
+	}
+	if s.mode == SandboxNamespace {
+		return s.wrapWithNamespaces(cwd, cmd, args, cfg, call)
 	}
 
 	// No sandboxing, return as-is
@@ -119,15 +137,29 @@ func (s *Sandbox) WrapCommand(cwd, cmd string, args []string) (string, []string,
 
 // wrapWithBwrap creates bwrap command arguments
 // rootDir is mounted as / inside the sandbox (same as WASM)
-func (s *Sandbox) wrapWithBwrap(cwd, cmd string, args []string) (string, []string, error) {
+func (s *Sandbox) wrapWithBwrap(cwd, cmd string, args []string, cfg *plugin.BwrapConfig, call *CallMounts) (string, []string, error) {
+	if cfg == nil {
+		cfg = &plugin.BwrapConfig{}
+	}
+	if call == nil {
+		call = &CallMounts{}
+	}
+
 	// Convert cwd from host path to sandbox path (relative to rootDir -> /)
 	sandboxCwd := s.toSandboxPath(cwd)
 
+	// Mount rootDir as root filesystem, or an overlay of it that takes the command's writes
+	rootArgs := []string{"--bind", s.rootDir, "/"}
+	if cfg.ReadonlyRoot {
+		rootArgs[0] = "--ro-bind"
+	}
+	if ws := call.Workspace; ws != nil {
+		rootArgs = []string{"--overlay-src", s.rootDir, "--overlay", ws.upperDir(), ws.workDir(), "/"}
+	}
+
 	// Build bwrap arguments
 	// Mount rootDir as / first, then overlay system directories
-	bwrapArgs := []string{
-		// Mount rootDir as root filesystem
-		"--bind", s.rootDir, "/",
+	bwrapArgs := append(rootArgs,
 		// Read-only bind mounts for system directories (overlay on top of /)
 		"--ro-bind", "/usr", "/usr",
 		"--ro-bind", "/bin", "/bin",
@@ -139,19 +171,7 @@ func (s *Sandbox) wrapWithBwrap(cwd, cmd string, args []string) (string, []strin
 		"--dev", "/dev",
 		// Create minimal /tmp
 		"--tmpfs", "/tmp",
-		// Set working directory
-		"--chdir", sandboxCwd,
-		// Unshare namespaces for isolation
-		"--unshare-user",
-		"--unshare-pid",
-		"--unshare-net",
-		"--unshare-uts",
-		"--unshare-cgroup",
-		// Die when parent dies
-		"--die-with-parent",
-		// Create new session
-		"--new-session",
-	}
+	)
 
 	// Check if /lib64 exists (some systems don't have it)
 	if _, err := os.Stat("/lib64"); os.IsNotExist(err) {
@@ -175,6 +195,36 @@ func (s *Sandbox) wrapWithBwrap(cwd, cmd string, args []string) (string, []strin
 		}
 	}
 
+	// Add the tool's binds and tmpfs mounts last so they can override the defaults (e.g. hide /etc)
+	mountArgs, err := s.bwrapMountArgs(cfg)
+	if err != nil {
+		return "", nil, err
+	}
+	bwrapArgs = append(bwrapArgs, mountArgs...)
+	if call.Inputs != nil {
+		bwrapArgs = append(bwrapArgs, call.Inputs.bwrapArgs()...)
+	}
+
+	bwrapArgs = append(bwrapArgs,
+		// Set working directory
+		"--chdir", sandboxCwd,
+		// Unshare namespaces for isolation
+		"--unshare-user",
+		"--unshare-pid",
+		"--unshare-uts",
+		"--unshare-cgroup",
+		// Die when parent dies
+		"--die-with-parent",
+		// Create new session
+		"--new-session",
+	)
+	if !cfg.ShareNet {
+		bwrapArgs = append(bwrapArgs, "--unshare-net")
+	}
+	if cfg.Hostname != "" {
+		bwrapArgs = append(bwrapArgs, "--hostname", cfg.Hostname)
+	}
+
 	// Add the actual command and its arguments
 	bwrapArgs = append(bwrapArgs, cmd)
 	bwrapArgs = append(bwrapArgs, args...)
@@ -229,7 +279,7 @@ func (s *Sandbox) ValidatePath(path string) error {
 	return s.validatePath(path)
 }
 
-// bwrapMountDirs returns the list of directories that bwrap needs as mount points
+// bwrapMountDirs returns the list of directories that bwrap and the namespace sandbox need as mount points
 func bwrapMountDirs() []string {
 	dirs := []string{"usr", "bin", "lib", "etc", "dev", "tmp"}
 	// Add lib64 if it exists on the host
@@ -246,7 +296,7 @@ func bwrapMountDirs() []string {
 // PrepareMountDirs creates the directories needed for bwrap mounts
 // Returns the list of directories that were created (not pre-existing)
 func (s *Sandbox) PrepareMountDirs() error {
-	if s.mode != SandboxBwrap {
+	if s.mode != SandboxBwrap && s.mode != SandboxNamespace {
 		return nil
 	}
 
@@ -184,7 +184,7 @@ func TestSandbox_WrapCommand_NoneMode(t *testing.T) {
 	}
 
 	// Test wrapping a command in none mode
-	cmd, args, err := s.WrapCommand(tmpDir, "echo", []string{"hello"})
+	cmd, args, err := s.WrapCommand(tmpDir, "echo", []string{"hello"}, nil, nil)
 	if err != nil {
 		t.Fatalf("WrapCommand() error = %v", err)
 	}
@@ -214,7 +214,7 @@ func TestSandbox_WrapCommand_InvalidPath(t *testing.T) {
 	}
 
 	// Test wrapping with invalid cwd
-	_, _, err = s.WrapCommand("/tmp", "echo", []string{"hello"})
+	_, _, err = s.WrapCommand("/tmp", "echo", []string{"hello"}, nil, nil)
 	if err == nil {
 		t.Errorf("WrapCommand() should fail for path outside root")
 	}
@@ -269,7 +269,7 @@ func TestSandbox_WrapCommand_BwrapMode(t *testing.T) {
 		t.Skipf("bwrap mode not active (mode=%v), skipping", s.Mode())
 	}
 
-	cmd, args, err := s.WrapCommand(tmpDir, "echo", []string{"hello"})
+	cmd, args, err := s.WrapCommand(tmpDir, "echo", []string{"hello"}, nil, nil)
 	if err != nil {
 		t.Fatalf("WrapCommand() error = %v", err)
 	}
@@ -306,7 +306,7 @@ func TestSandbox_WrapCommand_BwrapMode(t *testing.T) {
 }
 
 func TestSandbox_AutoMode_FallbackToNone(t *testing.T) {
-	// This test verifies that auto mode falls back to none when bwrap is not available
+	// This test verifies that auto mode falls back to namespaces, then none, when bwrap is not available
 	// We can't easily simulate bwrap not being available, so we just verify the behavior
 
 	tmpDir, err := os.MkdirTemp("", "sandbox_test")
@@ -323,15 +323,21 @@ func TestSandbox_AutoMode_FallbackToNone(t *testing.T) {
 		t.Fatalf("NewSandbox() error = %v", err)
 	}
 
-	// Mode should be either bwrap (if installed) or none (if not)
+	// Mode should be bwrap (if installed), namespace (if available) or none
 	mode := s.Mode()
-	if mode != SandboxBwrap && mode != SandboxNone {
-		t.Errorf("Mode() = %v, want bwrap or none", mode)
+	if mode != SandboxBwrap && mode != SandboxNamespace && mode != SandboxNone {
+		t.Errorf("Mode() = %v, want bwrap, namespace or none", mode)
 	}
 
-	// If bwrap is not available, mode should be none
-	if !s.IsBwrapAvailable() && mode != SandboxNone {
-		t.Errorf("Mode() = %v when bwrap not available, want none", mode)
+	// If bwrap is not available, mode should be namespace or none
+	if !s.IsBwrapAvailable() {
+		want := SandboxNone
+		if namespacesAvailable() {
+			want = SandboxNamespace
+		}
+		if mode != want {
+			t.Errorf("Mode() = %v when bwrap not available, want %v", mode, want)
+		}
 	}
 
 	// If bwrap is available, mode should be bwrap
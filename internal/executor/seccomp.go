@@ -0,0 +1,132 @@
+package executor
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+
+	"github.com/takeshy/mcp-gatekeeper/internal/plugin"
+)
+
+// Classic BPF opcodes used by seccomp filters
+const (
+	bpfLdWAbs = 0x20 // BPF_LD | BPF_W | BPF_ABS
+	bpfJeqK   = 0x15 // BPF_JMP | BPF_JEQ | BPF_K
+	bpfJgeK   = 0x35 // BPF_JMP | BPF_JGE | BPF_K
+	bpfRetK   = 0x06 // BPF_RET | BPF_K
+)
+
+// Seccomp filter return values and struct seccomp_data offsets
+const (
+	seccompRetKillProcess = 0x80000000
+	seccompRetErrno       = 0x00050000
+	seccompRetAllow       = 0x7fff0000
+
+	seccompDataNr   = 0
+	seccompDataArch = 4
+	seccompDataArg0 = 16 // low 32 bits of args[0] on little-endian hosts
+
+	errnoEPERM = 1
+	afUnix     = 1
+)
+
+// sockFilter is one classic BPF instruction (struct sock_filter)
+type sockFilter struct {
+	Code uint16
+	Jt   uint8
+	Jf   uint8
+	K    uint32
+}
+
+// seccompArch describes the syscall numbers a seccomp filter is compiled for
+type seccompArch struct {
+	auditArch uint32   // AUDIT_ARCH_* value checked against seccomp_data.arch
+	x32Bit    uint32   // Syscall number bit of an alternate ABI to deny (0 = none)
+	dangerous []uint32 // Syscalls denied by every profile
+	strict    []uint32 // Syscalls additionally denied by the strict profile
+	socket    uint32   // socket(2), limited to AF_UNIX by the strict profile
+}
+
+// compileSeccomp compiles a named seccomp profile to a BPF program for arch.
+// Denied syscalls fail with EPERM; syscalls of any other architecture kill the process.
+func compileSeccomp(profile string, arch *seccompArch) ([]sockFilter, error) {
+	if arch == nil {
+		return nil, fmt.Errorf("seccomp profiles are not supported on this platform")
+	}
+
+	var denied []uint32
+	switch profile {
+	case plugin.SeccompDefaultDenyDangerous:
+		denied = arch.dangerous
+	case plugin.SeccompStrict:
+		denied = append(append([]uint32(nil), arch.dangerous...), arch.strict...)
+	default:
+		return nil, fmt.Errorf("unknown seccomp profile %q", profile)
+	}
+
+	deny := sockFilter{Code: bpfRetK, K: seccompRetErrno | errnoEPERM}
+	prog := []sockFilter{
+		{Code: bpfLdWAbs, K: seccompDataArch},
+		{Code: bpfJeqK, Jt: 1, K: arch.auditArch},
+		{Code: bpfRetK, K: seccompRetKillProcess},
+		{Code: bpfLdWAbs, K: seccompDataNr},
+	}
+	if arch.x32Bit != 0 {
+		prog = append(prog, sockFilter{Code: bpfJgeK, Jf: 1, K: arch.x32Bit}, deny)
+	}
+	for _, nr := range denied {
+		prog = append(prog, sockFilter{Code: bpfJeqK, Jf: 1, K: nr}, deny)
+	}
+	if profile == plugin.SeccompStrict {
+		prog = append(prog,
+			sockFilter{Code: bpfJeqK, Jf: 4, K: arch.socket},
+			sockFilter{Code: bpfLdWAbs, K: seccompDataArg0},
+			sockFilter{Code: bpfJeqK, Jf: 1, K: afUnix},
+			sockFilter{Code: bpfRetK, K: seccompRetAllow},
+			deny,
+		)
+	}
+	return append(prog, sockFilter{Code: bpfRetK, K: seccompRetAllow}), nil
+}
+
+// marshalSeccomp encodes a BPF program in the layout bwrap reads from --seccomp
+func marshalSeccomp(prog []sockFilter) []byte {
+	buf := make([]byte, 0, len(prog)*8)
+	for _, ins := range prog {
+		buf = binary.NativeEndian.AppendUint16(buf, ins.Code)
+		buf = append(buf, ins.Jt, ins.Jf)
+		buf = binary.NativeEndian.AppendUint32(buf, ins.K)
+	}
+	return buf
+}
+
+// applySeccomp passes the tool's seccomp profile to the bwrap command cmd on an
+// inherited pipe. The returned function closes the parent's end after the command ran.
+func applySeccomp(cmd *exec.Cmd, cfg *plugin.BwrapConfig) (func(), error) {
+	if cfg == nil || cfg.Seccomp == "" {
+		return func() {}, nil
+	}
+	prog, err := compileSeccomp(cfg.Seccomp, hostSeccompArch)
+	if err != nil {
+		return nil, err
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create seccomp pipe: %w", err)
+	}
+	// The program is far smaller than the pipe buffer, so the write does not block
+	_, err = w.Write(marshalSeccomp(prog))
+	w.Close()
+	if err != nil {
+		r.Close()
+		return nil, fmt.Errorf("failed to write seccomp program: %w", err)
+	}
+
+	cmd.ExtraFiles = append(cmd.ExtraFiles, r)
+	fd := strconv.Itoa(2 + len(cmd.ExtraFiles))
+	cmd.Args = append([]string{cmd.Args[0], "--seccomp", fd}, cmd.Args[1:]...)
+	return func() { r.Close() }, nil
+}
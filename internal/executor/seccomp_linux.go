@@ -0,0 +1,35 @@
+//go:build linux && (amd64 || arm64)
+
+package executor
+
+import "golang.org/x/sys/unix"
+
+// hostSeccompArch is the seccomp syscall table of the running architecture
+var hostSeccompArch = &seccompArch{
+	auditArch: seccompAuditArch,
+	x32Bit:    seccompX32Bit,
+	dangerous: append([]uint32{
+		// Tracing and cross-process memory access
+		unix.SYS_PTRACE, unix.SYS_PROCESS_VM_READV, unix.SYS_PROCESS_VM_WRITEV, unix.SYS_KCMP,
+		// Mounts and namespaces
+		unix.SYS_MOUNT, unix.SYS_UMOUNT2, unix.SYS_PIVOT_ROOT, unix.SYS_SETNS, unix.SYS_UNSHARE,
+		unix.SYS_FSOPEN, unix.SYS_FSCONFIG, unix.SYS_FSMOUNT, unix.SYS_FSPICK, unix.SYS_MOVE_MOUNT,
+		unix.SYS_OPEN_TREE, unix.SYS_MOUNT_SETATTR,
+		unix.SYS_NAME_TO_HANDLE_AT, unix.SYS_OPEN_BY_HANDLE_AT,
+		// Kernel keyring
+		unix.SYS_KEYCTL, unix.SYS_ADD_KEY, unix.SYS_REQUEST_KEY,
+		// Kernel modules, BPF and performance counters
+		unix.SYS_INIT_MODULE, unix.SYS_FINIT_MODULE, unix.SYS_DELETE_MODULE,
+		unix.SYS_KEXEC_LOAD, unix.SYS_KEXEC_FILE_LOAD, unix.SYS_BPF, unix.SYS_PERF_EVENT_OPEN,
+		unix.SYS_USERFAULTFD, unix.SYS_LOOKUP_DCOOKIE,
+		// System administration
+		unix.SYS_REBOOT, unix.SYS_SWAPON, unix.SYS_SWAPOFF, unix.SYS_ACCT, unix.SYS_QUOTACTL,
+		unix.SYS_CLOCK_ADJTIME, unix.SYS_CLOCK_SETTIME, unix.SYS_SETTIMEOFDAY,
+	}, archDangerousSyscalls...),
+	strict: append([]uint32{
+		unix.SYS_CHROOT, unix.SYS_MKNODAT, unix.SYS_SETHOSTNAME, unix.SYS_SETDOMAINNAME,
+		unix.SYS_SYSLOG, unix.SYS_VHANGUP,
+		unix.SYS_IO_URING_SETUP, unix.SYS_IO_URING_ENTER, unix.SYS_IO_URING_REGISTER,
+	}, archStrictSyscalls...),
+	socket: unix.SYS_SOCKET,
+}
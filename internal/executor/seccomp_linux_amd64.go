@@ -0,0 +1,17 @@
+package executor
+
+import "golang.org/x/sys/unix"
+
+const (
+	seccompAuditArch = unix.AUDIT_ARCH_X86_64
+	seccompX32Bit    = 0x40000000 // __X32_SYSCALL_BIT
+)
+
+// archDangerousSyscalls are the x86-64 only syscalls denied by every profile
+var archDangerousSyscalls = []uint32{
+	unix.SYS_IOPL, unix.SYS_IOPERM, unix.SYS_USELIB, unix.SYS_USTAT, unix.SYS__SYSCTL, unix.SYS_SYSFS,
+	unix.SYS_CREATE_MODULE, unix.SYS_GET_KERNEL_SYMS, unix.SYS_QUERY_MODULE, unix.SYS_NFSSERVCTL,
+}
+
+// archStrictSyscalls are the x86-64 only syscalls additionally denied by the strict profile
+var archStrictSyscalls = []uint32{unix.SYS_MKNOD}
@@ -0,0 +1,14 @@
+package executor
+
+import "golang.org/x/sys/unix"
+
+const (
+	seccompAuditArch = unix.AUDIT_ARCH_AARCH64
+	seccompX32Bit    = 0 // arm64 has no alternate syscall ABI
+)
+
+// archDangerousSyscalls are the arm64 only syscalls denied by every profile
+var archDangerousSyscalls []uint32
+
+// archStrictSyscalls are the arm64 only syscalls additionally denied by the strict profile
+var archStrictSyscalls []uint32
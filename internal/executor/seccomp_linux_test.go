@@ -0,0 +1,119 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/takeshy/mcp-gatekeeper/internal/plugin"
+)
+
+// seccompHelperEnv makes the test binary install a seccomp profile and probe syscalls
+const seccompHelperEnv = "MCP_GATEKEEPER_SECCOMP_HELPER"
+
+// runSeccompHelper installs the profile named by seccompHelperEnv, then prints
+// "name=result" for a few syscalls and exits. It returns if the variable is unset.
+func runSeccompHelper() {
+	profile := os.Getenv(seccompHelperEnv)
+	if profile == "" {
+		return
+	}
+	if err := installSeccomp(profile); err != nil {
+		fmt.Println("error:", err)
+		os.Exit(2)
+	}
+
+	probe := func(name string, err error) {
+		result := "ok"
+		if err == unix.EPERM {
+			result = "EPERM"
+		}
+		fmt.Printf("%s=%s\n", name, result)
+	}
+	_, err := unix.KeyctlGetKeyringID(unix.KEY_SPEC_PROCESS_KEYRING, false)
+	probe("keyctl", err)
+	_, _, errno := unix.Syscall6(unix.SYS_PTRACE, unix.PTRACE_PEEKDATA, uintptr(os.Getppid()), 0, 0, 0, 0)
+	probe("ptrace", errnoErr(errno))
+	probe("unshare", unix.Unshare(unix.CLONE_NEWNS))
+	fd, err := unix.Socket(unix.AF_INET, unix.SOCK_STREAM, 0)
+	probe("socket_inet", err)
+	if err == nil {
+		unix.Close(fd)
+	}
+	fd, err = unix.Socket(unix.AF_UNIX, unix.SOCK_STREAM, 0)
+	probe("socket_unix", err)
+	if err == nil {
+		unix.Close(fd)
+	}
+	_, err = os.Getwd()
+	probe("getcwd", err)
+	os.Exit(0)
+}
+
+func errnoErr(errno unix.Errno) error {
+	if errno == 0 {
+		return nil
+	}
+	return errno
+}
+
+func TestSeccompProfiles_Helper(t *testing.T) {
+	if hostSeccompArch == nil {
+		t.Skip("seccomp profiles are not supported on this architecture")
+	}
+	tests := []struct {
+		profile string
+		want    []string
+	}{
+		{plugin.SeccompDefaultDenyDangerous, []string{"keyctl=EPERM", "ptrace=EPERM", "unshare=EPERM", "socket_inet=ok", "socket_unix=ok", "getcwd=ok"}},
+		{plugin.SeccompStrict, []string{"keyctl=EPERM", "ptrace=EPERM", "unshare=EPERM", "socket_inet=EPERM", "socket_unix=ok", "getcwd=ok"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.profile, func(t *testing.T) {
+			cmd := exec.Command(os.Args[0], "-test.run=^$")
+			cmd.Env = append(os.Environ(), seccompHelperEnv+"="+tt.profile)
+			out, err := cmd.CombinedOutput()
+			if err != nil {
+				t.Fatalf("helper failed: %v\n%s", err, out)
+			}
+			lines := strings.Fields(string(out))
+			for _, want := range tt.want {
+				found := false
+				for _, line := range lines {
+					if line == want {
+						found = true
+					}
+				}
+				if !found {
+					t.Errorf("expected %q in helper output:\n%s", want, out)
+				}
+			}
+		})
+	}
+}
+
+func TestExecute_BwrapSeccomp(t *testing.T) {
+	bwrapPath, err := exec.LookPath("bwrap")
+	if err != nil {
+		t.Skip("bwrap not installed")
+	}
+
+	rootDir := t.TempDir()
+	s := &Sandbox{mode: SandboxBwrap, rootDir: rootDir, bwrap: bwrapPath}
+	e := &Executor{config: DefaultConfig(), sandbox: s, queue: newExecQueue(DefaultMaxConcurrency, DefaultMaxQueue, DefaultQueueTimeout)}
+	opts := &ExecuteOptions{Bwrap: &plugin.BwrapConfig{Seccomp: plugin.SeccompStrict}}
+
+	result, err := e.executeWithBwrap(context.Background(), rootDir, "sh", []string{"-c", "echo ok"}, nil, opts)
+	if err != nil {
+		t.Fatalf("executeWithBwrap() error = %v", err)
+	}
+	if result.ExitCode != 0 || !strings.Contains(result.Stdout, "ok") {
+		t.Errorf("expected the command to run under the strict profile, got exit %d: %s", result.ExitCode, result.Stderr)
+	}
+}
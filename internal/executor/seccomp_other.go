@@ -0,0 +1,6 @@
+//go:build !linux || !(amd64 || arm64)
+
+package executor
+
+// hostSeccompArch is nil; seccomp profiles are not available on this platform
+var hostSeccompArch *seccompArch
@@ -0,0 +1,82 @@
+package executor
+
+import (
+	"io"
+	"os/exec"
+	"testing"
+
+	"github.com/takeshy/mcp-gatekeeper/internal/plugin"
+)
+
+func TestCompileSeccomp(t *testing.T) {
+	arch := &seccompArch{auditArch: 0xc000003e, x32Bit: 0x40000000, dangerous: []uint32{101, 165}, strict: []uint32{161}, socket: 41}
+
+	tests := []struct {
+		name    string
+		profile string
+		arch    *seccompArch
+		wantLen int
+		wantErr bool
+	}{
+		// arch check (4) + x32 check (2) + 2 per denied syscall + final allow
+		{"default", plugin.SeccompDefaultDenyDangerous, arch, 4 + 2 + 4 + 1, false},
+		// plus the AF_UNIX socket check (5)
+		{"strict", plugin.SeccompStrict, arch, 4 + 2 + 6 + 5 + 1, false},
+		{"unknown profile", "permissive", arch, 0, true},
+		{"unsupported platform", plugin.SeccompStrict, nil, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prog, err := compileSeccomp(tt.profile, tt.arch)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("compileSeccomp() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(prog) != tt.wantLen {
+				t.Errorf("len(prog) = %d, want %d", len(prog), tt.wantLen)
+			}
+			if last := prog[len(prog)-1]; last.Code != bpfRetK || last.K != seccompRetAllow {
+				t.Errorf("expected the program to end with RET ALLOW, got %+v", last)
+			}
+			if len(marshalSeccomp(prog)) != 8*len(prog) {
+				t.Errorf("expected 8 bytes per instruction")
+			}
+		})
+	}
+}
+
+func TestApplySeccomp(t *testing.T) {
+	if hostSeccompArch == nil {
+		t.Skip("seccomp profiles are not supported on this platform")
+	}
+
+	cmd := exec.Command("/usr/bin/bwrap", "--unshare-user", "ls")
+	closeSeccomp, err := applySeccomp(cmd, &plugin.BwrapConfig{Seccomp: plugin.SeccompDefaultDenyDangerous})
+	if err != nil {
+		t.Fatalf("applySeccomp() error = %v", err)
+	}
+	defer closeSeccomp()
+
+	if !containsSeq(cmd.Args, "/usr/bin/bwrap", "--seccomp", "3", "--unshare-user", "ls") {
+		t.Errorf("expected --seccomp 3 right after bwrap, got %v", cmd.Args)
+	}
+	if len(cmd.ExtraFiles) != 1 {
+		t.Fatalf("expected one extra file, got %d", len(cmd.ExtraFiles))
+	}
+	data, err := io.ReadAll(cmd.ExtraFiles[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	prog, _ := compileSeccomp(plugin.SeccompDefaultDenyDangerous, hostSeccompArch)
+	if string(data) != string(marshalSeccomp(prog)) {
+		t.Errorf("pipe holds %d bytes, want the %d byte program", len(data), 8*len(prog))
+	}
+
+	unchanged := exec.Command("/usr/bin/bwrap", "ls")
+	if _, err := applySeccomp(unchanged, &plugin.BwrapConfig{}); err != nil || len(unchanged.Args) != 2 || unchanged.ExtraFiles != nil {
+		t.Errorf("expected no change without a profile, got %v, %v", unchanged.Args, err)
+	}
+}
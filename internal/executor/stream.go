@@ -0,0 +1,47 @@
+package executor
+
+import (
+	"io"
+	"sync"
+)
+
+// Output stream names passed to OutputFunc
+const (
+	StreamStdout = "stdout"
+	StreamStderr = "stderr"
+)
+
+// OutputFunc receives output as a command produces it. The chunk is only
+// valid for the duration of the call.
+type OutputFunc func(stream string, chunk []byte)
+
+// outputWriters returns the writers capturing a command's stdout and stderr.
+// When opts.OnOutput is set, output kept by the buffers is also streamed to it.
+func outputWriters(stdout, stderr *limitedBuffer, opts *ExecuteOptions) (io.Writer, io.Writer) {
+	if opts == nil || opts.OnOutput == nil {
+		return stdout, stderr
+	}
+	mu := &sync.Mutex{}
+	return &streamingWriter{buf: stdout, stream: StreamStdout, fn: opts.OnOutput, mu: mu},
+		&streamingWriter{buf: stderr, stream: StreamStderr, fn: opts.OnOutput, mu: mu}
+}
+
+// streamingWriter writes to a limitedBuffer and forwards the kept bytes to an OutputFunc
+type streamingWriter struct {
+	buf    *limitedBuffer
+	stream string
+	fn     OutputFunc
+	mu     *sync.Mutex // Shared by stdout and stderr so fn is never called concurrently
+}
+
+func (w *streamingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	before := w.buf.buf.Len()
+	n, err := w.buf.Write(p)
+	if kept := w.buf.buf.Len() - before; kept > 0 {
+		w.fn(w.stream, p[:kept])
+	}
+	return n, err
+}
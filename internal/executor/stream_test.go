@@ -0,0 +1,62 @@
+package executor
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/takeshy/mcp-gatekeeper/internal/plugin"
+)
+
+func TestExecuteWithSandbox_StreamsOutput(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxOutput  int
+		script     string
+		wantStdout string
+		wantStderr string
+	}{
+		{
+			name:       "stdout and stderr",
+			maxOutput:  DefaultMaxOutput,
+			script:     "echo out; echo err >&2",
+			wantStdout: "out\n",
+			wantStderr: "err\n",
+		},
+		{
+			name:       "truncated output is not streamed",
+			maxOutput:  10,
+			script:     "yes | head -100",
+			wantStdout: "y\ny\ny\ny\ny\n",
+			wantStderr: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewExecutor(&ExecutorConfig{Timeout: DefaultTimeout, MaxOutput: tt.maxOutput})
+
+			streamed := map[string]*strings.Builder{
+				StreamStdout: {},
+				StreamStderr: {},
+			}
+			opts := &ExecuteOptions{OnOutput: func(stream string, chunk []byte) {
+				streamed[stream].Write(chunk)
+			}}
+
+			result, err := e.ExecuteWithSandbox(context.Background(), "/tmp", "sh", []string{"-c", tt.script}, nil, plugin.SandboxTypeNone, "", opts)
+			if err != nil {
+				t.Fatalf("ExecuteWithSandbox() error = %v", err)
+			}
+			if got := streamed[StreamStdout].String(); got != tt.wantStdout {
+				t.Errorf("streamed stdout = %q, want %q", got, tt.wantStdout)
+			}
+			if got := streamed[StreamStderr].String(); got != tt.wantStderr {
+				t.Errorf("streamed stderr = %q, want %q", got, tt.wantStderr)
+			}
+			if !strings.HasPrefix(result.Stdout, tt.wantStdout) {
+				t.Errorf("result stdout = %q, want prefix %q", result.Stdout, tt.wantStdout)
+			}
+		})
+	}
+}
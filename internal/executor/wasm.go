@@ -14,6 +14,9 @@ import (
 	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
 )
 
+// wasmPageSize is the size of a WebAssembly memory page
+const wasmPageSize = 64 * 1024
+
 // WasmExecutor handles WASM module execution using wazero
 type WasmExecutor struct {
 	rootDir string
@@ -38,10 +41,12 @@ func NewWasmExecutor(rootDir string, wasmDir string) *WasmExecutor {
 	}
 }
 
-// getOrCompile retrieves a cached compiled module or compiles it
-func (w *WasmExecutor) getOrCompile(ctx context.Context, wasmPath string) (wazero.Runtime, wazero.CompiledModule, error) {
+// getOrCompile retrieves a cached compiled module or compiles it. Modules are cached
+// per memory limit (in pages, 0 = wazero default) since the limit is set on the runtime.
+func (w *WasmExecutor) getOrCompile(ctx context.Context, wasmPath string, memoryLimitPages uint32) (wazero.Runtime, wazero.CompiledModule, error) {
+	key := fmt.Sprintf("%s:%d", wasmPath, memoryLimitPages)
 	w.cacheMu.RLock()
-	cached, ok := w.cache[wasmPath]
+	cached, ok := w.cache[key]
 	w.cacheMu.RUnlock()
 
 	if ok {
@@ -53,7 +58,7 @@ func (w *WasmExecutor) getOrCompile(ctx context.Context, wasmPath string) (wazer
 	defer w.cacheMu.Unlock()
 
 	// Double-check after acquiring write lock
-	if cached, ok := w.cache[wasmPath]; ok {
+	if cached, ok := w.cache[key]; ok {
 		return cached.runtime, cached.compiled, nil
 	}
 
@@ -63,8 +68,12 @@ func (w *WasmExecutor) getOrCompile(ctx context.Context, wasmPath string) (wazer
 		return nil, nil, fmt.Errorf("failed to read WASM binary %s: %w", wasmPath, err)
 	}
 
-	// Create runtime with compilation cache
-	runtime := wazero.NewRuntime(ctx)
+	// Create runtime; modules are closed when their execution context is done
+	runtimeConfig := wazero.NewRuntimeConfig().WithCloseOnContextDone(true)
+	if memoryLimitPages > 0 {
+		runtimeConfig = runtimeConfig.WithMemoryLimitPages(memoryLimitPages)
+	}
+	runtime := wazero.NewRuntimeWithConfig(ctx, runtimeConfig)
 
 	// Instantiate WASI
 	wasi_snapshot_preview1.MustInstantiate(ctx, runtime)
@@ -77,7 +86,7 @@ func (w *WasmExecutor) getOrCompile(ctx context.Context, wasmPath string) (wazer
 	}
 
 	// Cache it
-	w.cache[wasmPath] = &wasmCache{
+	w.cache[key] = &wasmCache{
 		runtime:  runtime,
 		compiled: compiled,
 	}
@@ -87,23 +96,31 @@ func (w *WasmExecutor) getOrCompile(ctx context.Context, wasmPath string) (wazer
 
 // Execute runs a WASM binary with the given arguments
 func (w *WasmExecutor) Execute(ctx context.Context, wasmPath string, cwd string, args []string, env []string, timeout time.Duration, maxOutput int) (*ExecuteResult, error) {
+	return w.execute(ctx, wasmPath, cwd, args, env, execLimits{timeout: timeout, maxStdout: maxOutput, maxStderr: maxOutput}, nil)
+}
+
+// execute runs a WASM binary within limits, streaming its output to opts.OnOutput when set
+func (w *WasmExecutor) execute(ctx context.Context, wasmPath string, cwd string, args []string, env []string, limits execLimits, opts *ExecuteOptions) (*ExecuteResult, error) {
 	startTime := time.Now()
 	result := &ExecuteResult{}
+	timeout, maxStdout, maxStderr := limits.timeout, limits.maxStdout, limits.maxStderr
+	memoryLimitPages := limits.resources.WasmMemoryPages()
 
 	// Create timeout context
 	execCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
 	// Get or compile the module
-	runtime, compiled, err := w.getOrCompile(execCtx, wasmPath)
+	runtime, compiled, err := w.getOrCompile(execCtx, wasmPath, memoryLimitPages)
 	if err != nil {
 		return nil, err
 	}
 
 	// Prepare output buffers
 	var stdout, stderr limitedBuffer
-	stdout.maxSize = maxOutput
-	stderr.maxSize = maxOutput
+	stdout.maxSize = maxStdout
+	stderr.maxSize = maxStderr
+	stdoutWriter, stderrWriter := outputWriters(&stdout, &stderr, opts)
 
 	// Configure filesystem - mount rootDir as / for sandboxing
 	// This makes rootDir appear as the root filesystem to the WASM module
@@ -113,6 +130,9 @@ func (w *WasmExecutor) Execute(ctx context.Context, wasmPath string, cwd string,
 	if w.wasmDir != "" {
 		fsConfig = fsConfig.WithDirMount(w.wasmDir, "/.wasm")
 	}
+	if inputs := inputFiles(opts); inputs != nil {
+		fsConfig = fsConfig.WithReadOnlyDirMount(inputs.hostDir, InputFilesDir)
+	}
 
 	// Convert host paths to guest paths
 	toGuestPath := func(hostPath string) string {
@@ -148,11 +168,15 @@ func (w *WasmExecutor) Execute(ctx context.Context, wasmPath string, cwd string,
 
 	// Configure the module
 	config := wazero.NewModuleConfig().
-		WithStdout(&stdout).
-		WithStderr(&stderr).
+		WithStdout(stdoutWriter).
+		WithStderr(stderrWriter).
 		WithArgs(guestArgs...).
 		WithFSConfig(fsConfig)
 
+	if stdin := stdinReader(opts); stdin != nil {
+		config = config.WithStdin(stdin)
+	}
+
 	// Set working directory if supported
 	if guestCwd != "" {
 		config = config.WithEnv("PWD", guestCwd)
@@ -192,7 +216,7 @@ func (w *WasmExecutor) Execute(ctx context.Context, wasmPath string, cwd string,
 	moduleName := fmt.Sprintf("module-%d", time.Now().UnixNano())
 	config = config.WithName(moduleName)
 
-	_, err = runtime.InstantiateModule(execCtx, compiled, config)
+	mod, err := runtime.InstantiateModule(execCtx, compiled, config)
 	if err != nil {
 		// Check for timeout
 		if execCtx.Err() == context.DeadlineExceeded {
@@ -202,6 +226,13 @@ func (w *WasmExecutor) Execute(ctx context.Context, wasmPath string, cwd string,
 			result.DurationMs = time.Since(startTime).Milliseconds()
 			return result, nil
 		}
+		if execCtx.Err() == context.Canceled {
+			result.Cancelled = true
+			result.ExitCode = -1
+			result.Stderr = "[execution cancelled]"
+			result.DurationMs = time.Since(startTime).Milliseconds()
+			return result, nil
+		}
 
 		// WASM exit codes come through as errors
 		// Try to extract exit code from error
@@ -215,10 +246,19 @@ func (w *WasmExecutor) Execute(ctx context.Context, wasmPath string, cwd string,
 
 	// Add truncation notice if output was limited
 	if stdout.truncated {
-		result.Stdout += fmt.Sprintf("\n[output truncated, exceeded %d bytes]", maxOutput)
+		result.StdoutTruncated = true
+		result.Stdout += fmt.Sprintf("\n[output truncated, exceeded %d bytes]", maxStdout)
 	}
 	if stderr.truncated {
-		result.Stderr += fmt.Sprintf("\n[output truncated, exceeded %d bytes]", maxOutput)
+		result.Stderr += fmt.Sprintf("\n[output truncated, exceeded %d bytes]", maxStderr)
+	}
+
+	// A module that failed with no room left to grow its memory hit the memory limit
+	if err != nil && memoryLimitPages > 0 && mod != nil {
+		if mem := mod.ExportedMemory("memory"); mem != nil && uint64(mem.Size())+wasmPageSize > uint64(memoryLimitPages)*wasmPageSize {
+			result.LimitExceeded = LimitMemory
+			result.Stderr = fmt.Sprintf("%s\n[resource limit exceeded: %s]", result.Stderr, LimitMemory)
+		}
 	}
 
 	return result, nil
@@ -0,0 +1,356 @@
+package executor
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/takeshy/mcp-gatekeeper/internal/plugin"
+)
+
+// maxWorkspaceChanges is the most changes listed in a result; a commit applies all of them
+const maxWorkspaceChanges = 1000
+
+// Kinds of workspace changes
+const (
+	WorkspaceAdded    = "added"
+	WorkspaceModified = "modified"
+	WorkspaceDeleted  = "deleted"
+)
+
+// WorkspaceChange is a path the command added, modified or deleted in its workspace
+type WorkspaceChange struct {
+	Path string `json:"path"` // Path inside the sandbox
+	Kind string `json:"kind"`
+}
+
+// WorkspaceResult describes what a command changed in its overlay workspace
+type WorkspaceResult struct {
+	Changes     []WorkspaceChange `json:"changes"`
+	Truncated   bool              `json:"truncated,omitempty"`    // More than maxWorkspaceChanges changes were made
+	Committed   bool              `json:"committed"`              // The changes were applied to the root directory
+	CommitError string            `json:"commit_error,omitempty"` // Why applying the changes failed part way
+}
+
+// CallMounts holds the per-call directories mounted into a bubblewrap or namespace sandbox
+type CallMounts struct {
+	Inputs    *InputFiles // File arguments (nil = none)
+	Workspace *Workspace  // Overlay over the root directory (nil = the root is bound directly)
+}
+
+// Workspace is the copy-on-write layer a command's writes to the root directory go to.
+// The root directory itself is left untouched unless the changes are committed.
+type Workspace struct {
+	rootDir     string
+	dir         string          // Holds the overlay's upper and work directories
+	mountPoints map[string]bool // Sandbox paths the sandbox mounts over, whose mount points are not changes
+}
+
+// newWorkspace creates the overlay directories for a call. mountPoints are the sandbox
+// paths mounted over the root directory. Close removes them.
+func newWorkspace(rootDir string, mountPoints []string) (*Workspace, error) {
+	dir, err := os.MkdirTemp("", "mcp-gatekeeper-workspace-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create workspace: %w", err)
+	}
+	w := &Workspace{rootDir: rootDir, dir: dir, mountPoints: make(map[string]bool)}
+	for _, sub := range []string{"upper", "work"} {
+		if err := os.Mkdir(filepath.Join(dir, sub), 0755); err != nil {
+			w.Close()
+			return nil, fmt.Errorf("failed to create workspace: %w", err)
+		}
+	}
+	// A mount point and the directories created to hold it are not changes
+	for _, p := range mountPoints {
+		for p = path.Clean("/" + p); p != "/"; p = path.Dir(p) {
+			w.mountPoints[p] = true
+		}
+	}
+	return w, nil
+}
+
+// upperDir holds the files the command created or changed
+func (w *Workspace) upperDir() string {
+	return filepath.Join(w.dir, "upper")
+}
+
+// workDir is the overlay's scratch directory
+func (w *Workspace) workDir() string {
+	return filepath.Join(w.dir, "work")
+}
+
+// Close removes the workspace and any changes not committed
+func (w *Workspace) Close() error {
+	if w == nil {
+		return nil
+	}
+	// The kernel leaves an inaccessible directory in the work directory
+	os.Chmod(filepath.Join(w.workDir(), "work"), 0700)
+	return os.RemoveAll(w.dir)
+}
+
+// Changes compares the upper directory with the root directory and lists what the
+// command added, modified or deleted
+func (w *Workspace) Changes() ([]WorkspaceChange, error) {
+	var changes []WorkspaceChange
+	if err := w.scan(".", &changes); err != nil {
+		return nil, fmt.Errorf("failed to scan workspace: %w", err)
+	}
+	return changes, nil
+}
+
+// scan lists the changes under the relative directory rel of the upper directory
+func (w *Workspace) scan(rel string, changes *[]WorkspaceChange) error {
+	upper := filepath.Join(w.upperDir(), rel)
+	entries, err := os.ReadDir(upper)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		seen[entry.Name()] = true
+		p := filepath.Join(rel, entry.Name())
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		lower, lowerErr := os.Lstat(filepath.Join(w.rootDir, p))
+		if lowerErr != nil && !os.IsNotExist(lowerErr) {
+			return lowerErr
+		}
+		kind := WorkspaceModified
+		if lowerErr != nil {
+			kind = WorkspaceAdded
+		}
+
+		switch {
+		case isWhiteout(info):
+			if lowerErr == nil {
+				w.add(changes, p, WorkspaceDeleted)
+			}
+		case info.IsDir():
+			if lowerErr != nil || !lower.IsDir() {
+				if !w.mountPoints[sandboxPath(p)] {
+					w.add(changes, p, kind)
+				}
+			} else if isOpaqueDir(filepath.Join(upper, entry.Name())) {
+				if err := w.scanOpaque(p, changes); err != nil {
+					return err
+				}
+			}
+			if err := w.scan(p, changes); err != nil {
+				return err
+			}
+		case info.Mode().IsRegular() || info.Mode()&fs.ModeSymlink != 0:
+			if lowerErr == nil && sameFile(filepath.Join(w.upperDir(), p), info, filepath.Join(w.rootDir, p), lower) {
+				continue
+			}
+			if kind == WorkspaceAdded && info.Mode().IsRegular() && info.Size() == 0 && w.mountPoints[sandboxPath(p)] {
+				continue
+			}
+			w.add(changes, p, kind)
+		}
+	}
+	return nil
+}
+
+// scanOpaque lists the root directory's entries hidden by a directory the command
+// removed and created again
+func (w *Workspace) scanOpaque(rel string, changes *[]WorkspaceChange) error {
+	entries, err := os.ReadDir(filepath.Join(w.rootDir, rel))
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		p := filepath.Join(rel, entry.Name())
+		if _, err := os.Lstat(filepath.Join(w.upperDir(), p)); os.IsNotExist(err) {
+			w.add(changes, p, WorkspaceDeleted)
+		}
+	}
+	return nil
+}
+
+// add records a change to the relative path rel
+func (w *Workspace) add(changes *[]WorkspaceChange, rel, kind string) {
+	*changes = append(*changes, WorkspaceChange{Path: sandboxPath(rel), Kind: kind})
+}
+
+// sandboxPath converts a path relative to the root directory to the path inside the sandbox
+func sandboxPath(rel string) string {
+	return path.Join("/", filepath.ToSlash(rel))
+}
+
+// sameFile reports whether the upper and lower files have the same type, mode and content
+func sameFile(upperPath string, upper fs.FileInfo, lowerPath string, lower fs.FileInfo) bool {
+	if upper.Mode() != lower.Mode() {
+		return false
+	}
+	if upper.Mode()&fs.ModeSymlink != 0 {
+		a, errA := os.Readlink(upperPath)
+		b, errB := os.Readlink(lowerPath)
+		return errA == nil && errB == nil && a == b
+	}
+	if upper.Size() != lower.Size() {
+		return false
+	}
+	return sameContent(upperPath, lowerPath)
+}
+
+// sameContent compares two files chunk by chunk, so that large files are never read whole
+func sameContent(pathA, pathB string) bool {
+	fa, err := os.Open(pathA)
+	if err != nil {
+		return false
+	}
+	defer fa.Close()
+	fb, err := os.Open(pathB)
+	if err != nil {
+		return false
+	}
+	defer fb.Close()
+
+	ra, rb := bufio.NewReader(fa), bufio.NewReader(fb)
+	bufA, bufB := make([]byte, 32*1024), make([]byte, 32*1024)
+	for {
+		na, errA := io.ReadFull(ra, bufA)
+		nb, errB := io.ReadFull(rb, bufB)
+		if na != nb || !bytes.Equal(bufA[:na], bufB[:nb]) {
+			return false
+		}
+		switch {
+		case errA == io.EOF || errA == io.ErrUnexpectedEOF:
+			return errB == io.EOF || errB == io.ErrUnexpectedEOF
+		case errA != nil || errB != nil:
+			return false
+		}
+	}
+}
+
+// Commit applies the changes to the root directory
+func (w *Workspace) Commit(changes []WorkspaceChange) error {
+	root, err := filepath.EvalSymlinks(w.rootDir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve root directory: %w", err)
+	}
+	for _, c := range changes {
+		rel := filepath.FromSlash(strings.TrimPrefix(c.Path, "/"))
+		target := filepath.Join(root, rel)
+		// Refuse to follow a symlink in the root directory out of it
+		parent, err := filepath.EvalSymlinks(filepath.Dir(target))
+		if err != nil || !IsPathWithinRoot(root, parent) {
+			return fmt.Errorf("cannot commit %s: parent directory is outside the root directory", c.Path)
+		}
+		target = filepath.Join(parent, filepath.Base(target))
+		if err := w.apply(filepath.Join(w.upperDir(), rel), target, c.Kind); err != nil {
+			return fmt.Errorf("cannot commit %s: %w", c.Path, err)
+		}
+	}
+	return nil
+}
+
+// apply replaces the target with the upper directory's version of it
+func (w *Workspace) apply(src, target, kind string) error {
+	if kind == WorkspaceDeleted {
+		return os.RemoveAll(target)
+	}
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+	existing, err := os.Lstat(target)
+	if err == nil && (info.IsDir() != existing.IsDir() || info.Mode()&fs.ModeSymlink != 0) {
+		if err := os.RemoveAll(target); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case info.IsDir():
+		if err := os.MkdirAll(target, 0755); err != nil {
+			return err
+		}
+		return os.Chmod(target, info.Mode().Perm())
+	case info.Mode()&fs.ModeSymlink != 0:
+		link, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(link, target)
+	}
+
+	// Write a temporary file and rename it over the target, which never follows a symlink
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.CreateTemp(filepath.Dir(target), ".mcp-gatekeeper-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(out.Name())
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(out.Name(), info.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Rename(out.Name(), target)
+}
+
+// Finish lists the changes for the result and commits them if requested and the command succeeded
+func (w *Workspace) Finish(result *ExecuteResult, commit bool) (*WorkspaceResult, error) {
+	changes, err := w.Changes()
+	if err != nil {
+		return nil, err
+	}
+	ws := &WorkspaceResult{Changes: changes}
+	if commit && result.ExitCode == 0 && !result.TimedOut && !result.Cancelled {
+		if err := w.Commit(changes); err != nil {
+			ws.CommitError = err.Error()
+		} else {
+			ws.Committed = true
+		}
+	}
+	if len(ws.Changes) > maxWorkspaceChanges {
+		ws.Changes, ws.Truncated = ws.Changes[:maxWorkspaceChanges], true
+	}
+	if ws.Changes == nil {
+		ws.Changes = []WorkspaceChange{}
+	}
+	return ws, nil
+}
+
+// newToolWorkspace creates the overlay workspace a call asks for, or returns nil
+func (e *Executor) newToolWorkspace(opts *ExecuteOptions) (*Workspace, error) {
+	if opts == nil || opts.Workspace != plugin.WorkspaceOverlay {
+		return nil, nil
+	}
+	cfg := bwrapConfig(opts)
+	if cfg == nil {
+		cfg = &plugin.BwrapConfig{}
+	}
+	if cfg.ReadonlyRoot {
+		return nil, fmt.Errorf("an overlay workspace cannot be used with a read-only root")
+	}
+	mounts, err := e.sandbox.toolMounts(cfg)
+	if err != nil {
+		return nil, err
+	}
+	mountPoints := append([]string{"/dev", "/tmp"}, namespaceSystemDirs...)
+	for _, m := range mounts {
+		mountPoints = append(mountPoints, m.Dest)
+	}
+	return newWorkspace(e.sandbox.RootDir(), append(mountPoints, cfg.Tmpfs...))
+}
@@ -0,0 +1,30 @@
+package executor
+
+import (
+	"io/fs"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// isWhiteout reports whether an upper directory entry marks a deleted file: a character
+// device with device number 0
+func isWhiteout(info fs.FileInfo) bool {
+	if info.Mode()&fs.ModeCharDevice == 0 {
+		return false
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	return ok && st.Rdev == 0
+}
+
+// isOpaqueDir reports whether an upper directory hides the root directory's entries
+// below it. Overlays mounted in a user namespace use the user.* xattr.
+func isOpaqueDir(dir string) bool {
+	for _, attr := range []string{"user.overlay.opaque", "trusted.overlay.opaque"} {
+		buf := make([]byte, 1)
+		if n, err := unix.Lgetxattr(dir, attr, buf); err == nil && n == 1 && buf[0] == 'y' {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+
+package executor
+
+import "io/fs"
+
+// isWhiteout reports false; overlay workspaces need Linux
+func isWhiteout(info fs.FileInfo) bool {
+	return false
+}
+
+// isOpaqueDir reports false; overlay workspaces need Linux
+func isOpaqueDir(dir string) bool {
+	return false
+}
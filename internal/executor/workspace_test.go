@@ -0,0 +1,137 @@
+package executor
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWorkspace_Changes(t *testing.T) {
+	rootDir := t.TempDir()
+	write := func(t *testing.T, dir, name, content string) {
+		t.Helper()
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(t, rootDir, "same.txt", "same")
+	write(t, rootDir, "changed.txt", "old")
+	write(t, rootDir, "dir/old.txt", "old")
+
+	ws, err := newWorkspace(rootDir, []string{"/data/cache", "/etc/hosts"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+	upper := ws.upperDir()
+	// Copied up without a change, changed, added, and mount points
+	write(t, upper, "same.txt", "same")
+	write(t, upper, "changed.txt", "new")
+	write(t, upper, "dir/new.txt", "new")
+	write(t, upper, "etc/hosts", "")
+	if err := os.MkdirAll(filepath.Join(upper, "data", "cache"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("same.txt", filepath.Join(upper, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := ws.Changes()
+	if err != nil {
+		t.Fatalf("Changes() error = %v", err)
+	}
+	want := []WorkspaceChange{
+		{Path: "/changed.txt", Kind: WorkspaceModified},
+		{Path: "/dir/new.txt", Kind: WorkspaceAdded},
+		{Path: "/link", Kind: WorkspaceAdded},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Fatalf("Changes() = %v, want %v", changes, want)
+	}
+
+	if err := ws.Commit(changes); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	for name, content := range map[string]string{"changed.txt": "new", "dir/new.txt": "new", "dir/old.txt": "old", "link": "same"} {
+		if data, err := os.ReadFile(filepath.Join(rootDir, name)); err != nil || string(data) != content {
+			t.Errorf("%s = %q, %v, want %q", name, data, err, content)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(rootDir, "data")); !os.IsNotExist(err) {
+		t.Errorf("expected mount points not committed, got %v", err)
+	}
+
+	dir := ws.dir
+	if err := ws.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("expected the workspace removed, got %v", err)
+	}
+}
+
+func TestWorkspace_CommitOutsideRoot(t *testing.T) {
+	rootDir := t.TempDir()
+	outside := t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(rootDir, "escape")); err != nil {
+		t.Fatal(err)
+	}
+	ws, err := newWorkspace(rootDir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+	if err := os.MkdirAll(filepath.Join(ws.upperDir(), "escape"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(ws.upperDir(), "escape", "x"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ws.Commit([]WorkspaceChange{{Path: "/escape/x", Kind: WorkspaceAdded}}); err == nil {
+		t.Error("expected an error committing through a symlink out of the root directory")
+	}
+	if _, err := os.Stat(filepath.Join(outside, "x")); !os.IsNotExist(err) {
+		t.Errorf("expected nothing written outside the root directory, got %v", err)
+	}
+}
+
+func TestSameContent(t *testing.T) {
+	big := bytes.Repeat([]byte("0123456789"), 10000) // Several chunks
+	changed := append([]byte{}, big...)
+	changed[len(changed)-1] = 'x'
+
+	tests := []struct {
+		name string
+		a, b []byte
+		want bool
+	}{
+		{"empty", nil, nil, true},
+		{"same small", []byte("abc"), []byte("abc"), true},
+		{"same large", big, big, true},
+		{"last byte differs", big, changed, false},
+		{"prefix", big, big[:len(big)-1], false},
+	}
+
+	dir := t.TempDir()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := filepath.Join(dir, "a"), filepath.Join(dir, "b")
+			if err := os.WriteFile(a, tt.a, 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(b, tt.b, 0644); err != nil {
+				t.Fatal(err)
+			}
+			if got := sameContent(a, b); got != tt.want {
+				t.Errorf("sameContent() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package mcp
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"unicode/utf8"
+
+	"github.com/takeshy/mcp-gatekeeper/internal/db"
+	"github.com/takeshy/mcp-gatekeeper/internal/plugin"
+	"github.com/takeshy/mcp-gatekeeper/internal/policy"
+)
+
+// stdinAuditPreviewBytes is how much of a tool's stdin is kept in the audit log
+const stdinAuditPreviewBytes = 256
+
+// contentAuditRecord replaces stdin and file arguments in audit logs
+type contentAuditRecord struct {
+	Name      string `json:"name,omitempty"` // File name (file arguments)
+	Bytes     int    `json:"bytes"`
+	SHA256    string `json:"sha256"`
+	Preview   string `json:"preview,omitempty"` // Leading text (stdin)
+	Truncated bool   `json:"truncated,omitempty"`
+}
+
+// newContentAuditRecord describes content by its size and SHA-256 hash
+func newContentAuditRecord(data []byte) contentAuditRecord {
+	sum := sha256.Sum256(data)
+	return contentAuditRecord{Bytes: len(data), SHA256: hex.EncodeToString(sum[:])}
+}
+
+// auditParams returns the request params to record in the audit log. In tools/call
+// arguments, stdin is replaced by its size, SHA-256 hash and a truncated preview, and
+// the file arguments of tool (which may be nil) by their name, size and hash.
+func auditParams(params interface{}, tool *plugin.Tool) interface{} {
+	raw, ok := params.(json.RawMessage)
+	hasFiles := tool != nil && tool.HasFileParameters()
+	if !ok || (!hasFiles && !bytes.Contains(raw, []byte(`"stdin"`))) {
+		return params
+	}
+
+	var decoded map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(&decoded); err != nil {
+		return params
+	}
+	arguments, _ := decoded["arguments"].(map[string]interface{})
+	if arguments == nil {
+		return params
+	}
+
+	changed := false
+	if stdin, ok := arguments["stdin"].(string); ok {
+		record := newContentAuditRecord([]byte(stdin))
+		record.Preview = stdin
+		if len(stdin) > stdinAuditPreviewBytes {
+			cut := stdinAuditPreviewBytes
+			for cut > 0 && !utf8.RuneStart(stdin[cut]) {
+				cut--
+			}
+			record.Preview, record.Truncated = stdin[:cut], true
+		}
+		arguments["stdin"] = record
+		changed = true
+	}
+	if hasFiles {
+		for i := range tool.Parameters {
+			p := &tool.Parameters[i]
+			raw, ok := arguments[p.Name]
+			if p.Type != plugin.ParameterTypeFile || !ok {
+				continue
+			}
+			arguments[p.Name] = fileAuditRecord(p, raw)
+			changed = true
+		}
+	}
+	if !changed {
+		return params
+	}
+	return decoded
+}
+
+// fileAuditRecord describes a file argument; one that cannot be decoded is hashed as given
+func fileAuditRecord(p *plugin.Parameter, raw interface{}) contentAuditRecord {
+	if file, err := p.DecodeFile(raw); err == nil {
+		record := newContentAuditRecord(file.Data)
+		record.Name = file.Name
+		return record
+	}
+	data, _ := json.Marshal(raw)
+	return newContentAuditRecord(data)
+}
+
+// auditBodies returns the params and response to record in the audit log for a call to
+// tool (which may be nil); a tool with audit_bodies "hash" has both reduced to digests
+func auditBodies(params interface{}, resp *Response, tool *plugin.Tool) (interface{}, interface{}) {
+	if tool != nil && tool.HashesAuditBodies() {
+		return db.NewAuditDigest(params), db.NewAuditDigest(resp)
+	}
+	return auditParams(params, tool), resp
+}
+
+// setAuditSecrets redacts from the audit log the values of the environment variables
+// tools may see whose names the database's redact configuration selects, since tools
+// such as env can print them
+func setAuditSecrets(database *db.DB, evaluator *policy.Evaluator, plugins *plugin.Config) {
+	if database == nil {
+		return
+	}
+	redactKeys, minLength := database.AuditEnvRedaction()
+	var values []string
+	// Unlike allowed_env_keys, an empty selection matches nothing
+	if len(redactKeys) > 0 {
+		keys := evaluator.FilterEnvKeys(plugins.AllowedEnvKeys, getEnvKeys(os.Environ()))
+		for _, key := range evaluator.FilterEnvKeys(redactKeys, keys) {
+			if value := os.Getenv(key); len(value) >= minLength {
+				values = append(values, value)
+			}
+		}
+	}
+	database.SetAuditRedactValues(values)
+}
@@ -0,0 +1,163 @@
+package mcp
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/takeshy/mcp-gatekeeper/internal/db"
+	"github.com/takeshy/mcp-gatekeeper/internal/plugin"
+	"github.com/takeshy/mcp-gatekeeper/internal/policy"
+)
+
+func TestAuditParams(t *testing.T) {
+	t.Run("no stdin", func(t *testing.T) {
+		raw := json.RawMessage(`{"name": "ls", "arguments": {"args": ["-l"]}}`)
+		if got, ok := auditParams(raw, nil).(json.RawMessage); !ok || string(got) != string(raw) {
+			t.Errorf("expected params unchanged, got %v", got)
+		}
+	})
+
+	t.Run("stdin", func(t *testing.T) {
+		raw := json.RawMessage(`{"name": "jq", "arguments": {"stdin": "hello", "count": 12345678901234567890}}`)
+		data, err := json.Marshal(auditParams(raw, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := `{"arguments":{"count":12345678901234567890,"stdin":{"bytes":5,"sha256":"2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824","preview":"hello"}},"name":"jq"}`
+		if string(data) != want {
+			t.Errorf("auditParams() = %s, want %s", data, want)
+		}
+	})
+
+	t.Run("truncated preview", func(t *testing.T) {
+		stdin := strings.Repeat("a", stdinAuditPreviewBytes-1) + "é" + "tail"
+		raw, _ := json.Marshal(map[string]interface{}{"name": "cat", "arguments": map[string]interface{}{"stdin": stdin}})
+		params, ok := auditParams(json.RawMessage(raw), nil).(map[string]interface{})
+		if !ok {
+			t.Fatal("expected params to be rewritten")
+		}
+		record := params["arguments"].(map[string]interface{})["stdin"].(contentAuditRecord)
+		if record.Bytes != len(stdin) || !record.Truncated || record.Preview != strings.Repeat("a", stdinAuditPreviewBytes-1) {
+			t.Errorf("unexpected record %+v", record)
+		}
+	})
+	t.Run("file arguments", func(t *testing.T) {
+		tool := &plugin.Tool{Name: "patch", Parameters: []plugin.Parameter{{Name: "diff", Type: plugin.ParameterTypeFile}}}
+		raw := json.RawMessage(`{"name": "patch", "arguments": {"diff": {"type": "resource", "resource": {"uri": "file:///fix.diff", "text": "hello"}}}}`)
+		data, err := json.Marshal(auditParams(raw, tool))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := `{"arguments":{"diff":{"name":"fix.diff","bytes":5,"sha256":"2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"}},"name":"patch"}`
+		if string(data) != want {
+			t.Errorf("auditParams() = %s, want %s", data, want)
+		}
+	})
+}
+
+func TestAuditBodies(t *testing.T) {
+	raw := json.RawMessage(`{"name":"vault","arguments":{"stdin":"hello"}}`)
+	resp := &Response{JSONRPC: "2.0", Result: map[string]string{"secret": "value"}}
+
+	t.Run("full", func(t *testing.T) {
+		params, response := auditBodies(raw, resp, &plugin.Tool{Name: "vault"})
+		if _, ok := params.(map[string]interface{}); !ok {
+			t.Errorf("expected stdin to be replaced, got %T", params)
+		}
+		if response != resp {
+			t.Errorf("expected the response unchanged, got %v", response)
+		}
+	})
+
+	t.Run("hash", func(t *testing.T) {
+		params, response := auditBodies(raw, resp, &plugin.Tool{Name: "vault", AuditBodies: plugin.AuditBodiesHash})
+		p, ok := params.(*db.AuditDigest)
+		if !ok || p.Bytes != len(raw) {
+			t.Errorf("expected a digest of the params, got %v", params)
+		}
+		if r, ok := response.(*db.AuditDigest); !ok || r.SHA256 == p.SHA256 {
+			t.Errorf("expected a digest of the response, got %v", response)
+		}
+	})
+}
+
+func TestSetAuditSecrets(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "test-audit-secrets-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpFile.Close()
+	defer os.Remove(tmpFile.Name())
+
+	database, err := db.Open(tmpFile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+
+	t.Setenv("GATEKEEPER_TEST_GH_TOKEN", "token-value-1234")
+	t.Setenv("GATEKEEPER_TEST_DB_PASSWORD", "password-value")
+	t.Setenv("GATEKEEPER_TEST_HOME", "/home/someone")
+	t.Setenv("GATEKEEPER_TEST_DENIED_SECRET", "denied-value-1234")
+	t.Setenv("GATEKEEPER_TEST_SHORT_KEY", "short")
+
+	output := "token-value-1234 password-value /home/someone denied-value-1234 short"
+	tests := []struct {
+		name      string
+		allowed   []string // allowed_env_keys; empty lets tools see every variable
+		keys      []string
+		minLength int
+		want      string
+	}{
+		{
+			// Only names that look like secrets, down to the default minimum length
+			name:      "default",
+			keys:      db.DefaultAuditEnvKeys,
+			minLength: db.DefaultAuditEnvMinLength,
+			want:      `"[REDACTED] [REDACTED] /home/someone [REDACTED] short"`,
+		},
+		{
+			name:      "env keys and minimum length",
+			keys:      []string{"*_HOME", "*_SHORT_KEY"},
+			minLength: 1,
+			want:      `"token-value-1234 password-value [REDACTED] denied-value-1234 [REDACTED]"`,
+		},
+		{
+			// Variables tools cannot see are not looked at
+			name:      "allowed env keys",
+			allowed:   []string{"GATEKEEPER_TEST_GH_TOKEN"},
+			keys:      db.DefaultAuditEnvKeys,
+			minLength: db.DefaultAuditEnvMinLength,
+			want:      `"[REDACTED] password-value /home/someone denied-value-1234 short"`,
+		},
+		{
+			name:      "disabled",
+			keys:      nil,
+			minLength: 1,
+			want:      `"token-value-1234 password-value /home/someone denied-value-1234 short"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			database.SetAuditEnvRedaction(tt.keys, tt.minLength)
+			plugins := &plugin.Config{AllowedEnvKeys: tt.allowed}
+			setAuditSecrets(database, policy.NewEvaluator(), plugins)
+			setAuditSecrets(nil, policy.NewEvaluator(), plugins)
+
+			if err := database.LogAudit(db.AuditModeStdio, nil, "tools/call", "env", nil, output, nil, time.Now()); err != nil {
+				t.Fatal(err)
+			}
+			entries, err := database.ListAuditLogs("", 1, 0)
+			if err != nil || len(entries) != 1 {
+				t.Fatalf("expected an entry, got %d (%v)", len(entries), err)
+			}
+			if entries[0].Response != tt.want {
+				t.Errorf("Response = %s, want %s", entries[0].Response, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// CancelledParams represents params for notifications/cancelled
+type CancelledParams struct {
+	RequestID json.RawMessage `json:"requestId"`
+	Reason    string          `json:"reason,omitempty"`
+}
+
+// errRequestCancelled is the cancellation cause when the client gives no reason
+var errRequestCancelled = errors.New("request cancelled by client")
+
+// errRequestInFlight is returned by start when a request with the same id is still running
+var errRequestInFlight = errors.New("request id is already in flight")
+
+// errSessionTerminated is the cancellation cause for calls still running when their session is deleted
+var errSessionTerminated = errors.New("session terminated")
+
+// inflightKey identifies an in-flight request within a scope (a session, or "" for stdio)
+type inflightKey struct {
+	scope string
+	id    string
+}
+
+// inflightRequests tracks cancellable in-flight requests by JSON-RPC id
+type inflightRequests struct {
+	mu    sync.Mutex
+	calls map[inflightKey]context.CancelCauseFunc
+}
+
+func newInflightRequests() *inflightRequests {
+	return &inflightRequests{calls: make(map[inflightKey]context.CancelCauseFunc)}
+}
+
+// start registers a request and returns its cancellable context. done must be
+// called when the request finishes. A request whose id is already in flight is refused,
+// so that neither call can cancel or untrack the other.
+func (t *inflightRequests) start(ctx context.Context, scope string, id json.RawMessage) (context.Context, func(), error) {
+	key := inflightKey{scope: scope, id: string(id)}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, exists := t.calls[key]; exists {
+		return nil, nil, fmt.Errorf("%w: %s", errRequestInFlight, id)
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	t.calls[key] = cancel
+
+	return ctx, func() {
+		t.mu.Lock()
+		delete(t.calls, key)
+		t.mu.Unlock()
+		cancel(nil)
+	}, nil
+}
+
+// cancel cancels the in-flight request named by a notifications/cancelled message.
+// It reports whether a matching request was found.
+func (t *inflightRequests) cancel(scope string, rawParams json.RawMessage) bool {
+	var params CancelledParams
+	if err := json.Unmarshal(rawParams, &params); err != nil || len(params.RequestID) == 0 {
+		return false
+	}
+
+	t.mu.Lock()
+	cancel, ok := t.calls[inflightKey{scope: scope, id: string(params.RequestID)}]
+	t.mu.Unlock()
+	if !ok {
+		return false
+	}
+
+	cause := errRequestCancelled
+	if params.Reason != "" {
+		cause = fmt.Errorf("%w: %s", errRequestCancelled, params.Reason)
+	}
+	cancel(cause)
+	return true
+}
+
+// cancelScope cancels every in-flight request in a scope, e.g. when its session ends
+func (t *inflightRequests) cancelScope(scope string, cause error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for key, cancel := range t.calls {
+		if key.scope == scope {
+			cancel(cause)
+		}
+	}
+}
+
+// cancellationError returns the error recorded for a cancelled execution
+func cancellationError(ctx context.Context) error {
+	if cause := context.Cause(ctx); cause != nil {
+		return cause
+	}
+	return errRequestCancelled
+}
@@ -0,0 +1,83 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestInflightRequests(t *testing.T) {
+	tracker := newInflightRequests()
+
+	ctx1, done1, err := tracker.start(context.Background(), "sess-a", json.RawMessage(`1`))
+	if err != nil {
+		t.Fatalf("start() error = %v", err)
+	}
+	defer done1()
+	ctx2, done2, err := tracker.start(context.Background(), "sess-b", json.RawMessage(`1`))
+	if err != nil {
+		t.Fatalf("start() error = %v", err)
+	}
+	defer done2()
+	ctx3, done3, err := tracker.start(context.Background(), "sess-a", json.RawMessage(`"2"`))
+	if err != nil {
+		t.Fatalf("start() error = %v", err)
+	}
+	defer done3()
+
+	tests := []struct {
+		name   string
+		scope  string
+		params string
+		want   bool
+	}{
+		{"invalid params", "sess-a", `{`, false},
+		{"missing request id", "sess-a", `{"reason": "x"}`, false},
+		{"unknown id", "sess-a", `{"requestId": 3}`, false},
+		{"string id does not match number", "sess-a", `{"requestId": "1"}`, false},
+		{"other session", "sess-c", `{"requestId": 1}`, false},
+		{"matching request", "sess-a", `{"requestId": 1, "reason": "user gave up"}`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tracker.cancel(tt.scope, json.RawMessage(tt.params)); got != tt.want {
+				t.Errorf("cancel() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if ctx1.Err() == nil {
+		t.Fatal("expected cancelled request context to be done")
+	}
+	if err := cancellationError(ctx1); !errors.Is(err, errRequestCancelled) || err.Error() != "request cancelled by client: user gave up" {
+		t.Errorf("cancellationError() = %v", err)
+	}
+	if ctx2.Err() != nil || ctx3.Err() != nil {
+		t.Error("expected other requests to keep running")
+	}
+
+	tracker.cancelScope("sess-a", errSessionTerminated)
+	if !errors.Is(cancellationError(ctx3), errSessionTerminated) {
+		t.Errorf("expected session terminated cause, got %v", cancellationError(ctx3))
+	}
+	if ctx2.Err() != nil {
+		t.Error("expected request in another session to keep running")
+	}
+
+	// A request id cannot be reused while it is in flight
+	if _, _, err := tracker.start(context.Background(), "sess-b", json.RawMessage(`1`)); !errors.Is(err, errRequestInFlight) {
+		t.Errorf("start() error = %v, want errRequestInFlight", err)
+	}
+
+	// Finished requests can no longer be cancelled, and their id is free again
+	done2()
+	if tracker.cancel("sess-b", json.RawMessage(`{"requestId": 1}`)) {
+		t.Error("expected finished request to be untracked")
+	}
+	if _, done, err := tracker.start(context.Background(), "sess-b", json.RawMessage(`1`)); err != nil {
+		t.Errorf("start() error = %v after the request finished", err)
+	} else {
+		done()
+	}
+}
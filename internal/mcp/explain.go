@@ -0,0 +1,111 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/takeshy/mcp-gatekeeper/internal/executor"
+	"github.com/takeshy/mcp-gatekeeper/internal/plugin"
+	"github.com/takeshy/mcp-gatekeeper/internal/policy"
+)
+
+// ExplainMethod is the JSON-RPC method that evaluates a tools/call request without executing it
+const ExplainMethod = "gatekeeper/explain"
+
+var (
+	// ErrToolNotFound is returned by Explain when the tool does not exist
+	ErrToolNotFound = errors.New("tool not found")
+	// ErrInvalidArguments is returned by Explain when the arguments cannot be parsed
+	ErrInvalidArguments = errors.New("invalid arguments")
+)
+
+// ExplainResult describes how a tools/call request would be handled
+type ExplainResult struct {
+	Tool       string           `json:"tool"`
+	Allowed    bool             `json:"allowed"`
+	Reason     string           `json:"reason"`
+	Command    string           `json:"command,omitempty"`
+	Argv       []string         `json:"argv"` // args_prefix followed by the user arguments
+	Cwd        string           `json:"cwd"`
+	EnvKeys    []string         `json:"env_keys"` // Environment variables passed to the command (names only)
+	Sandbox    string           `json:"sandbox"`  // Effective isolation, as reported by executor.ToolIsolation
+	WasmBinary string           `json:"wasm_binary,omitempty"`
+	Args       *policy.Decision `json:"args"`
+	Paths      *policy.Decision `json:"paths,omitempty"` // Omitted when the arguments are denied
+}
+
+// Explain evaluates a tools/call request against the policy, recording every pattern tried.
+// Nothing is executed.
+func Explain(evaluator *policy.Evaluator, exec *executor.Executor, plugins *plugin.Config, rootDir string, params *CallToolParams) (*ExplainResult, error) {
+	tool := plugins.GetTool(params.Name)
+	if tool == nil {
+		return nil, fmt.Errorf("%w: %s", ErrToolNotFound, params.Name)
+	}
+
+	cwd, cmdArgs, err := parseToolArguments(tool, params.Arguments, executor.ExplainInputFiles(tool))
+	if err == nil {
+		_, err = parseToolStdin(tool, params.Arguments)
+	}
+	if err == nil {
+		cwd, err = resolveCwd(exec, rootDir, cwd)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArguments, err)
+	}
+
+	result := &ExplainResult{
+		Tool:       tool.Name,
+		Command:    tool.Command,
+		Argv:       append(append([]string{}, tool.ArgsPrefix...), cmdArgs...),
+		Cwd:        cwd,
+		EnvKeys:    evaluator.FilterEnvKeys(plugins.AllowedEnvKeys, getEnvKeys(os.Environ())),
+		Sandbox:    exec.ToolIsolation(tool),
+		WasmBinary: tool.WasmBinary,
+	}
+	if result.EnvKeys == nil {
+		result.EnvKeys = []string{}
+	}
+
+	result.Args, err = evaluator.ExplainArgs(tool, cmdArgs)
+	if err != nil {
+		return nil, fmt.Errorf("policy evaluation failed: %w", err)
+	}
+	result.Allowed, result.Reason = result.Args.Allowed, result.Args.Reason
+	if !result.Allowed {
+		return result, nil
+	}
+
+	result.Paths, err = evaluator.ExplainPaths(tool, rootDir, cwd, cmdArgs)
+	if err != nil {
+		return nil, fmt.Errorf("policy evaluation failed: %w", err)
+	}
+	if !result.Paths.Allowed {
+		result.Allowed, result.Reason = false, result.Paths.Reason
+	}
+	return result, nil
+}
+
+// explainResponse handles a gatekeeper/explain request.
+// toolAllowed, if set, restricts the tools the caller may explain.
+func explainResponse(evaluator *policy.Evaluator, exec *executor.Executor, plugins *plugin.Config, rootDir string, req *Request, toolAllowed func(string) bool) (*Response, string, error) {
+	var params CallToolParams
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return NewErrorResponse(req.ID, InvalidParams, "Invalid params", err.Error()), "", err
+	}
+	if toolAllowed != nil && !toolAllowed(params.Name) {
+		return NewErrorResponse(req.ID, Unauthorized, "Insufficient scope", params.Name), params.Name, fmt.Errorf("insufficient scope for tool: %s", params.Name)
+	}
+
+	result, err := Explain(evaluator, exec, plugins, rootDir, &params)
+	switch {
+	case errors.Is(err, ErrToolNotFound):
+		return NewErrorResponse(req.ID, MethodNotFound, "Tool not found", params.Name), params.Name, err
+	case errors.Is(err, ErrInvalidArguments):
+		return NewErrorResponse(req.ID, InvalidParams, "Invalid arguments", err.Error()), params.Name, err
+	case err != nil:
+		return NewErrorResponse(req.ID, InternalError, "Policy evaluation failed", err.Error()), params.Name, err
+	}
+	return NewResponse(req.ID, result), params.Name, nil
+}
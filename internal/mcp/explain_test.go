@@ -0,0 +1,115 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/takeshy/mcp-gatekeeper/internal/executor"
+	"github.com/takeshy/mcp-gatekeeper/internal/plugin"
+	"github.com/takeshy/mcp-gatekeeper/internal/policy"
+)
+
+func newExplainTestConfig() *plugin.Config {
+	return &plugin.Config{Tools: map[string]*plugin.Tool{
+		"git-log": {
+			Name:            "git-log",
+			Command:         "git",
+			ArgsPrefix:      []string{"log"},
+			AllowedArgGlobs: []string{"--oneline", "-n *"},
+			DeniedArgGlobs:  []string{"*--exec*"},
+			Sandbox:         plugin.SandboxTypeNone,
+		},
+	}}
+}
+
+func TestExplain(t *testing.T) {
+	plugins := newExplainTestConfig()
+	evaluator := policy.NewEvaluator()
+	exec := executor.NewExecutor(&executor.ExecutorConfig{RootDir: "/tmp"})
+
+	result, err := Explain(evaluator, exec, plugins, "/tmp", &CallToolParams{
+		Name:      "git-log",
+		Arguments: map[string]interface{}{"args": []interface{}{"-n", "5"}},
+	})
+	if err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+	if !result.Allowed {
+		t.Errorf("Explain() Allowed = false, reason = %s", result.Reason)
+	}
+	if want := []string{"log", "-n", "5"}; !reflect.DeepEqual(result.Argv, want) {
+		t.Errorf("Explain() Argv = %q, want %q", result.Argv, want)
+	}
+	if result.Cwd != "/tmp" || result.Sandbox != "none" || result.Paths == nil {
+		t.Errorf("Explain() = %+v", result)
+	}
+	// Deny pattern, then the two allow patterns in order
+	if len(result.Args.Trace) != 3 {
+		t.Fatalf("Explain() trace = %+v, want 3 checks", result.Args.Trace)
+	}
+	if tr := result.Args.Trace[1]; tr.Rule != "arg_allow" || tr.Pattern != "--oneline" || tr.Matched {
+		t.Errorf("Explain() trace[1] = %+v", tr)
+	}
+
+	result, err = Explain(evaluator, exec, plugins, "/tmp", &CallToolParams{
+		Name:      "git-log",
+		Arguments: map[string]interface{}{"args": []interface{}{"--exec=sh"}},
+	})
+	if err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+	if result.Allowed || result.Paths != nil {
+		t.Errorf("Explain() = %+v, want denied without path evaluation", result)
+	}
+
+	if _, err := Explain(evaluator, exec, plugins, "/tmp", &CallToolParams{Name: "missing"}); !errors.Is(err, ErrToolNotFound) {
+		t.Errorf("Explain() error = %v, want ErrToolNotFound", err)
+	}
+
+	// cwd is normalized and confined to the root directory like in tools/call
+	result, err = Explain(evaluator, exec, plugins, "/tmp", &CallToolParams{
+		Name:      "git-log",
+		Arguments: map[string]interface{}{"cwd": "/tmp/./", "args": []interface{}{"--oneline"}},
+	})
+	if err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+	if result.Cwd != "/tmp" {
+		t.Errorf("Explain() Cwd = %q, want /tmp", result.Cwd)
+	}
+	if _, err := Explain(evaluator, exec, plugins, "/tmp", &CallToolParams{
+		Name:      "git-log",
+		Arguments: map[string]interface{}{"cwd": "/etc", "args": []interface{}{"--oneline"}},
+	}); !errors.Is(err, ErrInvalidArguments) {
+		t.Errorf("Explain() error = %v, want ErrInvalidArguments for a cwd outside the root", err)
+	}
+}
+
+func TestStdioServer_Explain(t *testing.T) {
+	s, err := NewStdioServer(newExplainTestConfig(), "", "", "/tmp", "", nil)
+	if err != nil {
+		t.Fatalf("NewStdioServer() error = %v", err)
+	}
+	request := []byte(`{"jsonrpc": "2.0", "id": 1, "method": "gatekeeper/explain", "params": {"name": "git-log", "arguments": {"args": ["--oneline"]}}}`)
+
+	// Disabled by default
+	resp, err := s.handleMessage(context.Background(), request)
+	if err != nil {
+		t.Fatalf("handleMessage() error = %v", err)
+	}
+	if resp.Error == nil || resp.Error.Code != MethodNotFound {
+		t.Fatalf("expected MethodNotFound when explain is disabled, got %+v", resp)
+	}
+
+	s.EnableExplain()
+	resp, err = s.handleMessage(context.Background(), request)
+	if err != nil {
+		t.Fatalf("handleMessage() error = %v", err)
+	}
+	result, ok := resp.Result.(*ExplainResult)
+	if !ok || !result.Allowed {
+		t.Errorf("unexpected explain response: %+v", resp)
+	}
+}
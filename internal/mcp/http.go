@@ -9,6 +9,7 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -65,16 +66,23 @@ func (r *RateLimiter) Allow() bool {
 
 // HTTPServer implements the HTTP API server
 type HTTPServer struct {
-	plugins           *plugin.Config
+	plugins           atomic.Pointer[plugin.Config]
 	evaluator         *policy.Evaluator
 	executor          *executor.Executor
 	rateLimiter       *RateLimiter
+	rateLimitWindow   time.Duration
+	keyLimiters       map[int64]*RateLimiter // Per-key rate limiters for named API keys
+	keyLimitersMu     sync.Mutex
 	router            chi.Router
 	rootDir           string
 	expectedAPIKey    string              // Expected API key for authentication
 	db                *db.DB              // Optional database for audit logging
+	apiKeys           bool                // Authenticate named API keys from the database
 	oauthHandler      *oauth.Handler      // Optional OAuth handler
 	streamableHandler *StreamableHandler // Optional streamable HTTP handler
+	listChanged       bool               // Advertise and send notifications/tools/list_changed
+	explain           bool               // Serve gatekeeper/explain
+	requestTimeout    time.Duration      // Longest time a request may take
 }
 
 // HTTPConfig holds HTTP server configuration
@@ -83,12 +91,17 @@ type HTTPConfig struct {
 	RateLimitWindow  time.Duration
 	RootDir          string
 	WasmDir          string
-	APIKey           string        // Expected API key for authentication (optional)
-	DB               *db.DB        // Optional database for audit logging
-	EnableOAuth      bool          // Enable OAuth authentication (requires DB)
-	OAuthIssuer      string        // OAuth issuer URL (optional, auto-detected if empty)
-	EnableStreamable bool          // Enable MCP Streamable HTTP (2025-06-18)
-	SessionTTL       time.Duration // Session TTL for Streamable HTTP (default 30 minutes)
+	APIKey           string          // Expected API key for authentication (optional)
+	DB               *db.DB          // Optional database for audit logging
+	EnableAPIKeys    bool            // Enable named API keys (requires DB)
+	EnableOAuth      bool            // Enable OAuth authentication (requires DB)
+	OAuthIssuer      string          // OAuth issuer URL (optional, auto-detected if empty)
+	EnableStreamable bool            // Enable MCP Streamable HTTP (2025-06-18)
+	SessionTTL       time.Duration   // Session TTL for Streamable HTTP (default 30 minutes)
+	WatchPlugins     bool            // Plugins may be reloaded via SetPlugins (advertises tools listChanged)
+	EnableExplain    bool            // Serve the gatekeeper/explain policy dry-run method
+	Limits           executor.Limits // Execution limits (zero fields use the executor defaults; zero MaxConcurrency is unlimited)
+	BindPaths        []string        // Host paths outside RootDir that tool bwrap binds may use
 }
 
 // DefaultHTTPConfig returns the default HTTP configuration
@@ -100,6 +113,20 @@ func DefaultHTTPConfig() *HTTPConfig {
 	}
 }
 
+// minRequestTimeout is the HTTP request timeout when tool timeouts are short
+const minRequestTimeout = 60 * time.Second
+
+// RequestTimeout returns how long an HTTP request may take so that a tool call
+// queued for up to the queue timeout and then running up to the timeout ceiling
+// (0 = executor default) can still respond
+func RequestTimeout(limits executor.Limits) time.Duration {
+	timeoutCeiling := limits.TimeoutCeiling
+	if timeoutCeiling <= 0 {
+		timeoutCeiling = executor.DefaultTimeoutCeiling
+	}
+	return max(minRequestTimeout, limits.QueueTimeout+timeoutCeiling+10*time.Second)
+}
+
 // NewHTTPServer creates a new HTTP server
 func NewHTTPServer(plugins *plugin.Config, config *HTTPConfig) (*HTTPServer, error) {
 	if config == nil {
@@ -107,21 +134,34 @@ func NewHTTPServer(plugins *plugin.Config, config *HTTPConfig) (*HTTPServer, err
 	}
 
 	execConfig := &executor.ExecutorConfig{
-		Timeout:   executor.DefaultTimeout,
-		MaxOutput: executor.DefaultMaxOutput,
-		RootDir:   config.RootDir,
-		WasmDir:   config.WasmDir,
+		Timeout:        config.Limits.Timeout,
+		MaxOutput:      config.Limits.MaxOutput,
+		TimeoutCeiling: config.Limits.TimeoutCeiling,
+		OutputCeiling:  config.Limits.OutputCeiling,
+		MaxConcurrency: config.Limits.MaxConcurrency,
+		MaxQueue:       config.Limits.MaxQueue,
+		QueueTimeout:   config.Limits.QueueTimeout,
+		RootDir:        config.RootDir,
+		WasmDir:        config.WasmDir,
+		BindPaths:      config.BindPaths,
 	}
 
 	s := &HTTPServer{
-		plugins:        plugins,
 		evaluator:      policy.NewEvaluator(),
 		executor:       executor.NewExecutor(execConfig),
 		rateLimiter:    NewRateLimiter(config.RateLimit, config.RateLimitWindow),
+		rateLimitWindow: config.RateLimitWindow,
+		keyLimiters:    make(map[int64]*RateLimiter),
 		rootDir:        config.RootDir,
 		expectedAPIKey: config.APIKey,
 		db:             config.DB,
+		apiKeys:        config.EnableAPIKeys && config.DB != nil,
+		listChanged:    config.WatchPlugins,
+		explain:        config.EnableExplain,
+		requestTimeout: RequestTimeout(config.Limits),
 	}
+	s.plugins.Store(plugins)
+	setAuditSecrets(s.db, s.evaluator, plugins)
 
 	// Initialize OAuth handler if enabled and DB is available
 	if config.EnableOAuth && config.DB != nil {
@@ -147,7 +187,7 @@ func (s *HTTPServer) setupRoutes() {
 	// Middleware
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
-	r.Use(middleware.Timeout(60 * time.Second))
+	r.Use(middleware.Timeout(s.requestTimeout))
 
 	// Health check
 	r.Get("/health", s.handleHealth)
@@ -193,22 +233,32 @@ func (s *HTTPServer) StopStreamable() {
 	}
 }
 
+// SetPlugins atomically replaces the plugin configuration and notifies
+// Streamable HTTP sessions that the tool list has changed
+func (s *HTTPServer) SetPlugins(plugins *plugin.Config) {
+	s.plugins.Store(plugins)
+	setAuditSecrets(s.db, s.evaluator, plugins)
+	if s.streamableHandler != nil && s.listChanged {
+		s.streamableHandler.Notify(newToolsListChangedNotification())
+	}
+}
+
 // IsStreamableEnabled returns whether Streamable HTTP is enabled
 func (s *HTTPServer) IsStreamableEnabled() bool {
 	return s.streamableHandler != nil
 }
 
-// authMiddleware handles API key and OAuth token authentication
+// authMiddleware handles API key, named API key and OAuth token authentication
 func (s *HTTPServer) authMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// If no API key is configured and OAuth is not enabled, skip authentication
-		if s.expectedAPIKey == "" && s.oauthHandler == nil {
+		// If no authentication method is configured, skip authentication
+		if s.expectedAPIKey == "" && !s.apiKeys && s.oauthHandler == nil {
 			// Still check rate limit
 			if !s.rateLimiter.Allow() {
 				s.writeError(w, http.StatusTooManyRequests, "rate limit exceeded")
 				return
 			}
-			next.ServeHTTP(w, r)
+			next.ServeHTTP(w, r.WithContext(withCaller(r.Context(), newCaller(r, "", "", nil))))
 			return
 		}
 
@@ -230,25 +280,42 @@ func (s *HTTPServer) authMiddleware(next http.Handler) http.Handler {
 		token := parts[1]
 
 		// Try API key authentication first (if configured)
-		authenticated := false
+		var c *caller
 		if s.expectedAPIKey != "" {
 			if subtle.ConstantTimeCompare([]byte(token), []byte(s.expectedAPIKey)) == 1 {
-				authenticated = true
+				c = newCaller(r, db.AuthMethodAPIKey, "api_key", nil)
 			}
 		}
 
-		// Try OAuth token authentication (if enabled and API key didn't match)
-		if !authenticated && s.oauthHandler != nil {
-			client, err := s.oauthHandler.ValidateAccessToken(r)
+		// Try named API keys (if enabled and the shared API key didn't match)
+		if c == nil && s.apiKeys {
+			key, err := s.db.ValidateAPIKey(token)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "[WARN] API key validation error: %v\n", err)
+			}
+			if key != nil {
+				if !s.allowAPIKey(key) {
+					s.writeError(w, http.StatusTooManyRequests, "rate limit exceeded")
+					return
+				}
+				// Tools are restricted to the key's allowlist
+				c = newCaller(r, db.AuthMethodAPIKey, key.Principal(), key.Scopes())
+			}
+		}
+
+		// Try OAuth token authentication (if enabled and no API key matched)
+		if c == nil && s.oauthHandler != nil {
+			client, scopes, err := s.oauthHandler.ValidateAccessToken(r)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "[WARN] OAuth token validation error: %v\n", err)
 			}
 			if client != nil {
-				authenticated = true
+				// Tools are restricted to the token's scopes
+				c = newCaller(r, db.AuthMethodOAuth, client.Principal(), scopes)
 			}
 		}
 
-		if !authenticated {
+		if c == nil {
 			s.maybeSetWWWAuthenticate(w, r)
 			s.writeError(w, http.StatusUnauthorized, "invalid credentials")
 			return
@@ -260,12 +327,37 @@ func (s *HTTPServer) authMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
-		next.ServeHTTP(w, r)
+		next.ServeHTTP(w, r.WithContext(withCaller(r.Context(), c)))
 	})
 }
 
+// allowAPIKey checks the per-key rate limit of a named API key
+func (s *HTTPServer) allowAPIKey(key *db.APIKey) bool {
+	if key.RateLimit <= 0 {
+		return true
+	}
+
+	s.keyLimitersMu.Lock()
+	limiter, ok := s.keyLimiters[key.ID]
+	if !ok || limiter.limit != key.RateLimit {
+		limiter = NewRateLimiter(key.RateLimit, s.rateLimitWindow)
+		s.keyLimiters[key.ID] = limiter
+	}
+	s.keyLimitersMu.Unlock()
+
+	return limiter.Allow()
+}
+
 func (s *HTTPServer) handleHealth(w http.ResponseWriter, r *http.Request) {
-	s.writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	isolation := make(map[string]string)
+	for _, tool := range s.plugins.Load().ListTools() {
+		isolation[tool.Name] = s.executor.ToolIsolation(tool)
+	}
+	s.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":    "ok",
+		"queue":     s.executor.QueueStats(),
+		"isolation": isolation,
+	})
 }
 
 // handleMCP handles MCP JSON-RPC requests
@@ -294,13 +386,15 @@ func (s *HTTPServer) handleMCP(w http.ResponseWriter, r *http.Request) {
 	case "initialize":
 		resp = s.handleMCPInitialize(&req)
 	case "tools/list":
-		resp = s.handleMCPToolsList(&req)
+		resp = s.handleMCPToolsList(r.Context(), &req)
 	case "tools/call":
 		resp = s.handleMCPToolsCall(r.Context(), &req)
 	case "resources/list":
-		resp = s.handleMCPResourcesList(&req)
+		resp = s.handleMCPResourcesList(r.Context(), &req)
 	case "resources/read":
-		resp = s.handleMCPResourcesRead(&req, "") // No session ID in non-streamable mode
+		resp = s.handleMCPResourcesRead(r.Context(), &req, "") // No session ID in non-streamable mode
+	case ExplainMethod:
+		resp = s.handleMCPExplain(r.Context(), &req)
 	case "ping":
 		resp = NewResponse(req.ID, struct{}{})
 	default:
@@ -379,7 +473,7 @@ func (s *HTTPServer) requestBaseURL(r *http.Request) string {
 }
 
 func (s *HTTPServer) hasUIEnabledTools() bool {
-	for _, t := range s.plugins.ListTools() {
+	for _, t := range s.plugins.Load().ListTools() {
 		if t.UIType != "" || t.UITemplate != "" {
 			return true
 		}
@@ -387,45 +481,22 @@ func (s *HTTPServer) hasUIEnabledTools() bool {
 	return false
 }
 
-func (s *HTTPServer) handleMCPToolsList(req *Request) *Response {
-	pluginTools := s.plugins.ListTools()
+func (s *HTTPServer) handleMCPToolsList(ctx context.Context, req *Request) *Response {
+	pluginTools := s.plugins.Load().ListTools()
 
 	tools := make([]Tool, 0, len(pluginTools))
 	for _, t := range pluginTools {
-		// Filter out tools that are not visible to the model
-		if !t.IsVisibleToModel() {
+		// Filter out tools that are not visible to the model or outside the token's scopes
+		if !t.IsVisibleToModel() || !toolAllowed(ctx, t.Name) {
 			continue
 		}
 
-		// Build properties based on tool configuration
-		props := map[string]Property{}
-		// Only include cwd property if the tool allows changing working directory
-		if !t.FixedCwd {
-			props["cwd"] = Property{
-				Type:        "string",
-				Description: "Working directory for the command (defaults to root directory)",
-			}
-		}
-		// Only include args property if the tool accepts arguments
-		// A tool with allowed_arg_globs = [""] means it accepts no arguments
-		noArgs := len(t.AllowedArgGlobs) == 1 && t.AllowedArgGlobs[0] == ""
-		if !noArgs {
-			props["args"] = Property{
-				Type:        "array",
-				Description: "Command arguments",
-				Items:       &Items{Type: "string"},
-			}
-		}
-
 		tools = append(tools, Tool{
-			Name:        t.Name,
-			Description: t.Description,
-			InputSchema: InputSchema{
-				Type:       "object",
-				Properties: props,
-				Required:   []string{},
-			},
-			Meta: BuildToolMeta(t),
+			Name:         t.Name,
+			Description:  t.Description,
+			InputSchema:  BuildInputSchema(t),
+			OutputSchema: BuildOutputSchema(t),
+			Meta:         BuildToolMeta(t),
 		})
 	}
 	return NewResponse(req.ID, &ListToolsResult{Tools: tools})
@@ -436,96 +507,141 @@ func (s *HTTPServer) handleMCPToolsCall(ctx context.Context, req *Request) *Resp
 	var params CallToolParams
 	if err := json.Unmarshal(req.Params, &params); err != nil {
 		resp := NewErrorResponse(req.ID, InvalidParams, "Invalid params", err.Error())
-		s.logAudit(req.Method, params.Name, req.Params, resp, err, startTime)
+		s.logAudit(ctx, req.Method, params.Name, nil, req.Params, resp, err, startTime)
+		return resp
+	}
+
+	if !toolAllowed(ctx, params.Name) {
+		fmt.Fprintf(os.Stderr, "[WARN] Tool not permitted by token scope: %s\n", params.Name)
+		resp := NewErrorResponse(req.ID, Unauthorized, "Insufficient scope", params.Name)
+		s.logAudit(ctx, req.Method, params.Name, nil, req.Params, resp, fmt.Errorf("insufficient scope for tool: %s", params.Name), startTime)
 		return resp
 	}
 
-	// Look up tool by name from plugins
-	tool := s.plugins.GetTool(params.Name)
+	// Load the plugins once, so a concurrent reload cannot mix two configurations in one call
+	plugins := s.plugins.Load()
+	tool := plugins.GetTool(params.Name)
 	if tool == nil {
 		fmt.Fprintf(os.Stderr, "[ERROR] Tool not found: %s\n", params.Name)
 		resp := NewErrorResponse(req.ID, MethodNotFound, "Tool not found", params.Name)
-		s.logAudit(req.Method, params.Name, req.Params, resp, fmt.Errorf("tool not found: %s", params.Name), startTime)
+		s.logAudit(ctx, req.Method, params.Name, nil, req.Params, resp, fmt.Errorf("tool not found: %s", params.Name), startTime)
 		return resp
 	}
 
-	// Parse arguments
-	cwd, _ := params.Arguments["cwd"].(string)
-	var cmdArgs []string
-	if argsRaw, ok := params.Arguments["args"].([]interface{}); ok {
-		for _, a := range argsRaw {
-			if str, ok := a.(string); ok {
-				cmdArgs = append(cmdArgs, str)
-			}
-		}
+	// File arguments are written to a scratch directory that lives for this call
+	inputs, err := executor.NewInputFiles(tool)
+	if err != nil {
+		resp := NewErrorResponse(req.ID, InternalError, "Failed to prepare input files", err.Error())
+		s.logAudit(ctx, req.Method, params.Name, tool, req.Params, resp, err, startTime)
+		return resp
 	}
+	defer inputs.Close()
 
-	// Default cwd to rootDir if not provided
-	if cwd == "" {
-		cwd = s.rootDir
+	// Parse and validate arguments
+	cwd, cmdArgs, err := parseToolArguments(tool, params.Arguments, inputs.Add)
+	var stdin string
+	if err == nil {
+		stdin, err = parseToolStdin(tool, params.Arguments)
+	}
+	if err == nil {
+		cwd, err = resolveCwd(s.executor, s.rootDir, cwd)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[WARN] Invalid arguments for %s: %v\n", tool.Name, err)
+		resp := NewErrorResponse(req.ID, InvalidParams, "Invalid arguments", err.Error())
+		s.logAudit(ctx, req.Method, params.Name, tool, req.Params, resp, err, startTime)
+		return resp
 	}
 
 	// Evaluate policy (check if user-provided args are allowed)
 	decision, err := s.evaluator.EvaluateArgs(tool, cmdArgs)
 	if err != nil {
 		resp := NewErrorResponse(req.ID, InternalError, "Policy evaluation failed", err.Error())
-		s.logAudit(req.Method, params.Name, req.Params, resp, err, startTime)
+		s.logAudit(ctx, req.Method, params.Name, tool, req.Params, resp, err, startTime)
 		return resp
 	}
 
 	if !decision.Allowed {
 		fmt.Fprintf(os.Stderr, "[WARN] Arguments denied by policy: %s\n", decision.Reason)
 		resp := NewErrorResponse(req.ID, PolicyDenied, "Arguments denied by policy", decision.Reason)
-		s.logAudit(req.Method, params.Name, req.Params, resp, fmt.Errorf("policy denied: %s", decision.Reason), startTime)
+		s.logAudit(ctx, req.Method, params.Name, tool, req.Params, resp, fmt.Errorf("policy denied: %s", decision.Reason), startTime)
+		return resp
+	}
+
+	// Confine declared path arguments to the root directory
+	pathDecision, err := s.evaluator.EvaluatePaths(tool, s.rootDir, cwd, cmdArgs)
+	if err != nil {
+		resp := NewErrorResponse(req.ID, InternalError, "Policy evaluation failed", err.Error())
+		s.logAudit(ctx, req.Method, params.Name, tool, req.Params, resp, err, startTime)
+		return resp
+	}
+
+	if !pathDecision.Allowed {
+		fmt.Fprintf(os.Stderr, "[WARN] Path arguments denied by policy: %s\n", pathDecision.Reason)
+		resp := NewErrorResponse(req.ID, PolicyDenied, "Arguments denied by policy", pathDecision.Reason)
+		s.logAudit(ctx, req.Method, params.Name, tool, req.Params, resp, fmt.Errorf("policy denied: %s", pathDecision.Reason), startTime)
 		return resp
 	}
 
 	// Filter environment variables
-	filteredEnvKeys := s.evaluator.FilterEnvKeys(s.plugins.AllowedEnvKeys, getEnvKeys(os.Environ()))
+	filteredEnvKeys := s.evaluator.FilterEnvKeys(plugins.AllowedEnvKeys, getEnvKeys(os.Environ()))
 	filteredEnv := filterEnvByKeys(os.Environ(), filteredEnvKeys)
 
 	// Prepend args_prefix if defined (after policy evaluation)
 	if len(tool.ArgsPrefix) > 0 {
-		cmdArgs = append(tool.ArgsPrefix, cmdArgs...)
+		cmdArgs = append(append([]string{}, tool.ArgsPrefix...), cmdArgs...)
 	}
 
-	// Execute command using the tool's sandbox setting
-	result, err := s.executor.ExecuteWithSandbox(ctx, cwd, tool.Command, cmdArgs, filteredEnv, tool.Sandbox, tool.WasmBinary)
+	// Execute command using the tool's sandbox setting, streaming output if requested
+	opts := executor.ToolOptions(tool)
+	if stdin != "" {
+		opts.Stdin = strings.NewReader(stdin)
+	}
+	opts.InputFiles = inputs
+	done := streamOutputProgress(ctx, params.Meta, tool, opts)
+	result, err := s.executor.ExecuteWithSandbox(ctx, cwd, tool.Command, cmdArgs, filteredEnv, tool.Sandbox, tool.WasmBinary, opts)
+	done()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "[ERROR] Execution failed: %v\n", err)
-		resp := NewErrorResponse(req.ID, ExecutionFailed, "Execution failed", err.Error())
-		s.logAudit(req.Method, params.Name, req.Params, resp, err, startTime)
+		resp := executionErrorResponse(req.ID, err)
+		s.logAudit(ctx, req.Method, params.Name, tool, req.Params, resp, err, startTime)
 		return resp
 	}
 
-	// Return MCP-formatted result
-	content := []Content{
-		{
-			Type: "text",
-			Text: result.Stdout,
-		},
+	if result.Cancelled {
+		cancelErr := cancellationError(ctx)
+		fmt.Fprintf(os.Stderr, "[WARN] Execution cancelled: %v\n", cancelErr)
+		resp := NewErrorResponse(req.ID, ExecutionFailed, "Execution cancelled", cancelErr.Error())
+		s.logAudit(ctx, req.Method, params.Name, tool, req.Params, resp, cancelErr, startTime)
+		return resp
 	}
 
-	resp := NewResponse(req.ID, &CallToolResult{
-		Content: content,
-		IsError: result.ExitCode != 0,
-		Metadata: &ResultMetadata{
-			ExitCode: result.ExitCode,
-			Stderr:   result.Stderr,
-		},
-		Meta: BuildResultMeta(tool, result.Stdout),
+	resp := NewResponse(req.ID, toolCallResult(tool, result))
+	s.logAudit(ctx, req.Method, params.Name, tool, req.Params, resp, nil, startTime)
+	return resp
+}
+
+// handleMCPExplain evaluates a tools/call request against the policy without executing it
+func (s *HTTPServer) handleMCPExplain(ctx context.Context, req *Request) *Response {
+	if !s.explain {
+		return NewErrorResponse(req.ID, MethodNotFound, "Method not found", req.Method)
+	}
+	startTime := time.Now()
+	plugins := s.plugins.Load()
+	resp, toolName, err := explainResponse(s.evaluator, s.executor, plugins, s.rootDir, req, func(name string) bool {
+		return toolAllowed(ctx, name)
 	})
-	s.logAudit(req.Method, params.Name, req.Params, resp, nil, startTime)
+	s.logAudit(ctx, req.Method, toolName, plugins.GetTool(toolName), req.Params, resp, err, startTime)
 	return resp
 }
 
-func (s *HTTPServer) handleMCPResourcesList(req *Request) *Response {
-	// List UI resources for tools that have UI enabled
-	pluginTools := s.plugins.ListTools()
+func (s *HTTPServer) handleMCPResourcesList(ctx context.Context, req *Request) *Response {
+	// List UI resources for tools that have UI enabled and are within the token's scopes
+	pluginTools := s.plugins.Load().ListTools()
 
 	var resources []Resource
 	for _, t := range pluginTools {
-		if t.UIType != "" || t.UITemplate != "" {
+		if (t.UIType != "" || t.UITemplate != "") && toolAllowed(ctx, t.Name) {
 			resources = append(resources, Resource{
 				URI:         UIResourceURI(t.Name),
 				Name:        fmt.Sprintf("%s UI", t.Name),
@@ -541,7 +657,7 @@ func (s *HTTPServer) handleMCPResourcesList(req *Request) *Response {
 
 // handleMCPResourcesRead handles resources/read requests
 // sessionID is optional - empty string for non-streamable mode
-func (s *HTTPServer) handleMCPResourcesRead(req *Request, sessionID string) *Response {
+func (s *HTTPServer) handleMCPResourcesRead(ctx context.Context, req *Request, sessionID string) *Response {
 	var params ReadResourceParams
 	if err := json.Unmarshal(req.Params, &params); err != nil {
 		return NewErrorResponse(req.ID, InvalidParams, "Invalid params", err.Error())
@@ -561,8 +677,12 @@ func (s *HTTPServer) handleMCPResourcesRead(req *Request, sessionID string) *Res
 	}
 	toolName := pathParts[0]
 
+	if !toolAllowed(ctx, toolName) {
+		return NewErrorResponse(req.ID, Unauthorized, "Insufficient scope", toolName)
+	}
+
 	// Get the tool from plugins
-	tool := s.plugins.GetTool(toolName)
+	tool := s.plugins.Load().GetTool(toolName)
 	if tool == nil {
 		return NewErrorResponse(req.ID, MethodNotFound, "Tool not found", toolName)
 	}
@@ -598,12 +718,13 @@ func (s *HTTPServer) handleMCPResourcesRead(req *Request, sessionID string) *Res
 	})
 }
 
-// logAudit logs an audit entry if database is configured
-func (s *HTTPServer) logAudit(method string, toolName string, params interface{}, resp *Response, err error, startTime time.Time) {
+// logAudit logs an audit entry if database is configured; tool is the called tool, if found
+func (s *HTTPServer) logAudit(ctx context.Context, method string, toolName string, tool *plugin.Tool, params interface{}, resp *Response, err error, startTime time.Time) {
 	if s.db == nil {
 		return
 	}
-	if logErr := s.db.LogAudit(db.AuditModeHTTP, method, toolName, params, resp, err, startTime); logErr != nil {
+	params, response := auditBodies(params, resp, tool)
+	if logErr := s.db.LogAudit(db.AuditModeHTTP, auditCaller(ctx), method, toolName, params, response, err, startTime); logErr != nil {
 		fmt.Fprintf(os.Stderr, "[WARN] Failed to log audit: %v\n", logErr)
 	}
 }
@@ -2,6 +2,7 @@ package mcp
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -10,6 +11,7 @@ import (
 	"time"
 
 	"github.com/takeshy/mcp-gatekeeper/internal/db"
+	"github.com/takeshy/mcp-gatekeeper/internal/executor"
 	"github.com/takeshy/mcp-gatekeeper/internal/plugin"
 )
 
@@ -118,3 +120,341 @@ func TestInitializeIncludesOAuthExtension(t *testing.T) {
 		t.Fatalf("expected oauth client credentials extension, got %v", extensions)
 	}
 }
+
+func TestOAuthScopesRestrictTools(t *testing.T) {
+	database := newHTTPTestDB(t)
+	if _, err := database.CreateOAuthClient("scoped-client", []string{"tools:git-*"}); err != nil {
+		t.Fatalf("CreateOAuthClient: %v", err)
+	}
+	client, err := database.GetOAuthClient("scoped-client")
+	if err != nil {
+		t.Fatalf("GetOAuthClient: %v", err)
+	}
+	accessToken, _, err := database.CreateToken(client.ID, client.Scopes)
+	if err != nil {
+		t.Fatalf("CreateToken: %v", err)
+	}
+
+	plugins := &plugin.Config{Tools: map[string]*plugin.Tool{
+		"git-status": {Name: "git-status", Command: "git", Sandbox: plugin.SandboxTypeNone, UIType: plugin.UITypeTable},
+		"ls":         {Name: "ls", Command: "ls", Sandbox: plugin.SandboxTypeNone, UIType: plugin.UITypeTable},
+	}}
+	server, err := NewHTTPServer(plugins, &HTTPConfig{
+		EnableOAuth:     true,
+		DB:              database,
+		RootDir:         "/tmp",
+		RateLimit:       10,
+		RateLimitWindow: time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("NewHTTPServer: %v", err)
+	}
+
+	call := func(body string) Response {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "http://example.com/mcp", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		w := httptest.NewRecorder()
+		server.Handler().ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", w.Code)
+		}
+		var resp Response
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		return resp
+	}
+
+	// tools/list only includes tools within the token's scopes
+	resp := call(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+	if resp.Error != nil {
+		t.Fatalf("unexpected error: %+v", resp.Error)
+	}
+	result, _ := resp.Result.(map[string]interface{})
+	tools, _ := result["tools"].([]interface{})
+	if len(tools) != 1 {
+		t.Fatalf("expected 1 tool, got %v", result["tools"])
+	}
+	if name := tools[0].(map[string]interface{})["name"]; name != "git-status" {
+		t.Fatalf("expected git-status, got %v", name)
+	}
+
+	// tools/call outside the scopes is rejected
+	resp = call(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"ls","arguments":{}}}`)
+	if resp.Error == nil {
+		t.Fatal("expected insufficient scope error")
+	}
+	if resp.Error.Code != Unauthorized {
+		t.Fatalf("expected code %d, got %d", Unauthorized, resp.Error.Code)
+	}
+
+	// UI resources are limited to the same tools
+	resp = call(`{"jsonrpc":"2.0","id":3,"method":"resources/list"}`)
+	if resp.Error != nil {
+		t.Fatalf("unexpected error: %+v", resp.Error)
+	}
+	result, _ = resp.Result.(map[string]interface{})
+	resources, _ := result["resources"].([]interface{})
+	if len(resources) != 1 {
+		t.Fatalf("expected 1 resource, got %v", result["resources"])
+	}
+	if uri := resources[0].(map[string]interface{})["uri"]; uri != UIResourceURI("git-status") {
+		t.Fatalf("expected the git-status UI, got %v", uri)
+	}
+	resp = call(`{"jsonrpc":"2.0","id":4,"method":"resources/read","params":{"uri":"ui://ls"}}`)
+	if resp.Error == nil || resp.Error.Code != Unauthorized {
+		t.Fatalf("expected insufficient scope error, got %+v", resp.Error)
+	}
+}
+
+func TestNamedAPIKeys(t *testing.T) {
+	database := newHTTPTestDB(t)
+	limitedKey, err := database.CreateAPIKey("ci", []string{"git-*"}, 3, nil)
+	if err != nil {
+		t.Fatalf("CreateAPIKey: %v", err)
+	}
+	revokedKey, err := database.CreateAPIKey("old", nil, 0, nil)
+	if err != nil {
+		t.Fatalf("CreateAPIKey: %v", err)
+	}
+	old, err := database.GetAPIKey("old")
+	if err != nil {
+		t.Fatalf("GetAPIKey: %v", err)
+	}
+	if err := database.RevokeAPIKey(old.ID); err != nil {
+		t.Fatalf("RevokeAPIKey: %v", err)
+	}
+
+	plugins := &plugin.Config{Tools: map[string]*plugin.Tool{
+		"git-status": {Name: "git-status", Command: "git", Sandbox: plugin.SandboxTypeNone},
+		"ls":         {Name: "ls", Command: "ls", Sandbox: plugin.SandboxTypeNone},
+	}}
+	server, err := NewHTTPServer(plugins, &HTTPConfig{
+		EnableAPIKeys:   true,
+		DB:              database,
+		RootDir:         "/tmp",
+		RateLimit:       100,
+		RateLimitWindow: time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("NewHTTPServer: %v", err)
+	}
+
+	post := func(key, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "http://example.com/mcp", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+key)
+		w := httptest.NewRecorder()
+		server.Handler().ServeHTTP(w, req)
+		return w
+	}
+
+	// Revoked keys are rejected
+	if w := post(revokedKey, `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status 401 for revoked key, got %d", w.Code)
+	}
+
+	// tools/list only includes the key's allowed tools
+	w := post(limitedKey, `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	var resp Response
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	result, _ := resp.Result.(map[string]interface{})
+	if tools, _ := result["tools"].([]interface{}); len(tools) != 1 {
+		t.Fatalf("expected 1 tool, got %v", result["tools"])
+	}
+
+	// tools/call outside the allowlist is rejected and audited with the key's name
+	w = post(limitedKey, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"ls","arguments":{}}}`)
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Error == nil || resp.Error.Code != Unauthorized {
+		t.Fatalf("expected unauthorized error, got %+v", resp.Error)
+	}
+	entries, err := database.ListAuditLogs(db.AuditModeHTTP, 10, 0)
+	if err != nil {
+		t.Fatalf("ListAuditLogs: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Principal != "api_key:ci" {
+		t.Fatalf("expected audit entry for api_key:ci, got %+v", entries)
+	}
+
+	// The key's own rate limit applies (3 per window)
+	if w := post(limitedKey, `{"jsonrpc":"2.0","id":3,"method":"tools/list"}`); w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if w := post(limitedKey, `{"jsonrpc":"2.0","id":4,"method":"tools/list"}`); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected status 429, got %d", w.Code)
+	}
+}
+
+func TestAuditRecordsCaller(t *testing.T) {
+	database := newHTTPTestDB(t)
+	server, err := NewHTTPServer(&plugin.Config{Tools: map[string]*plugin.Tool{}}, &HTTPConfig{
+		APIKey:           "test-key",
+		DB:               database,
+		RootDir:          "/tmp",
+		RateLimit:        10,
+		RateLimitWindow:  time.Minute,
+		EnableStreamable: true,
+	})
+	if err != nil {
+		t.Fatalf("NewHTTPServer: %v", err)
+	}
+
+	post := func(sessionID, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "http://example.com/mcp", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("Authorization", "Bearer test-key")
+		req.Header.Set("User-Agent", "audit-test/1.0")
+		if sessionID != "" {
+			req.Header.Set(HeaderMcpSessionID, sessionID)
+		}
+		w := httptest.NewRecorder()
+		server.Handler().ServeHTTP(w, req)
+		return w
+	}
+
+	w := post("", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`)
+	sessionID := w.Header().Get(HeaderMcpSessionID)
+	if sessionID == "" {
+		t.Fatalf("expected session ID, got status %d: %s", w.Code, w.Body.String())
+	}
+
+	post(sessionID, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"missing","arguments":{}}}`)
+
+	entries, err := database.QueryAuditLogs(db.AuditFilter{SessionID: sessionID}, 10, 0)
+	if err != nil {
+		t.Fatalf("QueryAuditLogs: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 audit entry, got %d", len(entries))
+	}
+	want := db.AuditCaller{
+		Principal:  "api_key",
+		AuthMethod: db.AuthMethodAPIKey,
+		ClientIP:   "192.0.2.1",
+		UserAgent:  "audit-test/1.0",
+		SessionID:  sessionID,
+	}
+	if entries[0].AuditCaller != want {
+		t.Errorf("expected caller %+v, got %+v", want, entries[0].AuditCaller)
+	}
+}
+
+func TestQueueFullAndHealth(t *testing.T) {
+	plugins := &plugin.Config{Tools: map[string]*plugin.Tool{
+		"sleep": {Name: "sleep", Command: "sleep", Sandbox: plugin.SandboxTypeNone, MaxConcurrency: 1},
+	}}
+	server, err := NewHTTPServer(plugins, &HTTPConfig{
+		APIKey:          "test-key",
+		RootDir:         "/tmp",
+		RateLimit:       100,
+		RateLimitWindow: time.Minute,
+		Limits:          executor.Limits{MaxConcurrency: 4, MaxQueue: 0},
+	})
+	if err != nil {
+		t.Fatalf("NewHTTPServer: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	post := func(ctx context.Context, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "http://example.com/mcp", bytes.NewBufferString(body)).WithContext(ctx)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer test-key")
+		w := httptest.NewRecorder()
+		server.Handler().ServeHTTP(w, req)
+		return w
+	}
+	health := func() executor.QueueStats {
+		t.Helper()
+		w := httptest.NewRecorder()
+		server.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "http://example.com/health", nil))
+		var body struct {
+			Status string              `json:"status"`
+			Queue  executor.QueueStats `json:"queue"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("decode health: %v", err)
+		}
+		if body.Status != "ok" {
+			t.Fatalf("expected status ok, got %q", body.Status)
+		}
+		return body.Queue
+	}
+
+	// Occupy the tool's only slot
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		post(ctx, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"sleep","arguments":{"args":["30"]}}}`)
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for health().Running != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("first call did not start")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if stats := health(); stats.MaxConcurrency != 4 || stats.Waiting != 0 {
+		t.Errorf("unexpected queue stats %+v", stats)
+	}
+
+	// A second call to the same tool has nowhere to wait
+	w := post(context.Background(), `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"sleep","arguments":{"args":["0"]}}}`)
+	var resp Response
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Error == nil || resp.Error.Code != QueueFull {
+		t.Fatalf("expected queue full error, got %+v", resp.Error)
+	}
+
+	cancel()
+	<-done
+}
+
+func TestHealthIsolation(t *testing.T) {
+	plugins := &plugin.Config{Tools: map[string]*plugin.Tool{
+		"echo": {Name: "echo", Command: "echo", Sandbox: plugin.SandboxTypeNone},
+		"ruby": {Name: "ruby", Sandbox: plugin.SandboxTypeWasm, WasmBinary: "/tmp/ruby.wasm"},
+	}}
+	server, err := NewHTTPServer(plugins, &HTTPConfig{
+		APIKey:          "test-key",
+		RootDir:         "/tmp",
+		RateLimit:       100,
+		RateLimitWindow: time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("NewHTTPServer: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	server.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "http://example.com/health", nil))
+	var body struct {
+		Isolation map[string]string `json:"isolation"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode health: %v", err)
+	}
+	want := map[string]string{"echo": executor.IsolationNone, "ruby": executor.IsolationWasm}
+	if len(body.Isolation) != len(want) {
+		t.Fatalf("isolation = %v, want %v", body.Isolation, want)
+	}
+	for name, isolation := range want {
+		if body.Isolation[name] != isolation {
+			t.Errorf("isolation[%s] = %q, want %q", name, body.Isolation[name], isolation)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/takeshy/mcp-gatekeeper/internal/executor"
+	"github.com/takeshy/mcp-gatekeeper/internal/plugin"
+)
+
+// progressInterval is the minimum time between output progress notifications
+const progressInterval = 100 * time.Millisecond
+
+// notifyFunc delivers a server-to-client notification on the transport serving a request
+type notifyFunc func(*Notification)
+
+type notifierContextKey struct{}
+
+// withNotifier attaches the transport's notification sink to the request context
+func withNotifier(ctx context.Context, notify notifyFunc) context.Context {
+	return context.WithValue(ctx, notifierContextKey{}, notify)
+}
+
+// notifierFromContext returns the request's notification sink, or nil if the transport cannot notify
+func notifierFromContext(ctx context.Context) notifyFunc {
+	notify, _ := ctx.Value(notifierContextKey{}).(notifyFunc)
+	return notify
+}
+
+// newNotification creates a notification with JSON-encoded params
+func newNotification(method string, params interface{}) *Notification {
+	data, err := json.Marshal(params)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[WARN] Failed to encode %s params: %v\n", method, err)
+		return nil
+	}
+	return &Notification{
+		JSONRPC: "2.0",
+		Method:  method,
+		Params:  data,
+	}
+}
+
+// streamOutputProgress makes opts stream a tool's output as progress notifications
+// when the request carries a progress token and the transport can notify. Output of
+// tools with output filters is not streamed, since it is only filtered once the command
+// exits. The returned done func flushes pending output and must be called once execution finishes.
+func streamOutputProgress(ctx context.Context, meta *RequestMeta, tool *plugin.Tool, opts *executor.ExecuteOptions) func() {
+	if !meta.hasProgressToken() || len(tool.OutputFilters) > 0 {
+		return func() {}
+	}
+	notify := notifierFromContext(ctx)
+	if notify == nil {
+		return func() {}
+	}
+
+	p := &outputProgress{
+		token:  meta.ProgressToken,
+		logger: tool.Name,
+		notify: notify,
+	}
+	opts.OnOutput = p.write
+	return p.close
+}
+
+// outputProgress batches command output into progress and log notifications
+type outputProgress struct {
+	token  json.RawMessage
+	logger string
+	notify notifyFunc
+
+	mu       sync.Mutex
+	pending  []outputChunk
+	sent     int // Bytes reported so far; used as the monotonically increasing progress value
+	lastSent time.Time
+	timer    *time.Timer
+	closed   bool
+}
+
+// outputChunk is buffered output from one stream
+type outputChunk struct {
+	stream string
+	text   []byte
+}
+
+// write buffers a chunk and sends it immediately or once the progress interval elapses
+func (p *outputProgress) write(stream string, chunk []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return
+	}
+
+	if n := len(p.pending); n > 0 && p.pending[n-1].stream == stream {
+		p.pending[n-1].text = append(p.pending[n-1].text, chunk...)
+	} else {
+		p.pending = append(p.pending, outputChunk{stream: stream, text: append([]byte(nil), chunk...)})
+	}
+
+	if wait := progressInterval - time.Since(p.lastSent); wait > 0 {
+		if p.timer == nil {
+			p.timer = time.AfterFunc(wait, p.flushTimer)
+		}
+		return
+	}
+	p.flushLocked()
+}
+
+// flushTimer sends output buffered since the last notification
+func (p *outputProgress) flushTimer() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.timer = nil
+	if !p.closed {
+		p.flushLocked()
+	}
+}
+
+// close sends any pending output and stops further notifications
+func (p *outputProgress) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.timer != nil {
+		p.timer.Stop()
+		p.timer = nil
+	}
+	p.flushLocked()
+	p.closed = true
+}
+
+func (p *outputProgress) flushLocked() {
+	for _, c := range p.pending {
+		p.sent += len(c.text)
+		text := string(c.text)
+		if n := newNotification("notifications/progress", &ProgressParams{
+			ProgressToken: p.token,
+			Progress:      float64(p.sent),
+			Message:       text,
+		}); n != nil {
+			p.notify(n)
+		}
+		if n := newNotification("notifications/message", &LogMessageParams{
+			Level:  "info",
+			Logger: p.logger,
+			Data:   map[string]string{"stream": c.stream, "text": text},
+		}); n != nil {
+			p.notify(n)
+		}
+	}
+	p.pending = nil
+	p.lastSent = time.Now()
+}
@@ -0,0 +1,95 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/takeshy/mcp-gatekeeper/internal/executor"
+	"github.com/takeshy/mcp-gatekeeper/internal/plugin"
+)
+
+func TestStreamOutputProgress(t *testing.T) {
+	notifyCtx := withNotifier(context.Background(), func(*Notification) {})
+	token := &RequestMeta{ProgressToken: json.RawMessage(`"tok"`)}
+	tool := &plugin.Tool{Name: "tool"}
+	filtered := &plugin.Tool{Name: "tool", OutputFilters: []plugin.OutputFilter{{Type: plugin.OutputFilterStripANSI}}}
+
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		meta       *RequestMeta
+		tool       *plugin.Tool
+		wantStream bool
+	}{
+		{"no meta", notifyCtx, nil, tool, false},
+		{"null token", notifyCtx, &RequestMeta{ProgressToken: json.RawMessage(`null`)}, tool, false},
+		{"no notifier", context.Background(), token, tool, false},
+		{"token and notifier", notifyCtx, token, tool, true},
+		{"output filters", notifyCtx, token, filtered, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := &executor.ExecuteOptions{}
+			done := streamOutputProgress(tt.ctx, tt.meta, tt.tool, opts)
+			defer done()
+			if (opts.OnOutput != nil) != tt.wantStream {
+				t.Errorf("streamOutputProgress() set OnOutput = %v, want %v", opts.OnOutput != nil, tt.wantStream)
+			}
+		})
+	}
+}
+
+func TestOutputProgress_Batches(t *testing.T) {
+	var mu sync.Mutex
+	var progress []ProgressParams
+	var logs []LogMessageParams
+	ctx := withNotifier(context.Background(), func(n *Notification) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch n.Method {
+		case "notifications/progress":
+			var p ProgressParams
+			json.Unmarshal(n.Params, &p)
+			progress = append(progress, p)
+		case "notifications/message":
+			var p LogMessageParams
+			json.Unmarshal(n.Params, &p)
+			logs = append(logs, p)
+		}
+	})
+
+	opts := &executor.ExecuteOptions{}
+	done := streamOutputProgress(ctx, &RequestMeta{ProgressToken: json.RawMessage(`7`)}, &plugin.Tool{Name: "git_log"}, opts)
+	opts.OnOutput("stdout", []byte("one\n"))
+	opts.OnOutput("stdout", []byte("two\n"))
+	opts.OnOutput("stderr", []byte("warn\n"))
+	done()
+	opts.OnOutput("stdout", []byte("late\n"))
+	time.Sleep(2 * progressInterval)
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []ProgressParams{
+		{ProgressToken: json.RawMessage(`7`), Progress: 4, Message: "one\n"},
+		{ProgressToken: json.RawMessage(`7`), Progress: 8, Message: "two\n"},
+		{ProgressToken: json.RawMessage(`7`), Progress: 13, Message: "warn\n"},
+	}
+	if len(progress) != len(want) {
+		t.Fatalf("got %d progress notifications, want %d: %+v", len(progress), len(want), progress)
+	}
+	for i := range want {
+		if string(progress[i].ProgressToken) != string(want[i].ProgressToken) || progress[i].Progress != want[i].Progress || progress[i].Message != want[i].Message {
+			t.Errorf("progress[%d] = %+v, want %+v", i, progress[i], want[i])
+		}
+	}
+	if len(logs) != len(want) {
+		t.Fatalf("got %d log notifications, want %d", len(logs), len(want))
+	}
+	if logs[2].Logger != "git_log" || logs[2].Data.(map[string]interface{})["stream"] != "stderr" {
+		t.Errorf("unexpected stderr log message: %+v", logs[2])
+	}
+}
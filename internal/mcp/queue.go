@@ -0,0 +1,17 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/takeshy/mcp-gatekeeper/internal/executor"
+)
+
+// executionErrorResponse returns the error response for a failed execution.
+// Executions rejected for lack of a free slot get QueueFull so clients can retry.
+func executionErrorResponse(id json.RawMessage, err error) *Response {
+	if errors.Is(err, executor.ErrQueueFull) || errors.Is(err, executor.ErrQueueTimeout) {
+		return NewErrorResponse(id, QueueFull, "Execution queue full", err.Error())
+	}
+	return NewErrorResponse(id, ExecutionFailed, "Execution failed", err.Error())
+}
@@ -187,6 +187,15 @@ func (m *Manager) cleanup() {
 	}
 }
 
+// Broadcast sends an event to the SSE channels of all sessions
+func (m *Manager) Broadcast(event *SSEEvent) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, session := range m.sessions {
+		session.Broadcast(event)
+	}
+}
+
 // Count returns the number of active sessions
 func (m *Manager) Count() int {
 	m.mu.RLock()
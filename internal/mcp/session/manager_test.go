@@ -182,6 +182,30 @@ func TestSession_SSEChannels(t *testing.T) {
 	}
 }
 
+func TestManager_Broadcast(t *testing.T) {
+	m := NewManager(time.Hour)
+	s1 := m.Create()
+	s2 := m.Create()
+
+	ch1 := make(chan *SSEEvent, 10)
+	ch2 := make(chan *SSEEvent, 10)
+	s1.AddSSEChannel(ch1)
+	s2.AddSSEChannel(ch2)
+
+	m.Broadcast(&SSEEvent{ID: "all", Data: "test"})
+
+	for i, ch := range []chan *SSEEvent{ch1, ch2} {
+		select {
+		case got := <-ch:
+			if got.ID != "all" {
+				t.Errorf("session %d: expected event ID all, got %s", i+1, got.ID)
+			}
+		case <-time.After(time.Second):
+			t.Errorf("session %d: expected event", i+1)
+		}
+	}
+}
+
 func TestSession_Close(t *testing.T) {
 	m := NewManager(time.Hour)
 	session := m.Create()
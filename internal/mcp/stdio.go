@@ -9,6 +9,8 @@ import (
 	"io"
 	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/takeshy/mcp-gatekeeper/internal/db"
@@ -25,12 +27,17 @@ const (
 
 // StdioServer implements the MCP server over stdio
 type StdioServer struct {
-	plugins     *plugin.Config
+	plugins     atomic.Pointer[plugin.Config]
 	evaluator   *policy.Evaluator
 	executor    *executor.Executor
-	initialized bool
+	initialized atomic.Bool
+	listChanged bool // Advertise and send notifications/tools/list_changed
+	explain     bool // Serve gatekeeper/explain
 	reader      *bufio.Reader
 	writer      io.Writer
+	writeMu     sync.Mutex // Serializes responses and server-initiated notifications
+	inflight    *inflightRequests
+	calls       sync.WaitGroup // Tool calls running concurrently with the read loop
 	rootDir     string
 	db          *db.DB // Optional database for audit logging
 }
@@ -54,19 +61,58 @@ func NewStdioServer(plugins *plugin.Config, apiKey string, expectedAPIKey string
 		WasmDir:   wasmDir,
 	}
 
-	return &StdioServer{
-		plugins:   plugins,
+	s := &StdioServer{
 		evaluator: policy.NewEvaluator(),
 		executor:  executor.NewExecutor(execConfig),
 		reader:    bufio.NewReader(os.Stdin),
 		writer:    os.Stdout,
+		inflight:  newInflightRequests(),
 		rootDir:   rootDir,
 		db:        database,
-	}, nil
+	}
+	s.plugins.Store(plugins)
+	setAuditSecrets(s.db, s.evaluator, plugins)
+	return s, nil
+}
+
+// EnableListChanged advertises the tools listChanged capability; call it before Run
+// when plugins may be replaced via SetPlugins
+func (s *StdioServer) EnableListChanged() {
+	s.listChanged = true
+}
+
+// SetLimits sets the execution limits; call it before Run
+func (s *StdioServer) SetLimits(limits executor.Limits) {
+	s.executor.SetLimits(limits)
+}
+
+// SetBindPaths sets the host paths outside the root directory that tool bwrap binds may use; call it before Run
+func (s *StdioServer) SetBindPaths(paths []string) {
+	s.executor.SetBindPaths(paths)
+}
+
+// EnableExplain serves the gatekeeper/explain policy dry-run method; call it before Run
+func (s *StdioServer) EnableExplain() {
+	s.explain = true
+}
+
+// SetPlugins atomically replaces the plugin configuration and notifies
+// an initialized client that the tool list has changed
+func (s *StdioServer) SetPlugins(plugins *plugin.Config) {
+	s.plugins.Store(plugins)
+	setAuditSecrets(s.db, s.evaluator, plugins)
+	if !s.listChanged || !s.initialized.Load() {
+		return
+	}
+	if err := s.writeMessage(newToolsListChangedNotification()); err != nil {
+		fmt.Fprintf(os.Stderr, "[WARN] Failed to send tools/list_changed notification: %v\n", err)
+	}
 }
 
 // Run runs the stdio server
 func (s *StdioServer) Run(ctx context.Context) error {
+	defer s.calls.Wait()
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -87,6 +133,22 @@ func (s *StdioServer) Run(ctx context.Context) error {
 			continue
 		}
 
+		// Run tool calls in the background so notifications/cancelled can still be read.
+		// The call is registered before the next line is read, so a cancellation sent right
+		// after it is not missed.
+		if id, ok := toolsCallID([]byte(line)); ok {
+			callCtx, done, err := s.inflight.start(ctx, "", id)
+			if err != nil {
+				if err := s.writeResponse(NewErrorResponse(id, InvalidRequest, "Invalid Request", err.Error())); err != nil {
+					return fmt.Errorf("failed to write response: %w", err)
+				}
+				continue
+			}
+			s.calls.Add(1)
+			go s.runToolsCall(callCtx, done, []byte(line))
+			continue
+		}
+
 		response, err := s.handleMessage(ctx, []byte(line))
 		if err != nil {
 			// Log error but continue
@@ -102,6 +164,35 @@ func (s *StdioServer) Run(ctx context.Context) error {
 	}
 }
 
+// toolsCallID returns the id of a tools/call request
+func toolsCallID(data []byte) (json.RawMessage, bool) {
+	var req Request
+	if err := json.Unmarshal(data, &req); err != nil || req.Method != "tools/call" {
+		return nil, false
+	}
+	if req.ID == nil || string(req.ID) == "null" {
+		return nil, false
+	}
+	return req.ID, true
+}
+
+// runToolsCall handles a tools/call request registered as in flight; done untracks it
+func (s *StdioServer) runToolsCall(ctx context.Context, done func(), data []byte) {
+	defer s.calls.Done()
+	defer done()
+
+	response, err := s.handleMessage(ctx, data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error handling message: %v\n", err)
+		return
+	}
+	if response != nil {
+		if err := s.writeResponse(response); err != nil {
+			fmt.Fprintf(os.Stderr, "[ERROR] Failed to write response: %v\n", err)
+		}
+	}
+}
+
 func (s *StdioServer) handleMessage(ctx context.Context, data []byte) (*Response, error) {
 	var req Request
 	if err := json.Unmarshal(data, &req); err != nil {
@@ -127,10 +218,10 @@ func (s *StdioServer) handleMessage(ctx context.Context, data []byte) (*Response
 func (s *StdioServer) handleNotification(ctx context.Context, req *Request) error {
 	switch req.Method {
 	case "notifications/initialized":
-		s.initialized = true
+		s.initialized.Store(true)
 		return nil
 	case "notifications/cancelled":
-		// Handle cancellation
+		s.inflight.cancel("", req.Params)
 		return nil
 	default:
 		return fmt.Errorf("unknown notification: %s", req.Method)
@@ -149,6 +240,11 @@ func (s *StdioServer) handleRequest(ctx context.Context, req *Request) (*Respons
 		return s.handleResourcesList(req)
 	case "resources/read":
 		return s.handleResourcesRead(req)
+	case ExplainMethod:
+		if s.explain {
+			return s.handleExplain(req)
+		}
+		return NewErrorResponse(req.ID, MethodNotFound, "Method not found", req.Method), nil
 	case "ping":
 		return NewResponse(req.ID, struct{}{}), nil
 	default:
@@ -159,7 +255,7 @@ func (s *StdioServer) handleRequest(ctx context.Context, req *Request) (*Respons
 func (s *StdioServer) handleInitialize(req *Request) (*Response, error) {
 	caps := ServerCapabilities{
 		Tools: &ToolsCapability{
-			ListChanged: false,
+			ListChanged: s.listChanged,
 		},
 	}
 
@@ -183,7 +279,7 @@ func (s *StdioServer) handleInitialize(req *Request) (*Response, error) {
 }
 
 func (s *StdioServer) hasUIEnabledTools() bool {
-	for _, t := range s.plugins.ListTools() {
+	for _, t := range s.plugins.Load().ListTools() {
 		if t.UIType != "" || t.UITemplate != "" {
 			return true
 		}
@@ -193,7 +289,7 @@ func (s *StdioServer) hasUIEnabledTools() bool {
 
 func (s *StdioServer) handleToolsList(req *Request) (*Response, error) {
 	// Get tools from plugins
-	pluginTools := s.plugins.ListTools()
+	pluginTools := s.plugins.Load().ListTools()
 
 	tools := make([]Tool, 0, len(pluginTools))
 	for _, t := range pluginTools {
@@ -202,35 +298,12 @@ func (s *StdioServer) handleToolsList(req *Request) (*Response, error) {
 			continue
 		}
 
-		// Build properties based on tool configuration
-		props := map[string]Property{}
-		// Only include cwd property if the tool allows changing working directory
-		if !t.FixedCwd {
-			props["cwd"] = Property{
-				Type:        "string",
-				Description: "Working directory for the command (defaults to root directory)",
-			}
-		}
-		// Only include args property if the tool accepts arguments
-		// A tool with allowed_arg_globs = [""] means it accepts no arguments
-		noArgs := len(t.AllowedArgGlobs) == 1 && t.AllowedArgGlobs[0] == ""
-		if !noArgs {
-			props["args"] = Property{
-				Type:        "array",
-				Description: "Command arguments",
-				Items:       &Items{Type: "string"},
-			}
-		}
-
 		tools = append(tools, Tool{
-			Name:        t.Name,
-			Description: t.Description,
-			InputSchema: InputSchema{
-				Type:       "object",
-				Properties: props,
-				Required:   []string{},
-			},
-			Meta: BuildToolMeta(t),
+			Name:         t.Name,
+			Description:  t.Description,
+			InputSchema:  BuildInputSchema(t),
+			OutputSchema: BuildOutputSchema(t),
+			Meta:         BuildToolMeta(t),
 		})
 	}
 	return NewResponse(req.ID, &ListToolsResult{Tools: tools}), nil
@@ -242,106 +315,141 @@ func (s *StdioServer) handleToolsCall(ctx context.Context, req *Request) (*Respo
 	if err := json.Unmarshal(req.Params, &params); err != nil {
 		fmt.Fprintf(os.Stderr, "[ERROR] Invalid params: %v\n", err)
 		resp := NewErrorResponse(req.ID, InvalidParams, "Invalid params", err.Error())
-		s.logAudit(req.Method, params.Name, req.Params, resp, err, startTime)
+		s.logAudit(req.Method, params.Name, nil, req.Params, resp, err, startTime)
 		return resp, nil
 	}
 
-	// Look up tool by name from plugins
-	tool := s.plugins.GetTool(params.Name)
+	// Load the plugins once, so a concurrent reload cannot mix two configurations in one call
+	plugins := s.plugins.Load()
+	tool := plugins.GetTool(params.Name)
 	if tool == nil {
 		fmt.Fprintf(os.Stderr, "[ERROR] Tool not found: %s\n", params.Name)
 		resp := NewErrorResponse(req.ID, MethodNotFound, "Tool not found", params.Name)
-		s.logAudit(req.Method, params.Name, req.Params, resp, fmt.Errorf("tool not found: %s", params.Name), startTime)
+		s.logAudit(req.Method, params.Name, nil, req.Params, resp, fmt.Errorf("tool not found: %s", params.Name), startTime)
 		return resp, nil
 	}
 
-	return s.handleExecute(ctx, req.ID, req.Method, tool, params.Arguments, req.Params, startTime)
+	return s.handleExecute(withNotifier(ctx, s.notify), req.ID, req.Method, plugins, tool, params.Arguments, params.Meta, req.Params, startTime)
 }
 
-func (s *StdioServer) handleExecute(ctx context.Context, id json.RawMessage, method string, tool *plugin.Tool, args map[string]interface{}, rawParams json.RawMessage, startTime time.Time) (*Response, error) {
-	// Parse arguments
-	cwd, _ := args["cwd"].(string)
-	var cmdArgs []string
-	if argsRaw, ok := args["args"].([]interface{}); ok {
-		for _, a := range argsRaw {
-			if str, ok := a.(string); ok {
-				cmdArgs = append(cmdArgs, str)
-			}
-		}
+func (s *StdioServer) handleExecute(ctx context.Context, id json.RawMessage, method string, plugins *plugin.Config, tool *plugin.Tool, args map[string]interface{}, meta *RequestMeta, rawParams json.RawMessage, startTime time.Time) (*Response, error) {
+	// File arguments are written to a scratch directory that lives for this call
+	inputs, err := executor.NewInputFiles(tool)
+	if err != nil {
+		resp := NewErrorResponse(id, InternalError, "Failed to prepare input files", err.Error())
+		s.logAudit(method, tool.Name, tool, rawParams, resp, err, startTime)
+		return resp, nil
 	}
+	defer inputs.Close()
 
-	// Default cwd to rootDir if not provided
-	if cwd == "" {
-		cwd = s.rootDir
+	// Parse and validate arguments
+	cwd, cmdArgs, err := parseToolArguments(tool, args, inputs.Add)
+	var stdin string
+	if err == nil {
+		stdin, err = parseToolStdin(tool, args)
+	}
+	if err == nil {
+		cwd, err = resolveCwd(s.executor, s.rootDir, cwd)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[WARN] Invalid arguments for %s: %v\n", tool.Name, err)
+		resp := NewErrorResponse(id, InvalidParams, "Invalid arguments", err.Error())
+		s.logAudit(method, tool.Name, tool, rawParams, resp, err, startTime)
+		return resp, nil
 	}
 
 	// Evaluate policy (check if user-provided args are allowed)
 	decision, err := s.evaluator.EvaluateArgs(tool, cmdArgs)
 	if err != nil {
 		resp := NewErrorResponse(id, InternalError, "Policy evaluation failed", err.Error())
-		s.logAudit(method, tool.Name, rawParams, resp, err, startTime)
+		s.logAudit(method, tool.Name, tool, rawParams, resp, err, startTime)
 		return resp, nil
 	}
 
 	if !decision.Allowed {
 		fmt.Fprintf(os.Stderr, "[WARN] Arguments denied by policy: %s\n", decision.Reason)
 		resp := NewErrorResponse(id, PolicyDenied, "Arguments denied by policy", decision.Reason)
-		s.logAudit(method, tool.Name, rawParams, resp, fmt.Errorf("policy denied: %s", decision.Reason), startTime)
+		s.logAudit(method, tool.Name, tool, rawParams, resp, fmt.Errorf("policy denied: %s", decision.Reason), startTime)
+		return resp, nil
+	}
+
+	// Confine declared path arguments to the root directory
+	pathDecision, err := s.evaluator.EvaluatePaths(tool, s.rootDir, cwd, cmdArgs)
+	if err != nil {
+		resp := NewErrorResponse(id, InternalError, "Policy evaluation failed", err.Error())
+		s.logAudit(method, tool.Name, tool, rawParams, resp, err, startTime)
+		return resp, nil
+	}
+
+	if !pathDecision.Allowed {
+		fmt.Fprintf(os.Stderr, "[WARN] Path arguments denied by policy: %s\n", pathDecision.Reason)
+		resp := NewErrorResponse(id, PolicyDenied, "Arguments denied by policy", pathDecision.Reason)
+		s.logAudit(method, tool.Name, tool, rawParams, resp, fmt.Errorf("policy denied: %s", pathDecision.Reason), startTime)
 		return resp, nil
 	}
 
 	// Filter environment variables
-	filteredEnvKeys := s.evaluator.FilterEnvKeys(s.plugins.AllowedEnvKeys, getEnvKeys(os.Environ()))
+	filteredEnvKeys := s.evaluator.FilterEnvKeys(plugins.AllowedEnvKeys, getEnvKeys(os.Environ()))
 	filteredEnv := filterEnvByKeys(os.Environ(), filteredEnvKeys)
 
 	// Prepend args_prefix if defined (after policy evaluation)
 	if len(tool.ArgsPrefix) > 0 {
-		cmdArgs = append(tool.ArgsPrefix, cmdArgs...)
+		cmdArgs = append(append([]string{}, tool.ArgsPrefix...), cmdArgs...)
 	}
 
-	// Execute command using the tool's sandbox setting
-	result, err := s.executor.ExecuteWithSandbox(ctx, cwd, tool.Command, cmdArgs, filteredEnv, tool.Sandbox, tool.WasmBinary)
+	// Execute command using the tool's sandbox setting, streaming output if requested
+	opts := executor.ToolOptions(tool)
+	if stdin != "" {
+		opts.Stdin = strings.NewReader(stdin)
+	}
+	opts.InputFiles = inputs
+	done := streamOutputProgress(ctx, meta, tool, opts)
+	result, err := s.executor.ExecuteWithSandbox(ctx, cwd, tool.Command, cmdArgs, filteredEnv, tool.Sandbox, tool.WasmBinary, opts)
+	done()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "[ERROR] Execution failed: %v\n", err)
-		resp := NewErrorResponse(id, ExecutionFailed, "Execution failed", err.Error())
-		s.logAudit(method, tool.Name, rawParams, resp, err, startTime)
+		resp := executionErrorResponse(id, err)
+		s.logAudit(method, tool.Name, tool, rawParams, resp, err, startTime)
 		return resp, nil
 	}
 
-	// Return result
-	content := []Content{
-		{
-			Type: "text",
-			Text: result.Stdout,
-		},
+	// A cancelled request is audited but gets no response
+	if result.Cancelled {
+		cancelErr := cancellationError(ctx)
+		fmt.Fprintf(os.Stderr, "[WARN] Execution cancelled: %v\n", cancelErr)
+		resp := NewErrorResponse(id, ExecutionFailed, "Execution cancelled", cancelErr.Error())
+		s.logAudit(method, tool.Name, tool, rawParams, resp, cancelErr, startTime)
+		return nil, nil
 	}
 
-	resp := NewResponse(id, &CallToolResult{
-		Content: content,
-		IsError: result.ExitCode != 0,
-		Metadata: &ResultMetadata{
-			ExitCode: result.ExitCode,
-			Stderr:   result.Stderr,
-		},
-		Meta: BuildResultMeta(tool, result.Stdout),
-	})
-	s.logAudit(method, tool.Name, rawParams, resp, nil, startTime)
+	resp := NewResponse(id, toolCallResult(tool, result))
+	s.logAudit(method, tool.Name, tool, rawParams, resp, nil, startTime)
 	return resp, nil
 }
 
-// logAudit logs an audit entry if database is configured
-func (s *StdioServer) logAudit(method string, toolName string, params interface{}, resp *Response, err error, startTime time.Time) {
+// handleExplain evaluates a tools/call request against the policy without executing it
+func (s *StdioServer) handleExplain(req *Request) (*Response, error) {
+	startTime := time.Now()
+	plugins := s.plugins.Load()
+	resp, toolName, err := explainResponse(s.evaluator, s.executor, plugins, s.rootDir, req, nil)
+	s.logAudit(req.Method, toolName, plugins.GetTool(toolName), req.Params, resp, err, startTime)
+	return resp, nil
+}
+
+// logAudit logs an audit entry if database is configured; tool is the called tool, if found
+func (s *StdioServer) logAudit(method string, toolName string, tool *plugin.Tool, params interface{}, resp *Response, err error, startTime time.Time) {
 	if s.db == nil {
 		return
 	}
-	if logErr := s.db.LogAudit(db.AuditModeStdio, method, toolName, params, resp, err, startTime); logErr != nil {
+	params, response := auditBodies(params, resp, tool)
+	if logErr := s.db.LogAudit(db.AuditModeStdio, nil, method, toolName, params, response, err, startTime); logErr != nil {
 		fmt.Fprintf(os.Stderr, "[WARN] Failed to log audit: %v\n", logErr)
 	}
 }
 
 func (s *StdioServer) handleResourcesList(req *Request) (*Response, error) {
 	// List UI resources for tools that have UI enabled
-	pluginTools := s.plugins.ListTools()
+	pluginTools := s.plugins.Load().ListTools()
 
 	var resources []Resource
 	for _, t := range pluginTools {
@@ -380,7 +488,7 @@ func (s *StdioServer) handleResourcesRead(req *Request) (*Response, error) {
 	toolName := pathParts[0]
 
 	// Get the tool from plugins
-	tool := s.plugins.GetTool(toolName)
+	tool := s.plugins.Load().GetTool(toolName)
 	if tool == nil {
 		return NewErrorResponse(req.ID, MethodNotFound, "Tool not found", toolName), nil
 	}
@@ -417,10 +525,24 @@ func (s *StdioServer) handleResourcesRead(req *Request) (*Response, error) {
 }
 
 func (s *StdioServer) writeResponse(resp *Response) error {
-	data, err := json.Marshal(resp)
+	return s.writeMessage(resp)
+}
+
+// notify writes a server-initiated notification, logging write failures
+func (s *StdioServer) notify(n *Notification) {
+	if err := s.writeMessage(n); err != nil {
+		fmt.Fprintf(os.Stderr, "[WARN] Failed to send %s notification: %v\n", n.Method, err)
+	}
+}
+
+// writeMessage writes a single JSON-RPC message line
+func (s *StdioServer) writeMessage(msg interface{}) error {
+	data, err := json.Marshal(msg)
 	if err != nil {
 		return err
 	}
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
 	_, err = fmt.Fprintf(s.writer, "%s\n", data)
 	return err
 }
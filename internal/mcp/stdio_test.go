@@ -0,0 +1,356 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/takeshy/mcp-gatekeeper/internal/db"
+	"github.com/takeshy/mcp-gatekeeper/internal/plugin"
+)
+
+func TestStdioServer_SetPlugins(t *testing.T) {
+	s, err := NewStdioServer(&plugin.Config{Tools: map[string]*plugin.Tool{}}, "", "", "/tmp", "", nil)
+	if err != nil {
+		t.Fatalf("NewStdioServer() error = %v", err)
+	}
+	var out bytes.Buffer
+	s.writer = &out
+	s.EnableListChanged()
+
+	reloaded := &plugin.Config{Tools: map[string]*plugin.Tool{
+		"ls": {Name: "ls", Command: "ls", Sandbox: plugin.SandboxTypeNone},
+	}}
+
+	// No notification before the client has initialized
+	s.SetPlugins(reloaded)
+	if out.Len() != 0 {
+		t.Fatalf("expected no output before initialization, got %q", out.String())
+	}
+	if s.plugins.Load().GetTool("ls") == nil {
+		t.Fatal("expected config to be replaced")
+	}
+
+	if _, err := s.handleMessage(context.Background(), []byte(`{"jsonrpc": "2.0", "method": "notifications/initialized"}`)); err != nil {
+		t.Fatalf("handleMessage() error = %v", err)
+	}
+	s.SetPlugins(reloaded)
+	if got := out.String(); got != `{"jsonrpc":"2.0","method":"notifications/tools/list_changed"}`+"\n" {
+		t.Errorf("unexpected notification output: %q", got)
+	}
+
+	resp, err := s.handleMessage(context.Background(), []byte(`{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": {}}`))
+	if err != nil {
+		t.Fatalf("handleMessage() error = %v", err)
+	}
+	result := resp.Result.(*InitializeResult)
+	if !result.Capabilities.Tools.ListChanged {
+		t.Error("expected tools listChanged capability")
+	}
+}
+
+func TestStdioServer_ProgressNotifications(t *testing.T) {
+	s, err := NewStdioServer(&plugin.Config{Tools: map[string]*plugin.Tool{
+		"sh": {Name: "sh", Command: "sh", ArgsPrefix: []string{"-c"}, Sandbox: plugin.SandboxTypeNone},
+	}}, "", "", "/tmp", "", nil)
+	if err != nil {
+		t.Fatalf("NewStdioServer() error = %v", err)
+	}
+	var out bytes.Buffer
+	s.writer = &out
+
+	// Without a progress token only the response is returned
+	if _, err := s.handleMessage(context.Background(), []byte(`{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "sh", "arguments": {"args": ["echo hello"]}}}`)); err != nil {
+		t.Fatalf("handleMessage() error = %v", err)
+	}
+	if out.Len() != 0 {
+		t.Fatalf("expected no notifications without a progress token, got %q", out.String())
+	}
+
+	resp, err := s.handleMessage(context.Background(), []byte(`{"jsonrpc": "2.0", "id": 2, "method": "tools/call", "params": {"name": "sh", "arguments": {"args": ["echo hello"]}, "_meta": {"progressToken": "call-2"}}}`))
+	if err != nil {
+		t.Fatalf("handleMessage() error = %v", err)
+	}
+	if result := resp.Result.(*CallToolResult); result.Content[0].Text != "hello\n" {
+		t.Errorf("expected full result, got %q", result.Content[0].Text)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected progress and log notifications, got %q", out.String())
+	}
+	var progress struct {
+		Method string         `json:"method"`
+		Params ProgressParams `json:"params"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &progress); err != nil {
+		t.Fatalf("failed to parse notification: %v", err)
+	}
+	if progress.Method != "notifications/progress" || string(progress.Params.ProgressToken) != `"call-2"` || progress.Params.Message != "hello\n" {
+		t.Errorf("unexpected progress notification: %s", lines[0])
+	}
+	if !strings.Contains(lines[1], `"method":"notifications/message"`) {
+		t.Errorf("expected log message notification, got %s", lines[1])
+	}
+}
+
+func TestStdioServer_CancelToolsCall(t *testing.T) {
+	database := newHTTPTestDB(t)
+	s, err := NewStdioServer(&plugin.Config{Tools: map[string]*plugin.Tool{
+		"sleep": {Name: "sleep", Command: "sleep", Sandbox: plugin.SandboxTypeNone},
+	}}, "", "", "/tmp", "", database)
+	if err != nil {
+		t.Fatalf("NewStdioServer() error = %v", err)
+	}
+	in, input := io.Pipe()
+	s.reader = bufio.NewReader(in)
+	var out bytes.Buffer
+	s.writer = &out
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- s.Run(context.Background()) }()
+
+	start := time.Now()
+	io.WriteString(input, `{"jsonrpc": "2.0", "id": 7, "method": "tools/call", "params": {"name": "sleep", "arguments": {"args": ["30"]}}}`+"\n")
+	// Ping is answered while the tool call is still running
+	io.WriteString(input, `{"jsonrpc": "2.0", "id": 8, "method": "ping"}`+"\n")
+	time.Sleep(200 * time.Millisecond)
+	io.WriteString(input, `{"jsonrpc": "2.0", "method": "notifications/cancelled", "params": {"requestId": 7, "reason": "user gave up"}}`+"\n")
+	input.Close()
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Run() did not return after cancellation")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("cancelled call took %v", elapsed)
+	}
+
+	// Only the ping is answered; cancelled requests get no response
+	if got := out.String(); got != `{"jsonrpc":"2.0","id":8,"result":{}}`+"\n" {
+		t.Errorf("unexpected output: %q", got)
+	}
+
+	entries, err := database.ListAuditLogs(db.AuditModeStdio, 10, 0)
+	if err != nil {
+		t.Fatalf("ListAuditLogs: %v", err)
+	}
+	if len(entries) != 1 || entries[0].ToolName != "sleep" || entries[0].Error != "request cancelled by client: user gave up" {
+		t.Errorf("expected cancellation audit entry, got %+v", entries)
+	}
+}
+
+func TestStdioServer_CancelRightAfterCall(t *testing.T) {
+	s, err := NewStdioServer(&plugin.Config{Tools: map[string]*plugin.Tool{
+		"sleep": {Name: "sleep", Command: "sleep", Sandbox: plugin.SandboxTypeNone},
+	}}, "", "", "/tmp", "", nil)
+	if err != nil {
+		t.Fatalf("NewStdioServer() error = %v", err)
+	}
+	// The call, a second call reusing its id and the cancellation arrive back to back
+	s.reader = bufio.NewReader(strings.NewReader(
+		`{"jsonrpc": "2.0", "id": 7, "method": "tools/call", "params": {"name": "sleep", "arguments": {"args": ["30"]}}}` + "\n" +
+			`{"jsonrpc": "2.0", "id": 7, "method": "tools/call", "params": {"name": "sleep", "arguments": {"args": ["30"]}}}` + "\n" +
+			`{"jsonrpc": "2.0", "method": "notifications/cancelled", "params": {"requestId": 7}}` + "\n"))
+	var out bytes.Buffer
+	s.writer = &out
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- s.Run(context.Background()) }()
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Run() did not return; the cancellation was missed")
+	}
+
+	// Only the duplicate is answered; the cancelled call gets no response
+	var resp Response
+	if err := json.Unmarshal(out.Bytes(), &resp); err != nil {
+		t.Fatalf("expected a single response, got %q", out.String())
+	}
+	if resp.Error == nil || resp.Error.Code != InvalidRequest {
+		t.Errorf("expected InvalidRequest for the duplicate id, got %q", out.String())
+	}
+}
+
+func TestStdioServer_ConcurrentArgsPrefix(t *testing.T) {
+	// A decoded args_prefix has spare capacity, which concurrent calls must not share
+	pluginFile := filepath.Join(t.TempDir(), "plugin.json")
+	if err := os.WriteFile(pluginFile, []byte(`{"tools": [{"name": "echo", "command": "echo", "args_prefix": ["a", "b", "c"], "allowed_arg_globs": ["*"], "sandbox": "none"}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	plugins, err := plugin.LoadFromFile(pluginFile)
+	if err != nil {
+		t.Fatalf("LoadFromFile() error = %v", err)
+	}
+	s, err := NewStdioServer(plugins, "", "", "/tmp", "", nil)
+	if err != nil {
+		t.Fatalf("NewStdioServer() error = %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			arg := fmt.Sprintf("call-%d", i)
+			resp, err := s.handleMessage(context.Background(), []byte(fmt.Sprintf(`{"jsonrpc": "2.0", "id": %d, "method": "tools/call", "params": {"name": "echo", "arguments": {"args": [%q]}}}`, i, arg)))
+			if err != nil {
+				t.Errorf("handleMessage() error = %v", err)
+				return
+			}
+			result, ok := resp.Result.(*CallToolResult)
+			if !ok || result.Content[0].Text != "a b c "+arg+"\n" {
+				t.Errorf("expected %q to be echoed after the prefix, got %+v", arg, resp)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestStdioServer_Stdin(t *testing.T) {
+	database := newHTTPTestDB(t)
+	s, err := NewStdioServer(&plugin.Config{Tools: map[string]*plugin.Tool{
+		"wc":  {Name: "wc", Command: "wc", ArgsPrefix: []string{"-c"}, AllowedArgGlobs: []string{""}, Sandbox: plugin.SandboxTypeNone, Stdin: &plugin.StdinConfig{MaxBytes: 64, ContentType: plugin.StdinContentTypeJSON}},
+		"cat": {Name: "cat", Command: "cat", Sandbox: plugin.SandboxTypeNone},
+	}}, "", "", "/tmp", "", database)
+	if err != nil {
+		t.Fatalf("NewStdioServer() error = %v", err)
+	}
+
+	resp, err := s.handleMessage(context.Background(), []byte(`{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "wc", "arguments": {"stdin": "{\"a\": 1}"}}}`))
+	if err != nil {
+		t.Fatalf("handleMessage() error = %v", err)
+	}
+	if result, ok := resp.Result.(*CallToolResult); !ok || strings.TrimSpace(result.Content[0].Text) != "8" {
+		t.Fatalf("expected wc to count the 8 stdin bytes, got %+v", resp)
+	}
+
+	for _, msg := range []string{
+		`{"jsonrpc": "2.0", "id": 2, "method": "tools/call", "params": {"name": "wc", "arguments": {"stdin": "not json"}}}`,
+		`{"jsonrpc": "2.0", "id": 3, "method": "tools/call", "params": {"name": "wc", "arguments": {"stdin": "[` + strings.Repeat(`1,`, 40) + `1]"}}}`,
+		`{"jsonrpc": "2.0", "id": 4, "method": "tools/call", "params": {"name": "cat", "arguments": {"stdin": "data"}}}`,
+	} {
+		resp, err := s.handleMessage(context.Background(), []byte(msg))
+		if err != nil {
+			t.Fatalf("handleMessage() error = %v", err)
+		}
+		if resp.Error == nil || resp.Error.Code != InvalidParams {
+			t.Errorf("expected invalid params for %s, got %+v", msg, resp)
+		}
+	}
+
+	entries, err := database.ListAuditLogs(db.AuditModeStdio, 10, 0)
+	if err != nil {
+		t.Fatalf("ListAuditLogs: %v", err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("expected 4 audit entries, got %d", len(entries))
+	}
+	for _, entry := range entries {
+		if strings.Contains(entry.Params, `"stdin":"`) || !strings.Contains(entry.Params, `"sha256":"`) {
+			t.Errorf("expected stdin to be recorded as a hash, got %s", entry.Params)
+		}
+	}
+}
+
+func TestStdioServer_FileArguments(t *testing.T) {
+	database := newHTTPTestDB(t)
+	s, err := NewStdioServer(&plugin.Config{Tools: map[string]*plugin.Tool{
+		"cat": {Name: "cat", Command: "cat", Sandbox: plugin.SandboxTypeNone,
+			Parameters: []plugin.Parameter{{Name: "file", Type: plugin.ParameterTypeFile, Required: true, MaxBytes: 64}}},
+	}}, "", "", "/tmp", "", database)
+	if err != nil {
+		t.Fatalf("NewStdioServer() error = %v", err)
+	}
+
+	resp, err := s.handleMessage(context.Background(), []byte(`{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "cat", "arguments": {"file": {"type": "resource", "resource": {"uri": "file:///notes.txt", "text": "uploaded"}}}}}`))
+	if err != nil {
+		t.Fatalf("handleMessage() error = %v", err)
+	}
+	result, ok := resp.Result.(*CallToolResult)
+	if !ok || result.Content[0].Text != "uploaded" {
+		t.Fatalf("expected the uploaded file to be read, got %+v", resp)
+	}
+
+	resp, err = s.handleMessage(context.Background(), []byte(`{"jsonrpc": "2.0", "id": 2, "method": "tools/call", "params": {"name": "cat", "arguments": {"file": "not base64"}}}`))
+	if err != nil {
+		t.Fatalf("handleMessage() error = %v", err)
+	}
+	if resp.Error == nil || resp.Error.Code != InvalidParams {
+		t.Errorf("expected invalid params for bad base64, got %+v", resp)
+	}
+
+	entries, err := database.ListAuditLogs(db.AuditModeStdio, 10, 0)
+	if err != nil {
+		t.Fatalf("ListAuditLogs: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 audit entries, got %d", len(entries))
+	}
+	for _, entry := range entries {
+		if strings.Contains(entry.Params, "uploaded") || !strings.Contains(entry.Params, `"sha256":"`) {
+			t.Errorf("expected the file to be recorded as a hash, got %s", entry.Params)
+		}
+	}
+
+	// The scratch directory is removed after the call
+	matches, _ := filepath.Glob(filepath.Join(os.TempDir(), "mcp-gatekeeper-files-*", "file", "notes.txt"))
+	if len(matches) != 0 {
+		t.Errorf("expected input files to be removed, found %v", matches)
+	}
+}
+
+func TestStdioServer_StructuredContent(t *testing.T) {
+	s, err := NewStdioServer(&plugin.Config{Tools: map[string]*plugin.Tool{
+		"csv": {Name: "csv", Command: "cat", Sandbox: plugin.SandboxTypeNone, Stdin: &plugin.StdinConfig{}, OutputFormat: plugin.OutputFormatCSV},
+	}}, "", "", "/tmp", "", nil)
+	if err != nil {
+		t.Fatalf("NewStdioServer() error = %v", err)
+	}
+
+	resp, err := s.handleMessage(context.Background(), []byte(`{"jsonrpc": "2.0", "id": 1, "method": "tools/list"}`))
+	if err != nil {
+		t.Fatalf("handleMessage() error = %v", err)
+	}
+	if list, ok := resp.Result.(*ListToolsResult); !ok || len(list.Tools) != 1 || list.Tools[0].OutputSchema == nil {
+		t.Fatalf("expected the tool listed with an output schema, got %+v", resp.Result)
+	}
+
+	resp, err = s.handleMessage(context.Background(), []byte(`{"jsonrpc": "2.0", "id": 2, "method": "tools/call", "params": {"name": "csv", "arguments": {"stdin": "name,size\na.txt,10\n"}}}`))
+	if err != nil {
+		t.Fatalf("handleMessage() error = %v", err)
+	}
+	result, ok := resp.Result.(*CallToolResult)
+	if !ok || result.IsError {
+		t.Fatalf("expected a successful result, got %+v", resp)
+	}
+	rows, _ := result.StructuredContent["rows"].([]map[string]string)
+	if len(rows) != 1 || rows[0]["name"] != "a.txt" || rows[0]["size"] != "10" {
+		t.Errorf("unexpected structured content %v", result.StructuredContent)
+	}
+
+	resp, err = s.handleMessage(context.Background(), []byte(`{"jsonrpc": "2.0", "id": 3, "method": "tools/call", "params": {"name": "csv", "arguments": {"stdin": "name,size\na.txt\n"}}}`))
+	if err != nil {
+		t.Fatalf("handleMessage() error = %v", err)
+	}
+	if result, ok := resp.Result.(*CallToolResult); !ok || !result.IsError || result.StructuredContent != nil {
+		t.Errorf("expected malformed CSV to be a tool error, got %+v", resp)
+	}
+}
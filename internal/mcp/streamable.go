@@ -27,6 +27,7 @@ type StreamableHandler struct {
 	httpServer     *HTTPServer
 	sessionManager *session.Manager
 	heartbeatInterval time.Duration
+	inflight       *inflightRequests
 }
 
 // NewStreamableHandler creates a new StreamableHandler
@@ -35,6 +36,7 @@ func NewStreamableHandler(httpServer *HTTPServer, sessionTTL time.Duration) *Str
 		httpServer:        httpServer,
 		sessionManager:    session.NewManager(sessionTTL),
 		heartbeatInterval: 30 * time.Second,
+		inflight:          newInflightRequests(),
 	}
 }
 
@@ -99,6 +101,7 @@ func (h *StreamableHandler) HandlePost(w http.ResponseWriter, r *http.Request) {
 
 	// Touch session to update last activity
 	h.sessionManager.Touch(sessionID)
+	r = r.WithContext(withSessionID(r.Context(), sessionID))
 
 	// Handle notifications (no id or null id) - return 202 Accepted
 	if req.ID == nil || string(req.ID) == "null" {
@@ -111,13 +114,26 @@ func (h *StreamableHandler) HandlePost(w http.ResponseWriter, r *http.Request) {
 	var resp *Response
 	switch req.Method {
 	case "tools/list":
-		resp = h.httpServer.handleMCPToolsList(&req)
+		resp = h.httpServer.handleMCPToolsList(r.Context(), &req)
 	case "tools/call":
+		// Track the call so notifications/cancelled can stop it
+		ctx, done, err := h.inflight.start(r.Context(), sess.ID, req.ID)
+		if err != nil {
+			resp = NewErrorResponse(req.ID, InvalidRequest, "Invalid Request", err.Error())
+			break
+		}
+		defer done()
+		r = r.WithContext(ctx)
+		if h.handleToolsCallWithProgress(w, r, sess, &req) {
+			return
+		}
 		resp = h.httpServer.handleMCPToolsCall(r.Context(), &req)
 	case "resources/list":
-		resp = h.httpServer.handleMCPResourcesList(&req)
+		resp = h.httpServer.handleMCPResourcesList(r.Context(), &req)
 	case "resources/read":
-		resp = h.httpServer.handleMCPResourcesRead(&req, sess.ID)
+		resp = h.httpServer.handleMCPResourcesRead(r.Context(), &req, sess.ID)
+	case ExplainMethod:
+		resp = h.httpServer.handleMCPExplain(r.Context(), &req)
 	case "ping":
 		resp = NewResponse(req.ID, struct{}{})
 	default:
@@ -148,7 +164,7 @@ func (h *StreamableHandler) handleInitialize(w http.ResponseWriter, r *http.Requ
 	// Build capabilities
 	caps := ServerCapabilities{
 		Tools: &ToolsCapability{
-			ListChanged: false,
+			ListChanged: h.httpServer.listChanged,
 		},
 	}
 
@@ -182,13 +198,60 @@ func (h *StreamableHandler) handleInitialize(w http.ResponseWriter, r *http.Requ
 	h.writeJSONRPC(w, sess, resp)
 }
 
+// handleToolsCallWithProgress handles a tools/call request carrying a progress token.
+// Output notifications are streamed on an SSE response when the client accepts one,
+// otherwise on the session's GET streams. Reports whether the request was handled.
+func (h *StreamableHandler) handleToolsCallWithProgress(w http.ResponseWriter, r *http.Request, sess *session.Session, req *Request) bool {
+	var params struct {
+		Meta *RequestMeta `json:"_meta,omitempty"`
+	}
+	if err := json.Unmarshal(req.Params, &params); err != nil || !params.Meta.hasProgressToken() {
+		return false
+	}
+
+	if !h.acceptsSSE(r.Header.Get("Accept")) {
+		ctx := withNotifier(r.Context(), func(n *Notification) {
+			sess.Broadcast(&session.SSEEvent{Data: n})
+		})
+		h.writeJSONRPC(w, sess, h.httpServer.handleMCPToolsCall(ctx, req))
+		return true
+	}
+
+	sseWriter, err := sse.NewWriter(w)
+	if err != nil {
+		return false
+	}
+	w.WriteHeader(http.StatusOK)
+	sseWriter.Flush()
+
+	var writeErr error
+	ctx := withNotifier(r.Context(), func(n *Notification) {
+		if writeErr == nil {
+			writeErr = sseWriter.WriteEvent("message", "", n)
+		}
+	})
+	resp := h.httpServer.handleMCPToolsCall(ctx, req)
+	if writeErr == nil {
+		writeErr = sseWriter.WriteEvent("message", "", resp)
+	}
+	if writeErr != nil {
+		fmt.Fprintf(os.Stderr, "[WARN] Failed to write SSE response: %v\n", writeErr)
+	}
+	return true
+}
+
+// Notify sends a server-to-client notification to every session's SSE streams
+func (h *StreamableHandler) Notify(notification *Notification) {
+	h.sessionManager.Broadcast(&session.SSEEvent{Data: notification})
+}
+
 // handleNotification handles MCP notifications
 func (h *StreamableHandler) handleNotification(req *Request, sess *session.Session) {
 	switch req.Method {
 	case "notifications/initialized":
 		// Client is initialized, nothing to do
 	case "notifications/cancelled":
-		// Request cancellation, nothing to do for now
+		h.inflight.cancel(sess.ID, req.Params)
 	default:
 		fmt.Fprintf(os.Stderr, "[WARN] Unknown notification: %s\n", req.Method)
 	}
@@ -292,6 +355,7 @@ func (h *StreamableHandler) HandleDelete(w http.ResponseWriter, r *http.Request)
 		h.writeHTTPError(w, http.StatusNotFound, "session not found")
 		return
 	}
+	h.inflight.cancelScope(sessionID, errSessionTerminated)
 
 	w.WriteHeader(http.StatusNoContent)
 }
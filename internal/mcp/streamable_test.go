@@ -807,3 +807,148 @@ func TestStreamableHandler_MethodNotFound(t *testing.T) {
 		t.Errorf("expected error to contain method name, got %v", resp.Error.Data)
 	}
 }
+
+func TestHTTPServer_SetPlugins_NotifiesSessions(t *testing.T) {
+	s, err := NewHTTPServer(&plugin.Config{Tools: map[string]*plugin.Tool{}}, &HTTPConfig{
+		RateLimit:        100,
+		RateLimitWindow:  time.Minute,
+		RootDir:          "/tmp",
+		EnableStreamable: true,
+		WatchPlugins:     true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create HTTP server: %v", err)
+	}
+
+	// Initialize advertises tools listChanged
+	body := []byte(`{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": {"protocolVersion": "2025-06-18", "capabilities": {}, "clientInfo": {"name": "test", "version": "1.0"}}}`)
+	req := httptest.NewRequest("POST", "/mcp", bytes.NewReader(body))
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	w := httptest.NewRecorder()
+	s.streamableHandler.HandlePost(w, req)
+	if !strings.Contains(w.Body.String(), `"tools":{"listChanged":true}`) {
+		t.Errorf("expected tools listChanged capability, got %s", w.Body.String())
+	}
+
+	sess := s.streamableHandler.sessionManager.Create()
+	eventCh := make(chan *session.SSEEvent, 1)
+	sess.AddSSEChannel(eventCh)
+
+	s.SetPlugins(&plugin.Config{Tools: map[string]*plugin.Tool{
+		"ls": {Name: "ls", Command: "ls", Sandbox: plugin.SandboxTypeNone},
+	}})
+
+	select {
+	case event := <-eventCh:
+		notification, ok := event.Data.(*Notification)
+		if !ok || notification.Method != "notifications/tools/list_changed" {
+			t.Errorf("unexpected event data: %#v", event.Data)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected tools/list_changed notification")
+	}
+
+	listResp := s.handleMCPToolsList(context.Background(), &Request{JSONRPC: "2.0", ID: json.RawMessage(`2`), Method: "tools/list"})
+	if result := listResp.Result.(*ListToolsResult); len(result.Tools) != 1 || result.Tools[0].Name != "ls" {
+		t.Errorf("expected reloaded tool list, got %+v", result.Tools)
+	}
+}
+
+func TestStreamableHandler_ToolsCallProgress(t *testing.T) {
+	handler, httpServer := setupStreamableHandler(t)
+	httpServer.plugins.Store(&plugin.Config{Tools: map[string]*plugin.Tool{
+		"sh": {Name: "sh", Command: "sh", ArgsPrefix: []string{"-c"}, Sandbox: plugin.SandboxTypeNone},
+	}})
+	sess := handler.sessionManager.Create()
+	body := []byte(`{"jsonrpc": "2.0", "id": 3, "method": "tools/call", "params": {"name": "sh", "arguments": {"args": ["echo hello"]}, "_meta": {"progressToken": 42}}}`)
+
+	t.Run("SSE response", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/mcp", bytes.NewReader(body))
+		req.Header.Set("Accept", "application/json, text/event-stream")
+		req.Header.Set(HeaderMcpSessionID, sess.ID)
+		w := httptest.NewRecorder()
+		handler.HandlePost(w, req)
+
+		if ct := w.Header().Get("Content-Type"); ct != "text/event-stream" {
+			t.Fatalf("expected SSE response, got Content-Type %q", ct)
+		}
+		events := strings.Split(strings.TrimSpace(w.Body.String()), "\n\n")
+		if len(events) != 3 {
+			t.Fatalf("expected progress, log and response events, got %q", w.Body.String())
+		}
+		if !strings.Contains(events[0], `"method":"notifications/progress"`) || !strings.Contains(events[0], `"progressToken":42`) {
+			t.Errorf("unexpected progress event: %s", events[0])
+		}
+		if !strings.Contains(events[2], `"id":3`) || !strings.Contains(events[2], `"text":"hello\n"`) {
+			t.Errorf("unexpected response event: %s", events[2])
+		}
+	})
+
+	t.Run("JSON response", func(t *testing.T) {
+		eventCh := make(chan *session.SSEEvent, 10)
+		sess.AddSSEChannel(eventCh)
+		defer sess.RemoveSSEChannel(eventCh)
+
+		req := httptest.NewRequest("POST", "/mcp", bytes.NewReader(body))
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set(HeaderMcpSessionID, sess.ID)
+		w := httptest.NewRecorder()
+		handler.HandlePost(w, req)
+
+		var resp Response
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to parse response: %v", err)
+		}
+		if resp.Error != nil {
+			t.Fatalf("expected no error, got %v", resp.Error)
+		}
+		select {
+		case event := <-eventCh:
+			if n, ok := event.Data.(*Notification); !ok || n.Method != "notifications/progress" {
+				t.Errorf("unexpected event data: %#v", event.Data)
+			}
+		default:
+			t.Fatal("expected progress notification on the session stream")
+		}
+	})
+}
+
+func TestStreamableHandler_CancelToolsCall(t *testing.T) {
+	handler, httpServer := setupStreamableHandler(t)
+	httpServer.plugins.Store(&plugin.Config{Tools: map[string]*plugin.Tool{
+		"sleep": {Name: "sleep", Command: "sleep", Sandbox: plugin.SandboxTypeNone},
+	}})
+	sess := handler.sessionManager.Create()
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/mcp", strings.NewReader(body))
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set(HeaderMcpSessionID, sess.ID)
+		w := httptest.NewRecorder()
+		handler.HandlePost(w, req)
+		return w
+	}
+
+	respCh := make(chan *httptest.ResponseRecorder, 1)
+	go func() {
+		respCh <- post(`{"jsonrpc": "2.0", "id": "call-1", "method": "tools/call", "params": {"name": "sleep", "arguments": {"args": ["30"]}}}`)
+	}()
+	time.Sleep(200 * time.Millisecond)
+
+	if w := post(`{"jsonrpc": "2.0", "method": "notifications/cancelled", "params": {"requestId": "call-1"}}`); w.Code != http.StatusAccepted {
+		t.Fatalf("expected status %d, got %d", http.StatusAccepted, w.Code)
+	}
+
+	select {
+	case w := <-respCh:
+		var resp Response
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to parse response: %v", err)
+		}
+		if resp.Error == nil || resp.Error.Message != "Execution cancelled" {
+			t.Errorf("expected cancellation error, got %+v", resp)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("tools/call did not return after cancellation")
+	}
+}
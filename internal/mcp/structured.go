@@ -0,0 +1,142 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/takeshy/mcp-gatekeeper/internal/executor"
+	"github.com/takeshy/mcp-gatekeeper/internal/plugin"
+)
+
+// BuildOutputSchema returns the schema of a tool's structured content, or nil if the
+// tool has no output_format
+func BuildOutputSchema(t *plugin.Tool) *OutputSchema {
+	switch t.OutputFormat {
+	case plugin.OutputFormatJSON:
+		// A JSON object is returned as is, any other value as {"result": value}
+		return &OutputSchema{Type: "object"}
+	case plugin.OutputFormatCSV:
+		return &OutputSchema{
+			Type: "object",
+			Properties: map[string]Property{
+				"columns": {Type: "array", Description: "Header row", Items: &Items{Type: "string"}},
+				"rows":    {Type: "array", Description: "Data rows keyed by column", Items: &Items{Type: "object"}},
+			},
+			Required: []string{"columns", "rows"},
+		}
+	case plugin.OutputFormatLines:
+		return &OutputSchema{
+			Type: "object",
+			Properties: map[string]Property{
+				"lines": {Type: "array", Description: "Output lines", Items: &Items{Type: "string"}},
+			},
+			Required: []string{"lines"},
+		}
+	}
+	return nil
+}
+
+// parseStructuredContent parses a tool's stdout according to its output_format
+func parseStructuredContent(format plugin.OutputFormat, stdout string) (map[string]interface{}, error) {
+	switch format {
+	case plugin.OutputFormatJSON:
+		return parseJSONOutput(stdout)
+	case plugin.OutputFormatCSV:
+		return parseCSVOutput(stdout)
+	case plugin.OutputFormatLines:
+		lines := []string{}
+		if trimmed := strings.TrimSuffix(stdout, "\n"); trimmed != "" {
+			for _, line := range strings.Split(trimmed, "\n") {
+				lines = append(lines, strings.TrimSuffix(line, "\r"))
+			}
+		}
+		return map[string]interface{}{"lines": lines}, nil
+	}
+	return nil, fmt.Errorf("unknown output format %q", format)
+}
+
+// parseJSONOutput parses a single JSON value, wrapping values other than objects as {"result": value}
+func parseJSONOutput(stdout string) (map[string]interface{}, error) {
+	dec := json.NewDecoder(strings.NewReader(stdout))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		if err == io.EOF {
+			return nil, fmt.Errorf("output is empty")
+		}
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after the JSON value")
+	}
+	if obj, ok := value.(map[string]interface{}); ok {
+		return obj, nil
+	}
+	return map[string]interface{}{"result": value}, nil
+}
+
+// parseCSVOutput parses CSV with a header row into columns and rows keyed by column
+func parseCSVOutput(stdout string) (map[string]interface{}, error) {
+	records, err := csv.NewReader(bytes.NewReader([]byte(stdout))).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	columns := []string{}
+	rows := []map[string]string{}
+	if len(records) > 0 {
+		columns = records[0]
+		for _, record := range records[1:] {
+			row := make(map[string]string, len(columns))
+			for i, column := range columns {
+				row[column] = record[i]
+			}
+			rows = append(rows, row)
+		}
+	}
+	return map[string]interface{}{"columns": columns, "rows": rows}, nil
+}
+
+// toolCallResult builds the tools/call result of an execution after applying the tool's
+// output filters. For a tool with an output_format, successful output is also returned
+// as structured content; output that does not parse makes the result a tool error.
+func toolCallResult(tool *plugin.Tool, result *executor.ExecuteResult) *CallToolResult {
+	stdout, stderr, filters := tool.FilterOutput(result.Stdout, result.Stderr)
+	res := &CallToolResult{
+		Content: []Content{
+			{
+				Type: "text",
+				Text: stdout,
+			},
+		},
+		IsError: result.ExitCode != 0,
+		Metadata: &ResultMetadata{
+			ExitCode:  result.ExitCode,
+			Stderr:    stderr,
+			Workspace: result.Workspace,
+			Filters:   filters,
+		},
+		Meta: BuildResultMeta(tool, stdout),
+	}
+	if tool.OutputFormat == plugin.OutputFormatNone || res.IsError {
+		return res
+	}
+
+	structured, err := parseStructuredContent(tool.OutputFormat, stdout)
+	if result.StdoutTruncated {
+		err = fmt.Errorf("output was truncated")
+	}
+	if err != nil {
+		res.IsError = true
+		res.Content = append(res.Content, Content{
+			Type: "text",
+			Text: fmt.Sprintf("Failed to parse output as %s: %v", tool.OutputFormat, err),
+		})
+		return res
+	}
+	res.StructuredContent = structured
+	return res
+}
@@ -0,0 +1,131 @@
+package mcp
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/takeshy/mcp-gatekeeper/internal/executor"
+	"github.com/takeshy/mcp-gatekeeper/internal/plugin"
+)
+
+func TestParseStructuredContent(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  plugin.OutputFormat
+		stdout  string
+		want    string // structured content as JSON
+		wantErr bool
+	}{
+		{"json object", plugin.OutputFormatJSON, `{"a": 1, "b": [true]}` + "\n", `{"a":1,"b":[true]}`, false},
+		{"json array", plugin.OutputFormatJSON, `[{"a": 1}, {"a": 2}]`, `{"result":[{"a":1},{"a":2}]}`, false},
+		{"json large number", plugin.OutputFormatJSON, `{"id": 12345678901234567890}`, `{"id":12345678901234567890}`, false},
+		{"json scalar", plugin.OutputFormatJSON, `"text"`, `{"result":"text"}`, false},
+		{"json invalid", plugin.OutputFormatJSON, `{"a": `, "", true},
+		{"json trailing data", plugin.OutputFormatJSON, `{"a": 1} {"a": 2}`, "", true},
+		{"json empty", plugin.OutputFormatJSON, "", "", true},
+		{"csv", plugin.OutputFormatCSV, "name,size\na.txt,10\n\"b, c.txt\",20\n", `{"columns":["name","size"],"rows":[{"name":"a.txt","size":"10"},{"name":"b, c.txt","size":"20"}]}`, false},
+		{"csv header only", plugin.OutputFormatCSV, "name,size\n", `{"columns":["name","size"],"rows":[]}`, false},
+		{"csv empty", plugin.OutputFormatCSV, "", `{"columns":[],"rows":[]}`, false},
+		{"csv ragged", plugin.OutputFormatCSV, "name,size\na.txt\n", "", true},
+		{"lines", plugin.OutputFormatLines, "one\r\ntwo\n\nthree\n", `{"lines":["one","two","","three"]}`, false},
+		{"lines empty", plugin.OutputFormatLines, "", `{"lines":[]}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseStructuredContent(tt.format, tt.stdout)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseStructuredContent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			data, err := json.Marshal(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("parseStructuredContent() = %s, want %s", data, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildOutputSchema(t *testing.T) {
+	if schema := BuildOutputSchema(&plugin.Tool{}); schema != nil {
+		t.Errorf("expected no output schema without output_format, got %+v", schema)
+	}
+	for _, format := range []plugin.OutputFormat{plugin.OutputFormatJSON, plugin.OutputFormatCSV, plugin.OutputFormatLines} {
+		schema := BuildOutputSchema(&plugin.Tool{OutputFormat: format})
+		if schema == nil || schema.Type != "object" {
+			t.Errorf("%s: expected an object schema, got %+v", format, schema)
+		}
+	}
+	if schema := BuildOutputSchema(&plugin.Tool{OutputFormat: plugin.OutputFormatCSV}); !reflect.DeepEqual(schema.Required, []string{"columns", "rows"}) {
+		t.Errorf("expected csv columns and rows required, got %v", schema.Required)
+	}
+}
+
+func TestToolCallResult(t *testing.T) {
+	tool := &plugin.Tool{Name: "ls", OutputFormat: plugin.OutputFormatLines}
+
+	t.Run("structured", func(t *testing.T) {
+		res := toolCallResult(tool, &executor.ExecuteResult{Stdout: "a\nb\n"})
+		if res.IsError || len(res.Content) != 1 || res.Content[0].Text != "a\nb\n" {
+			t.Fatalf("expected the output as text, got %+v", res)
+		}
+		if !reflect.DeepEqual(res.StructuredContent, map[string]interface{}{"lines": []string{"a", "b"}}) {
+			t.Errorf("StructuredContent = %v", res.StructuredContent)
+		}
+	})
+
+	t.Run("failed command", func(t *testing.T) {
+		res := toolCallResult(tool, &executor.ExecuteResult{Stdout: "a\n", ExitCode: 2})
+		if !res.IsError || res.StructuredContent != nil || res.Metadata.ExitCode != 2 {
+			t.Errorf("expected an error without structured content, got %+v", res)
+		}
+	})
+
+	t.Run("parse failure", func(t *testing.T) {
+		jsonTool := &plugin.Tool{Name: "jq", OutputFormat: plugin.OutputFormatJSON}
+		res := toolCallResult(jsonTool, &executor.ExecuteResult{Stdout: "not json"})
+		if !res.IsError || res.StructuredContent != nil || len(res.Content) != 2 {
+			t.Errorf("expected a tool error with the parse failure, got %+v", res)
+		}
+	})
+
+	t.Run("truncated output", func(t *testing.T) {
+		res := toolCallResult(tool, &executor.ExecuteResult{Stdout: "a\n[output truncated, exceeded 2 bytes]", StdoutTruncated: true})
+		if !res.IsError || res.StructuredContent != nil {
+			t.Errorf("expected truncated output to be a tool error, got %+v", res)
+		}
+	})
+
+	t.Run("output filters", func(t *testing.T) {
+		filtered := &plugin.Tool{Name: "env", OutputFormat: plugin.OutputFormatJSON, OutputFilters: []plugin.OutputFilter{
+			{Type: plugin.OutputFilterRedact, Pattern: `secret-\w+`},
+			{Type: plugin.OutputFilterJSON, Fields: []string{".user"}},
+		}}
+		if err := filtered.ValidateOutputFilters(); err != nil {
+			t.Fatalf("ValidateOutputFilters() error = %v", err)
+		}
+		res := toolCallResult(filtered, &executor.ExecuteResult{Stdout: `{"user": "a", "token": "t"}`, Stderr: "using secret-abc"})
+		if res.IsError || res.Content[0].Text != `{"user":"a"}`+"\n" || res.Metadata.Stderr != "using [REDACTED]" {
+			t.Fatalf("expected filtered output, got %+v", res)
+		}
+		if !reflect.DeepEqual(res.StructuredContent, map[string]interface{}{"user": "a"}) {
+			t.Errorf("expected structured content parsed from the filtered output, got %v", res.StructuredContent)
+		}
+		if len(res.Metadata.Filters) != 2 || res.Metadata.Filters[0].Redactions != 1 {
+			t.Errorf("expected a report per filter, got %+v", res.Metadata.Filters)
+		}
+	})
+
+	t.Run("no output format", func(t *testing.T) {
+		res := toolCallResult(&plugin.Tool{Name: "cat"}, &executor.ExecuteResult{Stdout: "{}"})
+		if res.IsError || res.StructuredContent != nil {
+			t.Errorf("expected plain text only, got %+v", res)
+		}
+	})
+}
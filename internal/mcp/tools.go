@@ -0,0 +1,180 @@
+package mcp
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/takeshy/mcp-gatekeeper/internal/executor"
+	"github.com/takeshy/mcp-gatekeeper/internal/plugin"
+)
+
+// newToolsListChangedNotification creates the notification sent when the tool list changes
+func newToolsListChangedNotification() *Notification {
+	return &Notification{
+		JSONRPC: "2.0",
+		Method:  "notifications/tools/list_changed",
+	}
+}
+
+// BuildInputSchema builds the JSON schema describing a tool's arguments
+func BuildInputSchema(t *plugin.Tool) InputSchema {
+	props := map[string]Property{}
+	// Only include cwd property if the tool allows changing working directory
+	if !t.FixedCwd {
+		props["cwd"] = Property{
+			Type:        "string",
+			Description: "Working directory for the command (defaults to root directory)",
+		}
+	}
+
+	if t.Stdin != nil {
+		props["stdin"] = stdinProperty(t.Stdin)
+	}
+
+	// Tools with typed parameters expose each parameter instead of a generic args array
+	if t.HasParameters() {
+		required := []string{}
+		for _, p := range t.Parameters {
+			props[p.Name] = parameterProperty(p)
+			if p.Required {
+				required = append(required, p.Name)
+			}
+		}
+		additional := false
+		return InputSchema{
+			Type:                 "object",
+			Properties:           props,
+			Required:             required,
+			AdditionalProperties: &additional,
+		}
+	}
+
+	// Only include args property if the tool accepts arguments
+	// A tool with allowed_arg_globs = [""] means it accepts no arguments
+	noArgs := len(t.AllowedArgGlobs) == 1 && t.AllowedArgGlobs[0] == ""
+	if !noArgs {
+		props["args"] = Property{
+			Type:        "array",
+			Description: "Command arguments",
+			Items:       &Items{Type: "string"},
+		}
+	}
+
+	return InputSchema{
+		Type:       "object",
+		Properties: props,
+		Required:   []string{},
+	}
+}
+
+// parameterProperty converts a plugin parameter to a JSON schema property
+func parameterProperty(p plugin.Parameter) Property {
+	prop := Property{
+		Description: p.Description,
+		Default:     p.Default,
+	}
+	switch p.Type {
+	case plugin.ParameterTypeInteger:
+		prop.Type = "integer"
+	case plugin.ParameterTypeBoolean:
+		prop.Type = "boolean"
+	case plugin.ParameterTypeEnum:
+		prop.Type = "string"
+		prop.Enum = p.Enum
+	case plugin.ParameterTypeFile:
+		if prop.Description == "" {
+			prop.Description = "File content"
+		}
+		prop.Description += fmt.Sprintf(" (base64 or an embedded resource, max %d bytes)", p.FileLimit())
+		prop.AnyOf = []Property{
+			{Type: "string", ContentEncoding: "base64"},
+			{Type: "object", Description: "Embedded resource or resource contents with text or blob"},
+		}
+	default:
+		prop.Type = "string"
+	}
+	if p.Pattern != "" && p.Type == plugin.ParameterTypeString {
+		prop.Pattern = plugin.AnchorPattern(p.Pattern)
+	}
+	return prop
+}
+
+// stdinProperty describes the stdin argument of a tool that accepts input
+func stdinProperty(c *plugin.StdinConfig) Property {
+	desc := c.Description
+	if desc == "" {
+		desc = "Data written to the command's standard input"
+	}
+	desc += fmt.Sprintf(" (max %d bytes", c.Limit())
+	if c.ContentType != "" {
+		desc += ", " + c.ContentType
+	}
+	return Property{Type: "string", Description: desc + ")"}
+}
+
+// maxToolArgs is the most arguments a tools/call may pass in "args", which bounds the work of matching them
+const maxToolArgs = 1024
+
+// parseToolArguments extracts the working directory and user arguments (before args_prefix)
+// from tools/call arguments. Tools with typed parameters have their arguments validated
+// and mapped to argv through the tool's argv template, with file arguments stored by addFile.
+func parseToolArguments(t *plugin.Tool, arguments map[string]interface{}, addFile plugin.FileFunc) (string, []string, error) {
+	cwd, _ := arguments["cwd"].(string)
+
+	if t.HasParameters() {
+		cmdArgs, err := t.BuildArgs(arguments, addFile)
+		if err != nil {
+			return "", nil, err
+		}
+		return cwd, cmdArgs, nil
+	}
+
+	var cmdArgs []string
+	if argsRaw, ok := arguments["args"].([]interface{}); ok {
+		if len(argsRaw) > maxToolArgs {
+			return "", nil, fmt.Errorf("too many arguments (%d, max %d)", len(argsRaw), maxToolArgs)
+		}
+		for _, a := range argsRaw {
+			if str, ok := a.(string); ok {
+				cmdArgs = append(cmdArgs, str)
+			}
+		}
+	}
+	return cwd, cmdArgs, nil
+}
+
+// resolveCwd returns the absolute working directory of a call, which defaults to rootDir
+// and must be within the root directory
+func resolveCwd(exec *executor.Executor, rootDir, cwd string) (string, error) {
+	if cwd == "" {
+		return rootDir, nil
+	}
+	cwd, err := filepath.Abs(cwd)
+	if err != nil {
+		return "", fmt.Errorf("invalid cwd: %w", err)
+	}
+	if err := exec.ValidateCwd(cwd); err != nil {
+		return "", err
+	}
+	return cwd, nil
+}
+
+// parseToolStdin returns the stdin argument of a tools/call, checked against the tool's
+// stdin configuration. Tools without one reject the argument.
+func parseToolStdin(t *plugin.Tool, arguments map[string]interface{}) (string, error) {
+	raw, ok := arguments["stdin"]
+	if !ok {
+		return "", nil
+	}
+	if t.Stdin == nil {
+		return "", fmt.Errorf("tool does not accept stdin")
+	}
+	data, ok := raw.(string)
+	if !ok {
+		return "", fmt.Errorf("stdin must be a string")
+	}
+	if err := t.Stdin.Check(data); err != nil {
+		return "", err
+	}
+	return data, nil
+}
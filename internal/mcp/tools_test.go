@@ -0,0 +1,126 @@
+package mcp
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/takeshy/mcp-gatekeeper/internal/plugin"
+)
+
+func TestBuildInputSchema(t *testing.T) {
+	t.Run("generic args", func(t *testing.T) {
+		schema := BuildInputSchema(&plugin.Tool{Name: "ls", AllowedArgGlobs: []string{"*"}})
+		if _, ok := schema.Properties["args"]; !ok {
+			t.Error("expected args property")
+		}
+		if _, ok := schema.Properties["cwd"]; !ok {
+			t.Error("expected cwd property")
+		}
+		if schema.AdditionalProperties != nil {
+			t.Error("expected additionalProperties to be unset")
+		}
+	})
+
+	t.Run("no args tool", func(t *testing.T) {
+		schema := BuildInputSchema(&plugin.Tool{Name: "pwd", AllowedArgGlobs: []string{""}, FixedCwd: true})
+		if len(schema.Properties) != 0 {
+			t.Errorf("expected no properties, got %v", schema.Properties)
+		}
+	})
+
+	t.Run("stdin", func(t *testing.T) {
+		schema := BuildInputSchema(&plugin.Tool{Name: "jq", Stdin: &plugin.StdinConfig{MaxBytes: 4096, ContentType: plugin.StdinContentTypeJSON}})
+		got, ok := schema.Properties["stdin"]
+		if !ok || got.Type != "string" || !strings.HasSuffix(got.Description, "(max 4096 bytes, json)") {
+			t.Errorf("stdin property = %+v", got)
+		}
+		if _, ok := BuildInputSchema(&plugin.Tool{Name: "ls"}).Properties["stdin"]; ok {
+			t.Error("expected no stdin property without stdin config")
+		}
+	})
+
+	t.Run("file parameter", func(t *testing.T) {
+		schema := BuildInputSchema(&plugin.Tool{Name: "patch", Parameters: []plugin.Parameter{{Name: "diff", Type: plugin.ParameterTypeFile, MaxBytes: 1024}}})
+		got := schema.Properties["diff"]
+		if got.Type != "" || len(got.AnyOf) != 2 || got.AnyOf[0].ContentEncoding != "base64" || got.AnyOf[1].Type != "object" {
+			t.Errorf("diff property = %+v", got)
+		}
+		if !strings.Contains(got.Description, "max 1024 bytes") {
+			t.Errorf("diff description = %q", got.Description)
+		}
+	})
+
+	t.Run("typed parameters", func(t *testing.T) {
+		tool := &plugin.Tool{
+			Name: "git-log",
+			Parameters: []plugin.Parameter{
+				{Name: "count", Type: plugin.ParameterTypeInteger, Flag: "-n", Default: float64(10)},
+				{Name: "oneline", Type: plugin.ParameterTypeBoolean, Flag: "--oneline"},
+				{Name: "order", Type: plugin.ParameterTypeEnum, Enum: []string{"date", "topo"}},
+				{Name: "path", Type: plugin.ParameterTypeString, Required: true, Pattern: `[a-z/]+`},
+			},
+		}
+		schema := BuildInputSchema(tool)
+
+		if _, ok := schema.Properties["args"]; ok {
+			t.Error("expected no args property for typed tool")
+		}
+		if got := schema.Properties["count"]; got.Type != "integer" || got.Default != float64(10) {
+			t.Errorf("count property = %+v", got)
+		}
+		if got := schema.Properties["oneline"]; got.Type != "boolean" {
+			t.Errorf("oneline property = %+v", got)
+		}
+		if got := schema.Properties["order"]; got.Type != "string" || !reflect.DeepEqual(got.Enum, []string{"date", "topo"}) {
+			t.Errorf("order property = %+v", got)
+		}
+		if got := schema.Properties["path"]; got.Pattern != "^(?:[a-z/]+)$" {
+			t.Errorf("path pattern = %q", got.Pattern)
+		}
+		if !reflect.DeepEqual(schema.Required, []string{"path"}) {
+			t.Errorf("required = %v, want [path]", schema.Required)
+		}
+		if schema.AdditionalProperties == nil || *schema.AdditionalProperties {
+			t.Error("expected additionalProperties to be false")
+		}
+	})
+}
+
+func TestParseToolArguments(t *testing.T) {
+	generic := &plugin.Tool{Name: "ls"}
+	cwd, args, err := parseToolArguments(generic, map[string]interface{}{
+		"cwd":  "/tmp",
+		"args": []interface{}{"-la", 1, "src"},
+	}, nil)
+	if err != nil {
+		t.Fatalf("parseToolArguments() error = %v", err)
+	}
+	if cwd != "/tmp" || !reflect.DeepEqual(args, []string{"-la", "src"}) {
+		t.Errorf("parseToolArguments() = %q, %q", cwd, args)
+	}
+
+	tooMany := make([]interface{}, maxToolArgs+1)
+	for i := range tooMany {
+		tooMany[i] = "x"
+	}
+	if _, _, err := parseToolArguments(generic, map[string]interface{}{"args": tooMany}, nil); err == nil {
+		t.Error("parseToolArguments() expected error for too many arguments")
+	}
+
+	typed := &plugin.Tool{
+		Name:       "grep",
+		Parameters: []plugin.Parameter{{Name: "pattern", Type: plugin.ParameterTypeString, Required: true, Flag: "-e"}},
+	}
+	_, args, err = parseToolArguments(typed, map[string]interface{}{"pattern": "TODO"}, nil)
+	if err != nil {
+		t.Fatalf("parseToolArguments() error = %v", err)
+	}
+	if !reflect.DeepEqual(args, []string{"-e", "TODO"}) {
+		t.Errorf("parseToolArguments() args = %q", args)
+	}
+
+	if _, _, err := parseToolArguments(typed, map[string]interface{}{}, nil); err == nil {
+		t.Error("parseToolArguments() expected error for missing required parameter")
+	}
+}
@@ -2,6 +2,9 @@ package mcp
 
 import (
 	"encoding/json"
+
+	"github.com/takeshy/mcp-gatekeeper/internal/executor"
+	"github.com/takeshy/mcp-gatekeeper/internal/plugin"
 )
 
 // JSON-RPC 2.0 types
@@ -43,6 +46,7 @@ const (
 	Unauthorized    = -32001
 	PolicyDenied    = -32002
 	ExecutionFailed = -32003
+	QueueFull       = -32004
 )
 
 // Notification represents a JSON-RPC 2.0 notification (request without id)
@@ -115,25 +119,34 @@ type ServerInfo struct {
 
 // Tool represents an MCP tool
 type Tool struct {
-	Name        string                 `json:"name"`
-	Description string                 `json:"description,omitempty"`
-	InputSchema InputSchema            `json:"inputSchema"`
-	Meta        map[string]interface{} `json:"_meta,omitempty"`
+	Name         string                 `json:"name"`
+	Description  string                 `json:"description,omitempty"`
+	InputSchema  InputSchema            `json:"inputSchema"`
+	OutputSchema *OutputSchema          `json:"outputSchema,omitempty"` // Schema of structuredContent (tools with an output_format)
+	Meta         map[string]interface{} `json:"_meta,omitempty"`
 }
 
 // InputSchema represents the JSON schema for tool input
 type InputSchema struct {
-	Type       string              `json:"type"`
-	Properties map[string]Property `json:"properties,omitempty"`
-	Required   []string            `json:"required,omitempty"`
+	Type                 string              `json:"type"`
+	Properties           map[string]Property `json:"properties,omitempty"`
+	Required             []string            `json:"required,omitempty"`
+	AdditionalProperties *bool               `json:"additionalProperties,omitempty"`
 }
 
+// OutputSchema represents the JSON schema of a tool's structured content
+type OutputSchema = InputSchema
+
 // Property represents a property in JSON schema
 type Property struct {
-	Type        string   `json:"type"`
-	Description string   `json:"description,omitempty"`
-	Items       *Items   `json:"items,omitempty"`
-	Enum        []string `json:"enum,omitempty"`
+	Type            string      `json:"type,omitempty"` // Unset when AnyOf lists the accepted types
+	Description     string      `json:"description,omitempty"`
+	Items           *Items      `json:"items,omitempty"`
+	Enum            []string    `json:"enum,omitempty"`
+	Pattern         string      `json:"pattern,omitempty"`
+	Default         interface{} `json:"default,omitempty"`
+	ContentEncoding string      `json:"contentEncoding,omitempty"`
+	AnyOf           []Property  `json:"anyOf,omitempty"`
 }
 
 // Items represents array items in JSON schema
@@ -150,20 +163,49 @@ type ListToolsResult struct {
 type CallToolParams struct {
 	Name      string                 `json:"name"`
 	Arguments map[string]interface{} `json:"arguments,omitempty"`
+	Meta      *RequestMeta           `json:"_meta,omitempty"`
+}
+
+// RequestMeta represents the _meta object of a request
+type RequestMeta struct {
+	ProgressToken json.RawMessage `json:"progressToken,omitempty"`
+}
+
+// hasProgressToken reports whether the caller asked for progress notifications
+func (m *RequestMeta) hasProgressToken() bool {
+	return m != nil && len(m.ProgressToken) > 0 && string(m.ProgressToken) != "null"
+}
+
+// ProgressParams represents params for notifications/progress
+type ProgressParams struct {
+	ProgressToken json.RawMessage `json:"progressToken"`
+	Progress      float64         `json:"progress"`
+	Total         float64         `json:"total,omitempty"`
+	Message       string          `json:"message,omitempty"`
+}
+
+// LogMessageParams represents params for notifications/message
+type LogMessageParams struct {
+	Level  string      `json:"level"`
+	Logger string      `json:"logger,omitempty"`
+	Data   interface{} `json:"data"`
 }
 
 // CallToolResult represents the result of tools/call request
 type CallToolResult struct {
-	Content  []Content              `json:"content"`
-	IsError  bool                   `json:"isError,omitempty"`
-	Metadata *ResultMetadata        `json:"metadata,omitempty"`
-	Meta     map[string]interface{} `json:"_meta,omitempty"`
+	Content           []Content              `json:"content"`
+	StructuredContent map[string]interface{} `json:"structuredContent,omitempty"` // Parsed stdout (tools with an output_format)
+	IsError           bool                   `json:"isError,omitempty"`
+	Metadata          *ResultMetadata        `json:"metadata,omitempty"`
+	Meta              map[string]interface{} `json:"_meta,omitempty"`
 }
 
 // ResultMetadata contains execution metadata
 type ResultMetadata struct {
-	ExitCode int    `json:"exitCode"`
-	Stderr   string `json:"stderr,omitempty"`
+	ExitCode  int                         `json:"exitCode"`
+	Stderr    string                      `json:"stderr,omitempty"`
+	Workspace *executor.WorkspaceResult   `json:"workspace,omitempty"` // Changes made in an overlay workspace
+	Filters   []plugin.OutputFilterReport `json:"filters,omitempty"`   // What the tool's output filters removed
 }
 
 // Content represents content in tool result
@@ -154,11 +154,18 @@ type TemplateData struct {
 	SessionID  string      // MCP Streamable HTTP session ID (empty if not using streamable)
 }
 
-func generateCustomUI(templatePath string, output string, sessionID string) (string, error) {
+// ValidateTemplate checks that a custom UI template can be read and parsed
+func ValidateTemplate(templatePath string) error {
+	_, err := parseCustomTemplate(templatePath)
+	return err
+}
+
+// parseCustomTemplate reads and parses a custom UI template with the template helper functions
+func parseCustomTemplate(templatePath string) (*template.Template, error) {
 	// Read template file
 	tmplContent, err := os.ReadFile(templatePath)
 	if err != nil {
-		return "", fmt.Errorf("failed to read template file: %w", err)
+		return nil, fmt.Errorf("failed to read template file: %w", err)
 	}
 
 	// Parse template
@@ -191,7 +198,15 @@ func generateCustomUI(templatePath string, output string, sessionID string) (str
 		"trimSpace": strings.TrimSpace,
 	}).Parse(string(tmplContent))
 	if err != nil {
-		return "", fmt.Errorf("failed to parse template: %w", err)
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+	return tmpl, nil
+}
+
+func generateCustomUI(templatePath string, output string, sessionID string) (string, error) {
+	tmpl, err := parseCustomTemplate(templatePath)
+	if err != nil {
+		return "", err
 	}
 
 	// Prepare template data
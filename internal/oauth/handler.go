@@ -3,6 +3,7 @@ package oauth
 import (
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -55,6 +56,7 @@ type TokenResponse struct {
 	TokenType    string `json:"token_type"`
 	ExpiresIn    int    `json:"expires_in"`
 	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
 }
 
 // ErrorResponse represents an OAuth error response
@@ -122,7 +124,19 @@ func (h *Handler) handleClientCredentialsGrant(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	accessToken, refreshToken, err := h.db.CreateToken(client.ID)
+	// Grant the requested scopes, or all of the client's scopes if none are requested
+	scopes := db.ParseScope(r.FormValue("scope"))
+	if len(scopes) == 0 {
+		scopes = client.Scopes
+	} else if err := db.ValidateScopes(scopes); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid_scope", err.Error())
+		return
+	} else if !db.ScopesCover(client.Scopes, scopes) {
+		h.writeError(w, http.StatusBadRequest, "invalid_scope", "requested scope exceeds the client's allowed scopes")
+		return
+	}
+
+	accessToken, refreshToken, err := h.db.CreateToken(client.ID, scopes)
 	if err != nil {
 		h.writeError(w, http.StatusInternalServerError, "server_error", "failed to create tokens")
 		return
@@ -136,6 +150,7 @@ func (h *Handler) handleClientCredentialsGrant(w http.ResponseWriter, r *http.Re
 		TokenType:    "Bearer",
 		ExpiresIn:    int(db.AccessTokenExpiration.Seconds()),
 		RefreshToken: refreshToken,
+		Scope:        db.FormatScope(scopes),
 	})
 }
 
@@ -168,8 +183,19 @@ func (h *Handler) handleRefreshTokenGrant(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	// Requested scopes may only narrow the original grant
+	scopes := db.ParseScope(r.FormValue("scope"))
+	if err := db.ValidateScopes(scopes); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid_scope", err.Error())
+		return
+	}
+
 	// Refresh tokens
-	newAccessToken, newRefreshToken, err := h.db.RefreshToken(refreshToken, client.ID)
+	newAccessToken, newRefreshToken, err := h.db.RefreshToken(refreshToken, client.ID, scopes)
+	if errors.Is(err, db.ErrInvalidScope) {
+		h.writeError(w, http.StatusBadRequest, "invalid_scope", err.Error())
+		return
+	}
 	if err != nil {
 		h.writeError(w, http.StatusBadRequest, "invalid_grant", err.Error())
 		return
@@ -282,15 +308,16 @@ func (h *Handler) authorizationServerBaseURL(r *http.Request) string {
 }
 
 // ValidateAccessToken validates an access token from the Authorization header
-func (h *Handler) ValidateAccessToken(r *http.Request) (*db.OAuthClient, error) {
+// and returns the client and the scopes granted to the token
+func (h *Handler) ValidateAccessToken(r *http.Request) (*db.OAuthClient, []string, error) {
 	authHeader := r.Header.Get("Authorization")
 	if authHeader == "" {
-		return nil, nil
+		return nil, nil, nil
 	}
 
 	parts := strings.SplitN(authHeader, " ", 2)
 	if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
-		return nil, nil
+		return nil, nil, nil
 	}
 
 	return h.db.ValidateAccessToken(parts[1])
@@ -37,7 +37,7 @@ func newTestDB(t *testing.T) *db.DB {
 
 func TestTokenEndpointClientSecretBasic(t *testing.T) {
 	database := newTestDB(t)
-	clientSecret, err := database.CreateOAuthClient("basic-client")
+	clientSecret, err := database.CreateOAuthClient("basic-client", nil)
 	if err != nil {
 		t.Fatalf("CreateOAuthClient: %v", err)
 	}
@@ -80,6 +80,75 @@ func TestTokenEndpointClientSecretBasic(t *testing.T) {
 	}
 }
 
+func TestTokenEndpointScopes(t *testing.T) {
+	database := newTestDB(t)
+	clientSecret, err := database.CreateOAuthClient("scoped-client", []string{"tools:git-*"})
+	if err != nil {
+		t.Fatalf("CreateOAuthClient: %v", err)
+	}
+
+	handler := NewHandler(database, "")
+	server := httptest.NewServer(handler.Router())
+	defer server.Close()
+
+	requestToken := func(scope string) *http.Response {
+		t.Helper()
+		form := url.Values{}
+		form.Set("grant_type", "client_credentials")
+		form.Set("client_id", "scoped-client")
+		form.Set("client_secret", clientSecret)
+		if scope != "" {
+			form.Set("scope", scope)
+		}
+		resp, err := http.PostForm(server.URL+"/oauth/token", form)
+		if err != nil {
+			t.Fatalf("do request: %v", err)
+		}
+		return resp
+	}
+
+	tests := []struct {
+		name       string
+		scope      string
+		wantStatus int
+		wantScope  string
+	}{
+		{"default to client scopes", "", http.StatusOK, "tools:git-*"},
+		{"narrower scope", "tools:git-status", http.StatusOK, "tools:git-status"},
+		{"wider scope", "tools:*", http.StatusBadRequest, ""},
+		{"malformed scope", "admin", http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := requestToken(tt.scope)
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, resp.StatusCode)
+			}
+			if tt.wantStatus != http.StatusOK {
+				var errResp ErrorResponse
+				if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil {
+					t.Fatalf("decode response: %v", err)
+				}
+				if errResp.Error != "invalid_scope" {
+					t.Fatalf("expected error invalid_scope, got %q", errResp.Error)
+				}
+				return
+			}
+
+			var tokenResp TokenResponse
+			if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if tokenResp.Scope != tt.wantScope {
+				t.Fatalf("expected scope %q, got %q", tt.wantScope, tokenResp.Scope)
+			}
+		})
+	}
+}
+
 func TestOAuthMetadataAuthMethodsIncludesBasic(t *testing.T) {
 	handler := NewHandler(newTestDB(t), "")
 	server := httptest.NewServer(handler.Router())
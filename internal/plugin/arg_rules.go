@@ -0,0 +1,74 @@
+package plugin
+
+import (
+	"fmt"
+)
+
+// ArgRule restricts a single argument position (or every remaining argument)
+// to its own allow/deny glob lists. Arguments are matched individually, so
+// ["a b"] and ["a", "b"] are evaluated differently.
+type ArgRule struct {
+	Position *int     `json:"position,omitempty"` // Zero-based index into the user arguments
+	Rest     bool     `json:"rest,omitempty"`     // Applies to every argument without a positional rule
+	Allow    []string `json:"allow,omitempty"`    // Glob patterns the argument must match
+	Deny     []string `json:"deny,omitempty"`     // Glob patterns that deny the argument (checked first)
+}
+
+// HasArgRules returns true if the tool uses positional argument rules
+func (t *Tool) HasArgRules() bool {
+	return len(t.ArgRules) > 0
+}
+
+// ArgRuleFor returns the rule that applies to the argument at index i, or nil if none applies
+func (t *Tool) ArgRuleFor(i int) *ArgRule {
+	var rest *ArgRule
+	for j := range t.ArgRules {
+		rule := &t.ArgRules[j]
+		if rule.Position != nil && *rule.Position == i {
+			return rule
+		}
+		if rule.Rest {
+			rest = rule
+		}
+	}
+	return rest
+}
+
+// ValidateArgRules validates the structure of the tool's argument rules
+func (t *Tool) ValidateArgRules() error {
+	if len(t.ArgRules) == 0 {
+		return nil
+	}
+	if len(t.AllowedArgGlobs) > 0 {
+		return fmt.Errorf("arg_rules cannot be combined with allowed_arg_globs")
+	}
+
+	positions := make(map[int]bool)
+	hasRest := false
+	for i, rule := range t.ArgRules {
+		switch {
+		case rule.Position != nil && rule.Rest:
+			return fmt.Errorf("arg rule at index %d: position and rest are mutually exclusive", i)
+		case rule.Position != nil:
+			if *rule.Position < 0 {
+				return fmt.Errorf("arg rule at index %d: position must not be negative", i)
+			}
+			if positions[*rule.Position] {
+				return fmt.Errorf("arg rule at index %d: duplicate position %d", i, *rule.Position)
+			}
+			positions[*rule.Position] = true
+		case rule.Rest:
+			if hasRest {
+				return fmt.Errorf("arg rule at index %d: only one rest rule is allowed", i)
+			}
+			hasRest = true
+		default:
+			return fmt.Errorf("arg rule at index %d: either position or rest is required", i)
+		}
+		if len(rule.Allow) == 0 {
+			return fmt.Errorf("arg rule at index %d: allow must contain at least one pattern", i)
+		}
+	}
+
+	return nil
+}
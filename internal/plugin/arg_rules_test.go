@@ -0,0 +1,145 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func TestTool_ArgRuleFor(t *testing.T) {
+	tool := &Tool{
+		ArgRules: []ArgRule{
+			{Rest: true, Allow: []string{"*.txt"}},
+			{Position: intPtr(0), Allow: []string{"-n"}},
+		},
+	}
+
+	if rule := tool.ArgRuleFor(0); rule == nil || rule.Position == nil || *rule.Position != 0 {
+		t.Errorf("ArgRuleFor(0) = %+v, want positional rule", rule)
+	}
+	if rule := tool.ArgRuleFor(3); rule == nil || !rule.Rest {
+		t.Errorf("ArgRuleFor(3) = %+v, want rest rule", rule)
+	}
+
+	noRest := &Tool{ArgRules: []ArgRule{{Position: intPtr(0), Allow: []string{"*"}}}}
+	if rule := noRest.ArgRuleFor(1); rule != nil {
+		t.Errorf("ArgRuleFor(1) = %+v, want nil", rule)
+	}
+}
+
+func TestTool_ValidateArgRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		tool    *Tool
+		wantErr bool
+	}{
+		{
+			name:    "no rules",
+			tool:    &Tool{AllowedArgGlobs: []string{"*"}},
+			wantErr: false,
+		},
+		{
+			name: "positional and rest",
+			tool: &Tool{ArgRules: []ArgRule{
+				{Position: intPtr(0), Allow: []string{"status", "log"}},
+				{Rest: true, Allow: []string{"**"}, Deny: []string{"--exec=*"}},
+			}},
+			wantErr: false,
+		},
+		{
+			name: "combined with allowed_arg_globs",
+			tool: &Tool{
+				AllowedArgGlobs: []string{"*"},
+				ArgRules:        []ArgRule{{Rest: true, Allow: []string{"*"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "neither position nor rest",
+			tool:    &Tool{ArgRules: []ArgRule{{Allow: []string{"*"}}}},
+			wantErr: true,
+		},
+		{
+			name:    "position and rest",
+			tool:    &Tool{ArgRules: []ArgRule{{Position: intPtr(0), Rest: true, Allow: []string{"*"}}}},
+			wantErr: true,
+		},
+		{
+			name:    "negative position",
+			tool:    &Tool{ArgRules: []ArgRule{{Position: intPtr(-1), Allow: []string{"*"}}}},
+			wantErr: true,
+		},
+		{
+			name: "duplicate position",
+			tool: &Tool{ArgRules: []ArgRule{
+				{Position: intPtr(1), Allow: []string{"*"}},
+				{Position: intPtr(1), Allow: []string{"*"}},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "multiple rest rules",
+			tool: &Tool{ArgRules: []ArgRule{
+				{Rest: true, Allow: []string{"*"}},
+				{Rest: true, Allow: []string{"*"}},
+			}},
+			wantErr: true,
+		},
+		{
+			name:    "empty allow",
+			tool:    &Tool{ArgRules: []ArgRule{{Rest: true, Deny: []string{"-*"}}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.tool.ValidateArgRules()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateArgRules() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadFromFile_ArgRules(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "plugin.json")
+	content := `{
+		"tools": [{
+			"name": "git",
+			"command": "git",
+			"arg_rules": [
+				{"position": 0, "allow": ["status", "log"]},
+				{"rest": true, "allow": ["*"], "deny": ["-c"]}
+			]
+		}]
+	}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := LoadFromFile(path)
+	if err != nil {
+		t.Fatalf("LoadFromFile() error = %v", err)
+	}
+	tool := config.GetTool("git")
+	if tool == nil || len(tool.ArgRules) != 2 {
+		t.Fatalf("expected tool with 2 arg rules, got %+v", tool)
+	}
+	if tool.ArgRules[0].Position == nil || *tool.ArgRules[0].Position != 0 {
+		t.Errorf("expected first rule at position 0, got %+v", tool.ArgRules[0])
+	}
+
+	invalid := `{"tools": [{"name": "bad", "command": "echo", "allowed_arg_globs": ["*"], "arg_rules": [{"rest": true, "allow": ["*"]}]}]}`
+	if err := os.WriteFile(path, []byte(invalid), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFromFile(path); err == nil {
+		t.Error("LoadFromFile() expected error when combining arg_rules with allowed_arg_globs")
+	}
+}
@@ -0,0 +1,23 @@
+package plugin
+
+import "fmt"
+
+// How a tool's requests and responses are stored in the audit log
+const (
+	AuditBodiesFull = "full" // Stored with secrets redacted (default)
+	AuditBodiesHash = "hash" // Only their size and SHA-256 hash are stored
+)
+
+// ValidateAuditBodies checks the tool's audit_bodies option
+func (t *Tool) ValidateAuditBodies() error {
+	switch t.AuditBodies {
+	case "", AuditBodiesFull, AuditBodiesHash:
+		return nil
+	}
+	return fmt.Errorf("unknown audit_bodies %q (expected %q or %q)", t.AuditBodies, AuditBodiesFull, AuditBodiesHash)
+}
+
+// HashesAuditBodies reports whether only hashes of the tool's requests and responses are audited
+func (t *Tool) HashesAuditBodies() bool {
+	return t.AuditBodies == AuditBodiesHash
+}
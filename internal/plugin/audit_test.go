@@ -0,0 +1,28 @@
+package plugin
+
+import "testing"
+
+func TestToolValidateAuditBodies(t *testing.T) {
+	tests := []struct {
+		name     string
+		tool     Tool
+		wantErr  bool
+		wantHash bool
+	}{
+		{"default", Tool{}, false, false},
+		{"full", Tool{AuditBodies: AuditBodiesFull}, false, false},
+		{"hash", Tool{AuditBodies: AuditBodiesHash}, false, true},
+		{"unknown", Tool{AuditBodies: "none"}, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.tool.ValidateAuditBodies(); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateAuditBodies() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := tt.tool.HashesAuditBodies(); got != tt.wantHash {
+				t.Errorf("HashesAuditBodies() = %v, want %v", got, tt.wantHash)
+			}
+		})
+	}
+}
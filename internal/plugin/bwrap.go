@@ -0,0 +1,89 @@
+package plugin
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// BwrapConfig customizes the bubblewrap sandbox of a tool
+type BwrapConfig struct {
+	ROBinds      []BindMount `json:"ro_binds,omitempty"`      // Host paths mounted read-only
+	RWBinds      []BindMount `json:"rw_binds,omitempty"`      // Host paths mounted read-write
+	Tmpfs        []string    `json:"tmpfs,omitempty"`         // Sandbox paths replaced by an empty tmpfs, e.g. "/etc" to hide it
+	ShareNet     bool        `json:"share_net,omitempty"`     // Keep the host network (default: no network)
+	ReadonlyRoot bool        `json:"readonly_root,omitempty"` // Mount the root directory read-only
+	Hostname     string      `json:"hostname,omitempty"`      // Hostname inside the sandbox
+	Seccomp      string      `json:"seccomp,omitempty"`       // Seccomp profile: "default-deny-dangerous" or "strict" (default: none)
+}
+
+// Seccomp profiles for the bubblewrap sandbox
+const (
+	SeccompDefaultDenyDangerous = "default-deny-dangerous" // Deny kernel, mount, namespace, keyring and tracing syscalls
+	SeccompStrict               = "strict"                 // Also deny non-Unix sockets, chroot, device nodes and io_uring
+)
+
+// BindMount mounts a host path into the bubblewrap sandbox
+type BindMount struct {
+	Src  string `json:"src"`            // Host path; relative paths are relative to the root directory
+	Dest string `json:"dest,omitempty"` // Absolute sandbox path (default: where src appears in the sandbox)
+}
+
+// hostnamePattern matches a valid hostname
+var hostnamePattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9.-]{0,62}[A-Za-z0-9])?$`)
+
+// Validate checks that the bind mounts, tmpfs paths, hostname and seccomp profile are well-formed.
+// Whether bind sources are allowed is checked by the executor, which knows the root directory.
+func (c *BwrapConfig) Validate() error {
+	for _, b := range c.ROBinds {
+		if err := b.validate(); err != nil {
+			return fmt.Errorf("ro_binds: %w", err)
+		}
+	}
+	for _, b := range c.RWBinds {
+		if err := b.validate(); err != nil {
+			return fmt.Errorf("rw_binds: %w", err)
+		}
+	}
+	for _, p := range c.Tmpfs {
+		if err := validateSandboxPath(p); err != nil {
+			return fmt.Errorf("tmpfs: %w", err)
+		}
+	}
+	if c.Hostname != "" && !hostnamePattern.MatchString(c.Hostname) {
+		return fmt.Errorf("invalid hostname %q", c.Hostname)
+	}
+	switch c.Seccomp {
+	case "", SeccompDefaultDenyDangerous, SeccompStrict:
+	default:
+		return fmt.Errorf("unknown seccomp profile %q", c.Seccomp)
+	}
+	return nil
+}
+
+func (b BindMount) validate() error {
+	if b.Src == "" {
+		return fmt.Errorf("bind src is required")
+	}
+	for _, elem := range strings.Split(filepath.ToSlash(b.Src), "/") {
+		if elem == ".." {
+			return fmt.Errorf("bind src %q must not contain \"..\"", b.Src)
+		}
+	}
+	if b.Dest != "" {
+		return validateSandboxPath(b.Dest)
+	}
+	return nil
+}
+
+// validateSandboxPath checks that a mount point inside the sandbox is absolute, clean and not "/"
+func validateSandboxPath(p string) error {
+	if !filepath.IsAbs(p) || filepath.Clean(p) != p {
+		return fmt.Errorf("sandbox path %q must be absolute and clean", p)
+	}
+	if p == "/" {
+		return fmt.Errorf("sandbox path must not be /")
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBwrapConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     BwrapConfig
+		wantErr bool
+	}{
+		{"empty", BwrapConfig{}, false},
+		{"full profile", BwrapConfig{
+			ROBinds:      []BindMount{{Src: "/srv/data", Dest: "/data"}},
+			RWBinds:      []BindMount{{Src: "scratch"}},
+			Tmpfs:        []string{"/etc"},
+			ShareNet:     true,
+			ReadonlyRoot: true,
+			Hostname:     "tool-1.sandbox",
+			Seccomp:      SeccompStrict,
+		}, false},
+		{"default seccomp profile", BwrapConfig{Seccomp: SeccompDefaultDenyDangerous}, false},
+		{"unknown seccomp profile", BwrapConfig{Seccomp: "permissive"}, true},
+		{"missing src", BwrapConfig{ROBinds: []BindMount{{Dest: "/data"}}}, true},
+		{"src traversal", BwrapConfig{RWBinds: []BindMount{{Src: "../outside"}}}, true},
+		{"relative dest", BwrapConfig{ROBinds: []BindMount{{Src: "/srv/data", Dest: "data"}}}, true},
+		{"dest over root", BwrapConfig{ROBinds: []BindMount{{Src: "/srv/data", Dest: "/"}}}, true},
+		{"unclean tmpfs", BwrapConfig{Tmpfs: []string{"/etc/../var"}}, true},
+		{"tmpfs root", BwrapConfig{Tmpfs: []string{"/"}}, true},
+		{"invalid hostname", BwrapConfig{Hostname: "bad host"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadFromFile_Bwrap(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "plugin.json")
+	content := `{
+		"tools": [{
+			"name": "fetch",
+			"command": "curl",
+			"sandbox": "bubblewrap",
+			"bwrap": {"ro_binds": [{"src": "data", "dest": "/data"}], "share_net": true, "readonly_root": true}
+		}]
+	}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := LoadFromFile(path)
+	if err != nil {
+		t.Fatalf("LoadFromFile() error = %v", err)
+	}
+	tool := config.GetTool("fetch")
+	if tool == nil || tool.Bwrap == nil || !tool.Bwrap.ShareNet || !tool.Bwrap.ReadonlyRoot || len(tool.Bwrap.ROBinds) != 1 {
+		t.Fatalf("expected bwrap profile, got %+v", tool)
+	}
+
+	invalid := `{"tools": [{"name": "bad", "command": "ls", "sandbox": "none", "bwrap": {"share_net": true}}]}`
+	if err := os.WriteFile(path, []byte(invalid), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFromFile(path); err == nil {
+		t.Error("LoadFromFile() expected error for bwrap without the bubblewrap sandbox")
+	}
+}
@@ -0,0 +1,98 @@
+package plugin
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+)
+
+// DefaultFileMaxBytes is the size limit of a file argument when max_bytes is not set (1MB)
+const DefaultFileMaxBytes = 1024 * 1024
+
+// InputFile is the decoded content of a file argument
+type InputFile struct {
+	Name string // Base name the file is written as
+	Data []byte
+}
+
+// FileFunc stores a file argument and returns the path the command opens it by
+type FileFunc func(param string, file InputFile) (string, error)
+
+// fileNamePattern restricts the names file arguments are written as
+var fileNamePattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]*$`)
+
+// HasFileParameters returns true if any parameter has type "file"
+func (t *Tool) HasFileParameters() bool {
+	for _, p := range t.Parameters {
+		if p.Type == ParameterTypeFile {
+			return true
+		}
+	}
+	return false
+}
+
+// FileLimit returns the largest file a file parameter accepts in bytes
+func (p *Parameter) FileLimit() int {
+	if p.MaxBytes > 0 {
+		return p.MaxBytes
+	}
+	return DefaultFileMaxBytes
+}
+
+// DecodeFile decodes a file argument: a base64 string, an embedded resource
+// ({"type": "resource", "resource": {...}}) or resource contents ({"uri": ..., "text" or "blob": ...})
+func (p *Parameter) DecodeFile(raw interface{}) (InputFile, error) {
+	file := InputFile{Name: p.Name}
+
+	switch v := raw.(type) {
+	case string:
+		data, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return InputFile{}, fmt.Errorf("invalid base64 content: %w", err)
+		}
+		file.Data = data
+	case map[string]interface{}:
+		contents := v
+		if resource, ok := v["resource"].(map[string]interface{}); ok {
+			contents = resource
+		}
+		if uri, ok := contents["uri"].(string); ok {
+			if name := resourceFileName(uri); name != "" {
+				file.Name = name
+			}
+		}
+		if text, ok := contents["text"].(string); ok {
+			file.Data = []byte(text)
+		} else if blob, ok := contents["blob"].(string); ok {
+			data, err := base64.StdEncoding.DecodeString(blob)
+			if err != nil {
+				return InputFile{}, fmt.Errorf("invalid base64 blob: %w", err)
+			}
+			file.Data = data
+		} else {
+			return InputFile{}, fmt.Errorf("resource has neither text nor blob content")
+		}
+	default:
+		return InputFile{}, fmt.Errorf("expected base64 string or embedded resource, got %T", raw)
+	}
+
+	if len(file.Data) > p.FileLimit() {
+		return InputFile{}, fmt.Errorf("file is %d bytes, exceeding the limit of %d", len(file.Data), p.FileLimit())
+	}
+	return file, nil
+}
+
+// resourceFileName returns the base name of a resource URI, or "" if it is not a safe file name
+func resourceFileName(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return ""
+	}
+	name := path.Base(u.Path)
+	if name == "." || name == ".." || !fileNamePattern.MatchString(name) {
+		return ""
+	}
+	return name
+}
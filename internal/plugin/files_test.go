@@ -0,0 +1,108 @@
+package plugin
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParameter_DecodeFile(t *testing.T) {
+	p := &Parameter{Name: "patch", Type: ParameterTypeFile, MaxBytes: 16}
+
+	tests := []struct {
+		name     string
+		raw      interface{}
+		wantName string
+		wantData string
+		wantErr  bool
+	}{
+		{name: "base64", raw: "aGVsbG8=", wantName: "patch", wantData: "hello"},
+		{name: "invalid base64", raw: "not base64!", wantErr: true},
+		{
+			name:     "embedded text resource",
+			raw:      map[string]interface{}{"type": "resource", "resource": map[string]interface{}{"uri": "file:///work/fix.diff", "text": "diff"}},
+			wantName: "fix.diff",
+			wantData: "diff",
+		},
+		{
+			name:     "blob resource contents",
+			raw:      map[string]interface{}{"uri": "file:///data/table.csv", "mimeType": "text/csv", "blob": "YSxi"},
+			wantName: "table.csv",
+			wantData: "a,b",
+		},
+		{
+			name:     "unsafe uri name",
+			raw:      map[string]interface{}{"uri": "file:///work/..", "text": "x"},
+			wantName: "patch",
+			wantData: "x",
+		},
+		{name: "resource without content", raw: map[string]interface{}{"uri": "file:///a"}, wantErr: true},
+		{name: "too large", raw: map[string]interface{}{"text": strings.Repeat("x", 17)}, wantErr: true},
+		{name: "wrong type", raw: float64(1), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := p.DecodeFile(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecodeFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (file.Name != tt.wantName || string(file.Data) != tt.wantData) {
+				t.Errorf("DecodeFile() = %q, %q; want %q, %q", file.Name, file.Data, tt.wantName, tt.wantData)
+			}
+		})
+	}
+}
+
+func TestTool_ValidateParameters_File(t *testing.T) {
+	tests := []struct {
+		name    string
+		param   Parameter
+		wantErr bool
+	}{
+		{"file", Parameter{Name: "f", Type: ParameterTypeFile, MaxBytes: 1024, Flag: "-i"}, false},
+		{"file with default", Parameter{Name: "f", Type: ParameterTypeFile, Default: "eA=="}, true},
+		{"file with pattern", Parameter{Name: "f", Type: ParameterTypeFile, Pattern: ".*"}, true},
+		{"negative max_bytes", Parameter{Name: "f", Type: ParameterTypeFile, MaxBytes: -1}, true},
+		{"max_bytes on string", Parameter{Name: "s", Type: ParameterTypeString, MaxBytes: 10}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool := &Tool{Name: "t", Parameters: []Parameter{tt.param}}
+			if err := tool.ValidateParameters(); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateParameters() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTool_BuildArgs_File(t *testing.T) {
+	tool := &Tool{
+		Name:       "patch",
+		Parameters: []Parameter{{Name: "diff", Type: ParameterTypeFile, Flag: "-i", Required: true}},
+	}
+	var stored []InputFile
+	addFile := func(param string, file InputFile) (string, error) {
+		stored = append(stored, file)
+		return "/scratch/" + param + "/" + file.Name, nil
+	}
+
+	args, err := tool.BuildArgs(map[string]interface{}{"diff": "eA=="}, addFile)
+	if err != nil {
+		t.Fatalf("BuildArgs() error = %v", err)
+	}
+	if !reflect.DeepEqual(args, []string{"-i", "/scratch/diff/diff"}) {
+		t.Errorf("BuildArgs() = %q", args)
+	}
+	if len(stored) != 1 || string(stored[0].Data) != "x" {
+		t.Errorf("expected the decoded file to be stored, got %+v", stored)
+	}
+
+	if _, err := tool.BuildArgs(map[string]interface{}{"diff": "eA=="}, nil); err == nil {
+		t.Error("BuildArgs() expected error without a FileFunc")
+	}
+	if !tool.HasFileParameters() || newParamTool().HasFileParameters() {
+		t.Error("HasFileParameters() mismatch")
+	}
+}
@@ -0,0 +1,92 @@
+package plugin
+
+import (
+	"fmt"
+	"time"
+)
+
+// wasmPageSize is the size of a WebAssembly memory page
+const wasmPageSize = 64 * 1024
+
+// maxWasmMemoryBytes is the largest linear memory a wasm module can address (4GB)
+const maxWasmMemoryBytes = 65536 * wasmPageSize
+
+// ResourceLimits constrains the resources a tool's command may use. Zero fields are unlimited.
+type ResourceLimits struct {
+	MaxMemoryBytes   int64 `json:"max_memory_bytes,omitempty"`    // Address space (RLIMIT_AS); linear memory for wasm tools
+	MaxCPUSeconds    int   `json:"max_cpu_seconds,omitempty"`     // CPU time (RLIMIT_CPU)
+	MaxProcesses     int   `json:"max_processes,omitempty"`       // Processes of the executing user (RLIMIT_NPROC)
+	MaxFileSizeBytes int64 `json:"max_file_size_bytes,omitempty"` // Largest file the command may write (RLIMIT_FSIZE)
+	MaxOpenFiles     int   `json:"max_open_files,omitempty"`      // Open file descriptors (RLIMIT_NOFILE)
+}
+
+// IsZero reports whether no resource limit is set
+func (r *ResourceLimits) IsZero() bool {
+	return r == nil || *r == ResourceLimits{}
+}
+
+// validate checks that the limits are not negative and apply to the sandbox type
+func (r *ResourceLimits) validate(sandbox SandboxType) error {
+	if r.MaxMemoryBytes < 0 || r.MaxCPUSeconds < 0 || r.MaxProcesses < 0 || r.MaxFileSizeBytes < 0 || r.MaxOpenFiles < 0 {
+		return fmt.Errorf("resource limits cannot be negative")
+	}
+	if sandbox != SandboxTypeWasm {
+		return nil
+	}
+	if r.MaxCPUSeconds != 0 || r.MaxProcesses != 0 || r.MaxFileSizeBytes != 0 || r.MaxOpenFiles != 0 {
+		return fmt.Errorf("only max_memory_bytes applies to wasm tools")
+	}
+	if r.MaxMemoryBytes != 0 && (r.MaxMemoryBytes < wasmPageSize || r.MaxMemoryBytes > maxWasmMemoryBytes) {
+		return fmt.Errorf("max_memory_bytes for wasm tools must be between %d and %d", wasmPageSize, maxWasmMemoryBytes)
+	}
+	return nil
+}
+
+// WasmMemoryPages returns the memory limit in wasm pages, or 0 if memory is unlimited
+func (r *ResourceLimits) WasmMemoryPages() uint32 {
+	if r == nil || r.MaxMemoryBytes <= 0 {
+		return 0
+	}
+	return uint32(min(r.MaxMemoryBytes, maxWasmMemoryBytes) / wasmPageSize)
+}
+
+// ValidateLimits checks that the tool's timeout, output, concurrency and resource limits are well-formed.
+// Server-wide ceilings are checked by the executor.
+func (t *Tool) ValidateLimits() error {
+	if t.Timeout != "" {
+		d, err := time.ParseDuration(t.Timeout)
+		if err != nil {
+			return fmt.Errorf("invalid timeout %q: %w", t.Timeout, err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("timeout must be positive, got %q", t.Timeout)
+		}
+	}
+	if t.MaxOutputBytes < 0 {
+		return fmt.Errorf("max_output_bytes cannot be negative")
+	}
+	if t.MaxStderrBytes < 0 {
+		return fmt.Errorf("max_stderr_bytes cannot be negative")
+	}
+	if t.MaxConcurrency < 0 {
+		return fmt.Errorf("max_concurrency cannot be negative")
+	}
+	if t.Limits != nil {
+		if err := t.Limits.validate(t.Sandbox); err != nil {
+			return fmt.Errorf("invalid limits: %w", err)
+		}
+	}
+	return nil
+}
+
+// TimeoutDuration returns the tool's timeout, or 0 if it uses the server default
+func (t *Tool) TimeoutDuration() time.Duration {
+	if t.Timeout == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(t.Timeout)
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
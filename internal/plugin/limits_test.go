@@ -0,0 +1,67 @@
+package plugin
+
+import (
+	"testing"
+	"time"
+)
+
+func TestToolValidateLimits(t *testing.T) {
+	tests := []struct {
+		name        string
+		tool        Tool
+		wantErr     bool
+		wantTimeout time.Duration
+	}{
+		{"no limits", Tool{}, false, 0},
+		{"seconds", Tool{Timeout: "5s"}, false, 5 * time.Second},
+		{"minutes with output limits", Tool{Timeout: "10m", MaxOutputBytes: 4096, MaxStderrBytes: 1024}, false, 10 * time.Minute},
+		{"invalid duration", Tool{Timeout: "5"}, true, 0},
+		{"zero duration", Tool{Timeout: "0s"}, true, 0},
+		{"negative duration", Tool{Timeout: "-1s"}, true, 0},
+		{"negative max_output_bytes", Tool{MaxOutputBytes: -1}, true, 0},
+		{"negative max_stderr_bytes", Tool{MaxStderrBytes: -1}, true, 0},
+		{"max_concurrency", Tool{MaxConcurrency: 2}, false, 0},
+		{"negative max_concurrency", Tool{MaxConcurrency: -1}, true, 0},
+		{"resource limits", Tool{Limits: &ResourceLimits{MaxMemoryBytes: 1 << 30, MaxCPUSeconds: 10, MaxProcesses: 64, MaxFileSizeBytes: 1 << 20, MaxOpenFiles: 256}}, false, 0},
+		{"negative resource limit", Tool{Limits: &ResourceLimits{MaxCPUSeconds: -1}}, true, 0},
+		{"wasm memory limit", Tool{Sandbox: SandboxTypeWasm, Limits: &ResourceLimits{MaxMemoryBytes: 64 << 20}}, false, 0},
+		{"wasm memory below one page", Tool{Sandbox: SandboxTypeWasm, Limits: &ResourceLimits{MaxMemoryBytes: 1024}}, true, 0},
+		{"wasm cpu limit", Tool{Sandbox: SandboxTypeWasm, Limits: &ResourceLimits{MaxCPUSeconds: 10}}, true, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.tool.ValidateLimits()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateLimits() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr {
+				if got := tt.tool.TimeoutDuration(); got != tt.wantTimeout {
+					t.Errorf("TimeoutDuration() = %v, want %v", got, tt.wantTimeout)
+				}
+			}
+		})
+	}
+}
+
+func TestResourceLimitsWasmMemoryPages(t *testing.T) {
+	tests := []struct {
+		name   string
+		limits *ResourceLimits
+		want   uint32
+	}{
+		{"nil", nil, 0},
+		{"no memory limit", &ResourceLimits{MaxCPUSeconds: 1}, 0},
+		{"whole pages", &ResourceLimits{MaxMemoryBytes: 2 * 64 * 1024}, 2},
+		{"rounds down", &ResourceLimits{MaxMemoryBytes: 3*64*1024 - 1}, 2},
+		{"capped at 4GB", &ResourceLimits{MaxMemoryBytes: 1 << 40}, 65536},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.limits.WasmMemoryPages(); got != tt.want {
+				t.Errorf("WasmMemoryPages() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
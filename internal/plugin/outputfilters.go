@@ -0,0 +1,250 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Output filter types, applied in order to a tool's output after execution
+const (
+	OutputFilterRedact    = "redact"     // Replace regex matches in stdout and stderr
+	OutputFilterStripANSI = "strip_ansi" // Remove ANSI escape sequences from stdout and stderr
+	OutputFilterLines     = "lines"      // Keep the first head and last tail lines of stdout
+	OutputFilterJSON      = "json"       // Keep only the listed fields of JSON stdout
+)
+
+// DefaultRedaction replaces matches of a redact filter without a replacement
+const DefaultRedaction = "[REDACTED]"
+
+// ansiPattern matches CSI (colors, cursor movement), OSC (titles, links) and other escape sequences
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(\x07|\x1b\\)|\x1b[@-Z\\-_]`)
+
+// jsonFieldPattern matches the field paths of a json filter, e.g. ".name" or ".items[].id"
+var jsonFieldPattern = regexp.MustCompile(`^(\.([A-Za-z0-9_-]+(\[\])?|\[\]))+$`)
+
+// OutputFilter post-processes a tool's output
+type OutputFilter struct {
+	Type        string   `json:"type"`
+	Pattern     string   `json:"pattern,omitempty"`     // redact: regular expression
+	Replacement string   `json:"replacement,omitempty"` // redact: replacement, may refer to groups as $1 (default: DefaultRedaction)
+	Head        int      `json:"head,omitempty"`        // lines: leading lines kept
+	Tail        int      `json:"tail,omitempty"`        // lines: trailing lines kept
+	Fields      []string `json:"fields,omitempty"`      // json: field paths kept
+
+	re *regexp.Regexp // redact: Pattern compiled by validate
+}
+
+// OutputFilterReport records what an output filter removed
+type OutputFilterReport struct {
+	Type         string `json:"type"`
+	Redactions   int    `json:"redactions,omitempty"`    // redact: matches replaced
+	BytesRemoved int    `json:"bytes_removed,omitempty"` // strip_ansi, json
+	LinesRemoved int    `json:"lines_removed,omitempty"` // lines
+	Error        string `json:"error,omitempty"`         // Why the filter left the output unchanged
+}
+
+// validate checks the filter's type and the options it needs, and compiles a redact pattern
+func (f *OutputFilter) validate() error {
+	switch f.Type {
+	case OutputFilterRedact:
+		if f.Pattern == "" {
+			return fmt.Errorf("redact requires a pattern")
+		}
+		re, err := regexp.Compile(f.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", f.Pattern, err)
+		}
+		f.re = re
+	case OutputFilterStripANSI:
+	case OutputFilterLines:
+		if f.Head < 0 || f.Tail < 0 {
+			return fmt.Errorf("head and tail cannot be negative")
+		}
+		if f.Head == 0 && f.Tail == 0 {
+			return fmt.Errorf("lines requires head or tail")
+		}
+	case OutputFilterJSON:
+		if len(f.Fields) == 0 {
+			return fmt.Errorf("json requires fields")
+		}
+		for _, field := range f.Fields {
+			if !jsonFieldPattern.MatchString(field) {
+				return fmt.Errorf("invalid field path %q (expected e.g. \".name\" or \".items[].id\")", field)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown type %q (expected %q, %q, %q or %q)", f.Type, OutputFilterRedact, OutputFilterStripANSI, OutputFilterLines, OutputFilterJSON)
+	}
+	return nil
+}
+
+// ValidateOutputFilters checks the tool's output filters
+func (t *Tool) ValidateOutputFilters() error {
+	for i := range t.OutputFilters {
+		if err := t.OutputFilters[i].validate(); err != nil {
+			return fmt.Errorf("output_filters[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// FilterOutput applies the tool's output filters in order and reports what each removed.
+// The filters must have been checked by ValidateOutputFilters.
+func (t *Tool) FilterOutput(stdout, stderr string) (string, string, []OutputFilterReport) {
+	var reports []OutputFilterReport
+	for i := range t.OutputFilters {
+		f := &t.OutputFilters[i]
+		report := OutputFilterReport{Type: f.Type}
+		switch f.Type {
+		case OutputFilterRedact:
+			replacement := f.Replacement
+			if replacement == "" {
+				replacement = DefaultRedaction
+			}
+			for _, s := range []*string{&stdout, &stderr} {
+				report.Redactions += len(f.re.FindAllStringIndex(*s, -1))
+				*s = f.re.ReplaceAllString(*s, replacement)
+			}
+		case OutputFilterStripANSI:
+			for _, s := range []*string{&stdout, &stderr} {
+				stripped := ansiPattern.ReplaceAllString(*s, "")
+				report.BytesRemoved += len(*s) - len(stripped)
+				*s = stripped
+			}
+		case OutputFilterLines:
+			stdout, report.LinesRemoved = keepLines(stdout, f.Head, f.Tail)
+		case OutputFilterJSON:
+			projected, err := projectJSON(stdout, f.Fields)
+			if err != nil {
+				report.Error = err.Error()
+				break
+			}
+			report.BytesRemoved = max(len(stdout)-len(projected), 0)
+			stdout = projected
+		}
+		reports = append(reports, report)
+	}
+	return stdout, stderr, reports
+}
+
+// keepLines keeps the first head and last tail lines of s, replacing the rest with a
+// marker line, and returns the number of lines removed
+func keepLines(s string, head, tail int) (string, int) {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	omitted := len(lines) - head - tail
+	if omitted <= 0 {
+		return s, 0
+	}
+	var sb strings.Builder
+	for _, line := range lines[:head] {
+		sb.WriteString(line)
+	}
+	fmt.Fprintf(&sb, "[... %d lines omitted ...]\n", omitted)
+	for _, line := range lines[len(lines)-tail:] {
+		sb.WriteString(line)
+	}
+	return sb.String(), omitted
+}
+
+// projectJSON keeps only the given field paths of a JSON document, like jq's
+// {a, b} construction; a document without any of them becomes null
+func projectJSON(s string, fields []string) (string, error) {
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return "", fmt.Errorf("output is not JSON: %w", err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return "", fmt.Errorf("output is not a single JSON value")
+	}
+
+	var projected interface{}
+	for _, field := range fields {
+		if value, ok := projectField(doc, strings.Split(field[1:], ".")); ok {
+			projected = mergeJSON(projected, value)
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(projected); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// projectField returns the parts of value on the path segments, each a key, a key
+// followed by [] for every element of an array, or [] alone
+func projectField(value interface{}, segs []string) (interface{}, bool) {
+	if len(segs) == 0 {
+		return value, true
+	}
+	name, iterate := strings.CutSuffix(segs[0], "[]")
+	if name != "" {
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = obj[name]; !ok {
+			return nil, false
+		}
+	}
+
+	var child interface{}
+	if iterate {
+		elems, ok := value.([]interface{})
+		if !ok {
+			return nil, false
+		}
+		// Elements without the field become null so that paths merge element by element
+		projected := make([]interface{}, len(elems))
+		for i, elem := range elems {
+			projected[i], _ = projectField(elem, segs[1:])
+		}
+		child = projected
+	} else {
+		var ok bool
+		if child, ok = projectField(value, segs[1:]); !ok {
+			return nil, false
+		}
+	}
+	if name == "" {
+		return child, true
+	}
+	return map[string]interface{}{name: child}, true
+}
+
+// mergeJSON combines two projections of the same document
+func mergeJSON(a, b interface{}) interface{} {
+	switch av := a.(type) {
+	case nil:
+		return b
+	case map[string]interface{}:
+		if bv, ok := b.(map[string]interface{}); ok {
+			for k, v := range bv {
+				av[k] = mergeJSON(av[k], v)
+			}
+			return av
+		}
+	case []interface{}:
+		if bv, ok := b.([]interface{}); ok && len(av) == len(bv) {
+			for i := range av {
+				av[i] = mergeJSON(av[i], bv[i])
+			}
+			return av
+		}
+	}
+	if b == nil {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,371 @@
+package plugin
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParameterType represents the type of a named tool parameter
+type ParameterType string
+
+const (
+	ParameterTypeString  ParameterType = "string"
+	ParameterTypeInteger ParameterType = "integer"
+	ParameterTypeBoolean ParameterType = "boolean"
+	ParameterTypeEnum    ParameterType = "enum"
+	ParameterTypeFile    ParameterType = "file" // Content written to a scratch file whose path is passed to the command
+)
+
+// Parameter represents a named, typed argument accepted by a tool.
+// Parameters are mapped to argv through the tool's argv template.
+type Parameter struct {
+	Name        string        `json:"name"`
+	Type        ParameterType `json:"type"`
+	Description string        `json:"description,omitempty"`
+	Required    bool          `json:"required,omitempty"`
+	Pattern     string        `json:"pattern,omitempty"`   // Regular expression the whole value must match (string/integer)
+	Enum        []string      `json:"enum,omitempty"`      // Allowed values (enum only)
+	Default     interface{}   `json:"default,omitempty"`   // Used when the caller omits the parameter
+	Flag        string        `json:"flag,omitempty"`      // Emitted before the value (e.g. "-n"); a trailing "=" joins flag and value
+	MaxBytes    int           `json:"max_bytes,omitempty"` // Largest accepted file (file only, default: DefaultFileMaxBytes)
+}
+
+// parameterNamePattern restricts parameter names so they can be used as placeholders
+var parameterNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// placeholderPattern matches {name} placeholders in argv template elements
+var placeholderPattern = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_-]*)\}`)
+
+// reservedArgumentNames are tool call arguments handled by the server itself
+var reservedArgumentNames = map[string]bool{
+	"cwd":   true,
+	"args":  true,
+	"stdin": true,
+}
+
+// HasParameters returns true if the tool declares typed parameters
+func (t *Tool) HasParameters() bool {
+	return len(t.Parameters) > 0
+}
+
+// GetParameter returns a declared parameter by name
+func (t *Tool) GetParameter(name string) *Parameter {
+	for i := range t.Parameters {
+		if t.Parameters[i].Name == name {
+			return &t.Parameters[i]
+		}
+	}
+	return nil
+}
+
+// argvTemplate returns the argv template, defaulting to each parameter in declaration order
+func (t *Tool) argvTemplate() []string {
+	if len(t.Argv) > 0 {
+		return t.Argv
+	}
+	template := make([]string, 0, len(t.Parameters))
+	for _, p := range t.Parameters {
+		template = append(template, "{"+p.Name+"}")
+	}
+	return template
+}
+
+// ValidateParameters validates the parameter declarations and argv template
+func (t *Tool) ValidateParameters() error {
+	if len(t.Parameters) == 0 {
+		if len(t.Argv) > 0 {
+			return fmt.Errorf("argv requires parameters to be declared")
+		}
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	for i := range t.Parameters {
+		p := &t.Parameters[i]
+		if !parameterNamePattern.MatchString(p.Name) {
+			return fmt.Errorf("parameter at index %d has invalid name %q", i, p.Name)
+		}
+		if reservedArgumentNames[p.Name] {
+			return fmt.Errorf("parameter name %q is reserved", p.Name)
+		}
+		if seen[p.Name] {
+			return fmt.Errorf("duplicate parameter name %q", p.Name)
+		}
+		seen[p.Name] = true
+
+		switch p.Type {
+		case ParameterTypeString, ParameterTypeInteger, ParameterTypeBoolean:
+			if len(p.Enum) > 0 {
+				return fmt.Errorf("parameter %q: enum values require type \"enum\"", p.Name)
+			}
+		case ParameterTypeEnum:
+			if len(p.Enum) == 0 {
+				return fmt.Errorf("parameter %q: enum type requires at least one enum value", p.Name)
+			}
+		case ParameterTypeFile:
+			if len(p.Enum) > 0 || p.Default != nil {
+				return fmt.Errorf("parameter %q: file parameters cannot have enum values or a default", p.Name)
+			}
+			if p.MaxBytes < 0 {
+				return fmt.Errorf("parameter %q: max_bytes cannot be negative", p.Name)
+			}
+		default:
+			return fmt.Errorf("parameter %q has invalid type %q", p.Name, p.Type)
+		}
+
+		if p.MaxBytes != 0 && p.Type != ParameterTypeFile {
+			return fmt.Errorf("parameter %q: max_bytes is only supported for file type", p.Name)
+		}
+
+		if p.Pattern != "" {
+			if p.Type != ParameterTypeString && p.Type != ParameterTypeInteger {
+				return fmt.Errorf("parameter %q: pattern is only supported for string and integer types", p.Name)
+			}
+			if _, err := p.compilePattern(); err != nil {
+				return fmt.Errorf("parameter %q: invalid pattern: %w", p.Name, err)
+			}
+		}
+
+		if p.Default != nil {
+			if _, err := p.formatValue(p.Default); err != nil {
+				return fmt.Errorf("parameter %q: invalid default: %w", p.Name, err)
+			}
+		}
+	}
+
+	used := make(map[string]bool)
+	for _, elem := range t.argvTemplate() {
+		for _, name := range t.placeholderNames(elem) {
+			used[name] = true
+			p := t.GetParameter(name)
+			if p.Type != ParameterTypeBoolean {
+				continue
+			}
+			if elem != "{"+name+"}" {
+				return fmt.Errorf("argv element %q: boolean parameter %q must be used as a whole element", elem, name)
+			}
+			if p.Flag == "" {
+				return fmt.Errorf("boolean parameter %q requires a flag", name)
+			}
+		}
+	}
+	// A parameter argv never refers to would be accepted and silently dropped
+	for _, p := range t.Parameters {
+		if !used[p.Name] {
+			return fmt.Errorf("parameter %q is not used in argv", p.Name)
+		}
+	}
+
+	return nil
+}
+
+// BuildArgs validates the named arguments of a tool call and maps them to argv
+// using the tool's argv template. File arguments are passed to addFile, and the
+// path it returns is used as their value. The returned arguments do not include args_prefix.
+func (t *Tool) BuildArgs(arguments map[string]interface{}, addFile FileFunc) ([]string, error) {
+	for name := range arguments {
+		if name == "cwd" || (name == "stdin" && t.Stdin != nil) {
+			continue
+		}
+		if t.GetParameter(name) == nil {
+			return nil, fmt.Errorf("unknown argument %q", name)
+		}
+	}
+
+	// Resolve and validate every parameter value
+	values := make(map[string]string)
+	bools := make(map[string]bool)
+	for i := range t.Parameters {
+		p := &t.Parameters[i]
+		raw, ok := arguments[p.Name]
+		if !ok || raw == nil {
+			raw = p.Default
+		}
+		if raw == nil {
+			if p.Required {
+				return nil, fmt.Errorf("missing required argument %q", p.Name)
+			}
+			continue
+		}
+		if p.Type == ParameterTypeFile {
+			path, err := storeFile(p, raw, addFile)
+			if err != nil {
+				return nil, fmt.Errorf("argument %q: %w", p.Name, err)
+			}
+			values[p.Name] = path
+			continue
+		}
+		value, err := p.formatValue(raw)
+		if err != nil {
+			return nil, fmt.Errorf("argument %q: %w", p.Name, err)
+		}
+		values[p.Name] = value
+		if p.Type == ParameterTypeBoolean {
+			bools[p.Name] = value == "true"
+		}
+	}
+
+	var args []string
+	for _, elem := range t.argvTemplate() {
+		names := t.placeholderNames(elem)
+		if len(names) == 0 {
+			args = append(args, elem)
+			continue
+		}
+
+		// Whole-element placeholder: expands to the flag and/or value
+		if len(names) == 1 && elem == "{"+names[0]+"}" {
+			p := t.GetParameter(names[0])
+			value, ok := values[p.Name]
+			if !ok {
+				continue
+			}
+			switch {
+			case p.Type == ParameterTypeBoolean:
+				if bools[p.Name] {
+					args = append(args, p.Flag)
+				}
+			case strings.HasSuffix(p.Flag, "="):
+				args = append(args, p.Flag+value)
+			case p.Flag != "":
+				args = append(args, p.Flag, value)
+			default:
+				if strings.HasPrefix(value, "-") {
+					return nil, fmt.Errorf("argument %q: value must not start with '-'", p.Name)
+				}
+				args = append(args, value)
+			}
+			continue
+		}
+
+		// Embedded placeholders: dropped when any referenced parameter is unset
+		missing := false
+		expanded := placeholderPattern.ReplaceAllStringFunc(elem, func(m string) string {
+			name := m[1 : len(m)-1]
+			if t.GetParameter(name) == nil {
+				return m
+			}
+			value, ok := values[name]
+			if !ok {
+				missing = true
+			}
+			return value
+		})
+		if missing {
+			continue
+		}
+		if strings.HasPrefix(expanded, "-") && !strings.HasPrefix(elem, "-") {
+			return nil, fmt.Errorf("argv element %q: expanded value must not start with '-'", elem)
+		}
+		args = append(args, expanded)
+	}
+
+	return args, nil
+}
+
+// storeFile decodes a file argument and stores it with addFile
+func storeFile(p *Parameter, raw interface{}, addFile FileFunc) (string, error) {
+	file, err := p.DecodeFile(raw)
+	if err != nil {
+		return "", err
+	}
+	if addFile == nil {
+		return "", fmt.Errorf("file arguments are not supported here")
+	}
+	return addFile(p.Name, file)
+}
+
+// placeholderNames returns the declared parameter names referenced by an argv template element.
+// Braces that do not name a declared parameter (e.g. git's "@{upstream}") are kept literally.
+func (t *Tool) placeholderNames(elem string) []string {
+	var names []string
+	for _, m := range placeholderPattern.FindAllStringSubmatch(elem, -1) {
+		if t.GetParameter(m[1]) != nil {
+			names = append(names, m[1])
+		}
+	}
+	return names
+}
+
+// compilePattern compiles the parameter pattern anchored to the whole value
+func (p *Parameter) compilePattern() (*regexp.Regexp, error) {
+	return regexp.Compile(AnchorPattern(p.Pattern))
+}
+
+// AnchorPattern anchors a regular expression so that it must match a whole value
+func AnchorPattern(pattern string) string {
+	return "^(?:" + pattern + ")$"
+}
+
+// maxExactInteger is the largest magnitude up to which a JSON number (float64) holds every integer exactly
+const maxExactInteger = 1 << 53
+
+// formatValue validates a raw JSON value against the parameter and returns its argv form
+func (p *Parameter) formatValue(raw interface{}) (string, error) {
+	var value string
+
+	switch p.Type {
+	case ParameterTypeString, ParameterTypeEnum:
+		s, ok := raw.(string)
+		if !ok {
+			return "", fmt.Errorf("expected string, got %T", raw)
+		}
+		value = s
+	case ParameterTypeInteger:
+		switch n := raw.(type) {
+		case float64:
+			if n != math.Trunc(n) || math.IsInf(n, 0) || math.IsNaN(n) {
+				return "", fmt.Errorf("expected integer, got %v", n)
+			}
+			if math.Abs(n) > maxExactInteger {
+				return "", fmt.Errorf("integer out of range: %v", n)
+			}
+			value = strconv.FormatInt(int64(n), 10)
+		case int:
+			value = strconv.Itoa(n)
+		case int64:
+			value = strconv.FormatInt(n, 10)
+		default:
+			return "", fmt.Errorf("expected integer, got %T", raw)
+		}
+	case ParameterTypeBoolean:
+		b, ok := raw.(bool)
+		if !ok {
+			return "", fmt.Errorf("expected boolean, got %T", raw)
+		}
+		value = strconv.FormatBool(b)
+	default:
+		return "", fmt.Errorf("unsupported type %q", p.Type)
+	}
+
+	if p.Type == ParameterTypeEnum {
+		allowed := false
+		for _, e := range p.Enum {
+			if value == e {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			sorted := append([]string(nil), p.Enum...)
+			sort.Strings(sorted)
+			return "", fmt.Errorf("value %q is not one of %v", value, sorted)
+		}
+	}
+
+	if p.Pattern != "" {
+		re, err := p.compilePattern()
+		if err != nil {
+			return "", err
+		}
+		if !re.MatchString(value) {
+			return "", fmt.Errorf("value %q does not match pattern %q", value, p.Pattern)
+		}
+	}
+
+	return value, nil
+}
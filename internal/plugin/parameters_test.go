@@ -0,0 +1,284 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func newParamTool() *Tool {
+	return &Tool{
+		Name:    "git-log",
+		Command: "git",
+		Parameters: []Parameter{
+			{Name: "max_count", Type: ParameterTypeInteger, Flag: "-n", Default: float64(20)},
+			{Name: "oneline", Type: ParameterTypeBoolean, Flag: "--oneline"},
+			{Name: "format", Type: ParameterTypeEnum, Enum: []string{"short", "full"}, Flag: "--format="},
+			{Name: "path", Type: ParameterTypeString, Pattern: `[A-Za-z0-9_./-]+`},
+		},
+		Argv: []string{"{oneline}", "{max_count}", "{format}", "--", "{path}"},
+	}
+}
+
+func TestTool_BuildArgs(t *testing.T) {
+	tests := []struct {
+		name      string
+		arguments map[string]interface{}
+		want      []string
+		wantErr   bool
+	}{
+		{
+			name:      "defaults only",
+			arguments: map[string]interface{}{},
+			want:      []string{"-n", "20", "--"},
+		},
+		{
+			name: "all parameters",
+			arguments: map[string]interface{}{
+				"max_count": float64(5),
+				"oneline":   true,
+				"format":    "short",
+				"path":      "src/main.go",
+			},
+			want: []string{"--oneline", "-n", "5", "--format=short", "--", "src/main.go"},
+		},
+		{
+			name:      "false boolean omits flag",
+			arguments: map[string]interface{}{"oneline": false},
+			want:      []string{"-n", "20", "--"},
+		},
+		{
+			name:      "cwd is ignored",
+			arguments: map[string]interface{}{"cwd": "/tmp"},
+			want:      []string{"-n", "20", "--"},
+		},
+		{
+			name:      "unknown argument",
+			arguments: map[string]interface{}{"args": []interface{}{"--all"}},
+			wantErr:   true,
+		},
+		{
+			name:      "stdin without stdin config",
+			arguments: map[string]interface{}{"stdin": "data"},
+			wantErr:   true,
+		},
+		{
+			name:      "non-integer number",
+			arguments: map[string]interface{}{"max_count": 1.5},
+			wantErr:   true,
+		},
+		{
+			name:      "largest exact integer",
+			arguments: map[string]interface{}{"max_count": float64(1 << 53)},
+			want:      []string{"-n", "9007199254740992", "--"},
+		},
+		{
+			name:      "integer out of range",
+			arguments: map[string]interface{}{"max_count": 1e19},
+			wantErr:   true,
+		},
+		{
+			name:      "negative integer out of range",
+			arguments: map[string]interface{}{"max_count": -1e19},
+			wantErr:   true,
+		},
+		{
+			name:      "wrong type",
+			arguments: map[string]interface{}{"oneline": "yes"},
+			wantErr:   true,
+		},
+		{
+			name:      "enum value not allowed",
+			arguments: map[string]interface{}{"format": "raw"},
+			wantErr:   true,
+		},
+		{
+			name:      "pattern mismatch",
+			arguments: map[string]interface{}{"path": "a b"},
+			wantErr:   true,
+		},
+		{
+			name:      "positional value starting with dash",
+			arguments: map[string]interface{}{"path": "--output=x"},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newParamTool().BuildArgs(tt.arguments, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BuildArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BuildArgs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTool_BuildArgs_EmbeddedPlaceholders(t *testing.T) {
+	tool := &Tool{
+		Name: "git-show",
+		Parameters: []Parameter{
+			{Name: "rev", Type: ParameterTypeString, Required: true},
+			{Name: "depth", Type: ParameterTypeInteger},
+		},
+		Argv: []string{"{rev}~{depth}", "@{upstream}"},
+	}
+
+	got, err := tool.BuildArgs(map[string]interface{}{"rev": "HEAD", "depth": float64(2)}, nil)
+	if err != nil {
+		t.Fatalf("BuildArgs() error = %v", err)
+	}
+	if want := []string{"HEAD~2", "@{upstream}"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("BuildArgs() = %q, want %q", got, want)
+	}
+
+	// Element is dropped when a referenced parameter is unset
+	got, err = tool.BuildArgs(map[string]interface{}{"rev": "HEAD"}, nil)
+	if err != nil {
+		t.Fatalf("BuildArgs() error = %v", err)
+	}
+	if want := []string{"@{upstream}"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("BuildArgs() = %q, want %q", got, want)
+	}
+
+	if _, err := tool.BuildArgs(map[string]interface{}{}, nil); err == nil {
+		t.Error("BuildArgs() expected error for missing required argument")
+	}
+}
+
+func TestTool_ValidateParameters(t *testing.T) {
+	tests := []struct {
+		name    string
+		tool    *Tool
+		wantErr bool
+	}{
+		{
+			name:    "valid parameters",
+			tool:    newParamTool(),
+			wantErr: false,
+		},
+		{
+			name:    "no parameters",
+			tool:    &Tool{Name: "ls"},
+			wantErr: false,
+		},
+		{
+			name:    "argv without parameters",
+			tool:    &Tool{Name: "ls", Argv: []string{"-la"}},
+			wantErr: true,
+		},
+		{
+			name:    "reserved name",
+			tool:    &Tool{Parameters: []Parameter{{Name: "cwd", Type: ParameterTypeString}}},
+			wantErr: true,
+		},
+		{
+			name: "duplicate name",
+			tool: &Tool{Parameters: []Parameter{
+				{Name: "a", Type: ParameterTypeString},
+				{Name: "a", Type: ParameterTypeString},
+			}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid type",
+			tool:    &Tool{Parameters: []Parameter{{Name: "a", Type: "array"}}},
+			wantErr: true,
+		},
+		{
+			name:    "enum without values",
+			tool:    &Tool{Parameters: []Parameter{{Name: "a", Type: ParameterTypeEnum}}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid pattern",
+			tool:    &Tool{Parameters: []Parameter{{Name: "a", Type: ParameterTypeString, Pattern: "("}}},
+			wantErr: true,
+		},
+		{
+			name:    "default with wrong type",
+			tool:    &Tool{Parameters: []Parameter{{Name: "a", Type: ParameterTypeInteger, Default: "ten"}}},
+			wantErr: true,
+		},
+		{
+			name:    "boolean without flag",
+			tool:    &Tool{Parameters: []Parameter{{Name: "a", Type: ParameterTypeBoolean}}},
+			wantErr: true,
+		},
+		{
+			name: "boolean embedded in element",
+			tool: &Tool{
+				Parameters: []Parameter{{Name: "a", Type: ParameterTypeBoolean, Flag: "-a"}},
+				Argv:       []string{"--a={a}"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "parameter not used in argv",
+			tool: &Tool{
+				Parameters: []Parameter{{Name: "a", Type: ParameterTypeString}, {Name: "b", Type: ParameterTypeString}},
+				Argv:       []string{"{a}"},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.tool.ValidateParameters()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateParameters() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadFromFile_Parameters(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "plugin.json")
+	content := `{
+		"tools": [{
+			"name": "git-log",
+			"command": "git",
+			"args_prefix": ["log"],
+			"parameters": [
+				{"name": "max_count", "type": "integer", "flag": "-n", "default": 10},
+				{"name": "author", "type": "string", "flag": "--author="}
+			],
+			"argv": ["{max_count}", "{author}"]
+		}]
+	}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := LoadFromFile(path)
+	if err != nil {
+		t.Fatalf("LoadFromFile() error = %v", err)
+	}
+	tool := config.GetTool("git-log")
+	if tool == nil || len(tool.Parameters) != 2 {
+		t.Fatalf("expected tool with 2 parameters, got %+v", tool)
+	}
+
+	args, err := tool.BuildArgs(map[string]interface{}{"author": "alice"}, nil)
+	if err != nil {
+		t.Fatalf("BuildArgs() error = %v", err)
+	}
+	if want := []string{"-n", "10", "--author=alice"}; !reflect.DeepEqual(args, want) {
+		t.Errorf("BuildArgs() = %q, want %q", args, want)
+	}
+
+	// Invalid parameter declarations are rejected at load time
+	invalid := `{"tools": [{"name": "bad", "command": "echo", "parameters": [{"name": "x", "type": "float"}]}]}`
+	if err := os.WriteFile(path, []byte(invalid), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFromFile(path); err == nil {
+		t.Error("LoadFromFile() expected error for invalid parameter type")
+	}
+}
@@ -0,0 +1,113 @@
+package plugin
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// PathArgs declares which user arguments of a tool are filesystem paths.
+// Declared paths are resolved relative to the working directory and must stay
+// within the root directory (and within allowed_subtrees, if set).
+type PathArgs struct {
+	Positions       []int    `json:"positions,omitempty"`        // Zero-based argument indexes
+	AfterSeparator  bool     `json:"after_separator,omitempty"`  // Every argument after "--"
+	NonFlags        bool     `json:"non_flags,omitempty"`        // Every argument not starting with "-"
+	Flags           []string `json:"flags,omitempty"`            // Options taking a path ("-o" uses the next or attached argument, "--output=" the joined value)
+	AllowedSubtrees []string `json:"allowed_subtrees,omitempty"` // Directories relative to the root dir that paths must stay within
+}
+
+// PathArgument is a user argument (or the value part of one) declared as a path
+type PathArgument struct {
+	Index int    // Index into the user arguments
+	Value string // Path value
+}
+
+// Validate validates the path argument declaration
+func (p *PathArgs) Validate() error {
+	if len(p.Positions) == 0 && !p.AfterSeparator && !p.NonFlags && len(p.Flags) == 0 {
+		return fmt.Errorf("path_args must declare positions, after_separator, non_flags or flags")
+	}
+	for _, pos := range p.Positions {
+		if pos < 0 {
+			return fmt.Errorf("path_args position must not be negative: %d", pos)
+		}
+	}
+	for _, flag := range p.Flags {
+		if !strings.HasPrefix(flag, "-") || flag == "-" || flag == "--" {
+			return fmt.Errorf("path_args flag %q must be an option starting with '-'", flag)
+		}
+	}
+	for _, subtree := range p.AllowedSubtrees {
+		if filepath.IsAbs(subtree) {
+			return fmt.Errorf("allowed_subtree %q must be relative to the root directory", subtree)
+		}
+		for _, elem := range strings.Split(filepath.ToSlash(subtree), "/") {
+			if elem == ".." {
+				return fmt.Errorf("allowed_subtree %q must not contain \"..\"", subtree)
+			}
+		}
+	}
+	return nil
+}
+
+// Extract returns the arguments declared as paths
+func (p *PathArgs) Extract(args []string) []PathArgument {
+	positions := make(map[int]bool, len(p.Positions))
+	for _, pos := range p.Positions {
+		positions[pos] = true
+	}
+
+	var paths []PathArgument
+	afterSeparator := false
+	flagValue := false
+	for i, arg := range args {
+		// Value of a preceding path flag (e.g. "-o out.txt")
+		if flagValue {
+			paths = append(paths, PathArgument{Index: i, Value: arg})
+			flagValue = false
+			continue
+		}
+		if arg == "--" && !afterSeparator {
+			afterSeparator = true
+			if positions[i] {
+				paths = append(paths, PathArgument{Index: i, Value: arg})
+			}
+			continue
+		}
+
+		switch {
+		case positions[i],
+			afterSeparator && p.AfterSeparator,
+			p.NonFlags && (afterSeparator || !strings.HasPrefix(arg, "-")):
+			paths = append(paths, PathArgument{Index: i, Value: arg})
+			continue
+		case afterSeparator:
+			continue
+		}
+
+		for _, flag := range p.Flags {
+			switch {
+			case strings.HasSuffix(flag, "=") && strings.HasPrefix(arg, flag):
+				paths = append(paths, PathArgument{Index: i, Value: arg[len(flag):]})
+			case arg == flag:
+				flagValue = true
+			case strings.HasPrefix(flag, "--") && strings.HasPrefix(arg, flag+"="):
+				paths = append(paths, PathArgument{Index: i, Value: arg[len(flag)+1:]})
+			case isShortFlag(flag) && strings.HasPrefix(arg, flag):
+				// Short options also take an attached value (e.g. "-oout.txt")
+				paths = append(paths, PathArgument{Index: i, Value: arg[len(flag):]})
+			default:
+				continue
+			}
+			break
+		}
+	}
+
+	return paths
+}
+
+// isShortFlag reports whether flag is a single-dash, single-letter option such as "-o"
+func isShortFlag(flag string) bool {
+	return len(flag) == 2 && flag[0] == '-' && flag[1] != '-'
+}
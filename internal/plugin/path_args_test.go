@@ -0,0 +1,119 @@
+package plugin
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPathArgs_Extract(t *testing.T) {
+	tests := []struct {
+		name     string
+		pathArgs PathArgs
+		args     []string
+		want     []PathArgument
+	}{
+		{
+			name:     "positions",
+			pathArgs: PathArgs{Positions: []int{1}},
+			args:     []string{"-L", "src/main.go"},
+			want:     []PathArgument{{Index: 1, Value: "src/main.go"}},
+		},
+		{
+			name:     "after separator",
+			pathArgs: PathArgs{AfterSeparator: true},
+			args:     []string{"blame", "-w", "--", "a.go", "-weird"},
+			want:     []PathArgument{{Index: 3, Value: "a.go"}, {Index: 4, Value: "-weird"}},
+		},
+		{
+			name:     "non flags",
+			pathArgs: PathArgs{NonFlags: true},
+			args:     []string{"-n", "../x", "--all", "/etc/passwd"},
+			want:     []PathArgument{{Index: 1, Value: "../x"}, {Index: 3, Value: "/etc/passwd"}},
+		},
+		{
+			name:     "flags",
+			pathArgs: PathArgs{Flags: []string{"-o", "--output", "--file="}},
+			args:     []string{"-o", "out.txt", "--output=/tmp/x", "--file=in.txt", "-v"},
+			want: []PathArgument{
+				{Index: 1, Value: "out.txt"},
+				{Index: 2, Value: "/tmp/x"},
+				{Index: 3, Value: "in.txt"},
+			},
+		},
+		{
+			name:     "short flag with attached value",
+			pathArgs: PathArgs{Flags: []string{"-o", "--output"}},
+			args:     []string{"-o/etc/passwd", "--outputx", "-v"},
+			want:     []PathArgument{{Index: 0, Value: "/etc/passwd"}},
+		},
+		{
+			name:     "flag value is not a separator",
+			pathArgs: PathArgs{Flags: []string{"-o"}, AfterSeparator: true},
+			args:     []string{"-o", "--", "x"},
+			want:     []PathArgument{{Index: 1, Value: "--"}},
+		},
+		{
+			name:     "no path arguments",
+			pathArgs: PathArgs{AfterSeparator: true},
+			args:     []string{"status"},
+			want:     nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.pathArgs.Extract(tt.args)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Extract() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPathArgs_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		pathArgs PathArgs
+		wantErr  bool
+	}{
+		{
+			name:     "valid",
+			pathArgs: PathArgs{NonFlags: true, Flags: []string{"-o"}, AllowedSubtrees: []string{"src", "docs/api"}},
+			wantErr:  false,
+		},
+		{
+			name:     "nothing declared",
+			pathArgs: PathArgs{AllowedSubtrees: []string{"src"}},
+			wantErr:  true,
+		},
+		{
+			name:     "negative position",
+			pathArgs: PathArgs{Positions: []int{-1}},
+			wantErr:  true,
+		},
+		{
+			name:     "flag without dash",
+			pathArgs: PathArgs{Flags: []string{"output"}},
+			wantErr:  true,
+		},
+		{
+			name:     "absolute subtree",
+			pathArgs: PathArgs{NonFlags: true, AllowedSubtrees: []string{"/etc"}},
+			wantErr:  true,
+		},
+		{
+			name:     "subtree escaping root",
+			pathArgs: PathArgs{NonFlags: true, AllowedSubtrees: []string{"src/../.."}},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.pathArgs.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
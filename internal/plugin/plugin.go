@@ -64,14 +64,35 @@ type UIConfig struct {
 
 // Tool represents a tool definition from a plugin file
 type Tool struct {
-	Name            string      `json:"name"`
-	Description     string      `json:"description"`
-	Command         string      `json:"command"`
-	ArgsPrefix      []string    `json:"args_prefix,omitempty"` // Fixed arguments prepended to user args
-	AllowedArgGlobs []string    `json:"allowed_arg_globs"`
-	Sandbox         SandboxType `json:"sandbox"`
-	WasmBinary      string      `json:"wasm_binary"`
-	FixedCwd        bool        `json:"fixed_cwd,omitempty"` // If true, cwd is fixed to root directory and not exposed to clients
+	Name            string       `json:"name"`
+	Description     string       `json:"description"`
+	Command         string       `json:"command"`
+	ArgsPrefix      []string     `json:"args_prefix,omitempty"` // Fixed arguments prepended to user args
+	AllowedArgGlobs []string     `json:"allowed_arg_globs"`
+	DeniedArgGlobs  []string     `json:"denied_arg_globs,omitempty"` // Checked before allowed_arg_globs; any match denies the call
+	ArgRules        []ArgRule    `json:"arg_rules,omitempty"`        // Per-argument patterns (replaces allowed_arg_globs)
+	PathArgs        *PathArgs    `json:"path_args,omitempty"`        // Arguments confined to the root directory
+	Sandbox         SandboxType  `json:"sandbox"`
+	WasmBinary      string       `json:"wasm_binary"`
+	Bwrap           *BwrapConfig `json:"bwrap,omitempty"`     // Mount profile for the bubblewrap sandbox
+	FixedCwd        bool         `json:"fixed_cwd,omitempty"` // If true, cwd is fixed to root directory and not exposed to clients
+	// Copy-on-write workspace (optional, bubblewrap only)
+	Workspace        string `json:"workspace,omitempty"`         // "overlay" runs the command against an overlay of the root directory
+	WorkspaceChanges string `json:"workspace_changes,omitempty"` // "discard" (default) or "commit" the overlay's changes
+	// Execution limits (optional, default to the server-wide limits)
+	Timeout        string          `json:"timeout,omitempty"`          // Go duration, e.g. "5s" or "10m"
+	MaxOutputBytes int             `json:"max_output_bytes,omitempty"` // Stdout limit, and stderr limit unless max_stderr_bytes is set
+	MaxStderrBytes int             `json:"max_stderr_bytes,omitempty"` // Stderr limit
+	MaxConcurrency int             `json:"max_concurrency,omitempty"`  // Maximum concurrent executions of this tool (0 = unlimited)
+	Limits         *ResourceLimits `json:"limits,omitempty"`           // Memory, CPU, process and file limits for the command
+	// Typed parameters (optional, replaces the generic "args" array)
+	Parameters []Parameter  `json:"parameters,omitempty"` // Named, typed arguments exposed in the input schema
+	Argv       []string     `json:"argv,omitempty"`       // Template mapping parameters to argv (e.g. ["-n", "{count}", "{path}"])
+	Stdin      *StdinConfig `json:"stdin,omitempty"`      // Accepts a "stdin" argument written to the command's standard input
+	// Output post-processing (optional, applied in order after execution)
+	OutputFilters []OutputFilter `json:"output_filters,omitempty"`
+	// Audit logging (optional)
+	AuditBodies string `json:"audit_bodies,omitempty"` // "full" (default) or "hash" to store only the size and hash of requests and responses
 	// UI settings (optional, for MCP Apps support)
 	UIType       UIType       `json:"ui_type,omitempty"`
 	OutputFormat OutputFormat `json:"output_format,omitempty"`
@@ -111,6 +132,7 @@ func (t *Tool) IsVisibleToApp() bool {
 type PluginFile struct {
 	Tools          []Tool   `json:"tools"`
 	AllowedEnvKeys []string `json:"allowed_env_keys"`
+	DeniedArgGlobs []string `json:"denied_arg_globs,omitempty"` // Applied to every tool in the file
 }
 
 // Config holds the loaded plugin configuration
@@ -127,33 +149,15 @@ func LoadFromDir(dir string) (*Config, error) {
 		AllowedEnvKeys: []string{},
 	}
 
-	entries, err := os.ReadDir(dir)
+	filePaths, err := FindPluginFiles(dir)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read plugins directory: %w", err)
+		return nil, err
 	}
 
-	for _, entry := range entries {
-		var filePath string
-		var sourceName string
+	for _, filePath := range filePaths {
+		sourceName, _ := filepath.Rel(dir, filePath)
 
-		if entry.IsDir() {
-			// Check for plugin.json inside the directory
-			pluginPath := filepath.Join(dir, entry.Name(), "plugin.json")
-			if _, err := os.Stat(pluginPath); err != nil {
-				continue // No plugin.json in this directory
-			}
-			filePath = pluginPath
-			sourceName = entry.Name() + "/plugin.json"
-		} else {
-			name := entry.Name()
-			if !strings.HasSuffix(name, ".json") {
-				continue
-			}
-			filePath = filepath.Join(dir, name)
-			sourceName = name
-		}
-
-		pluginFile, err := loadFile(filePath)
+		pluginFile, err := LoadPluginFile(filePath)
 		if err != nil {
 			return nil, fmt.Errorf("failed to load plugin file %s: %w", sourceName, err)
 		}
@@ -178,9 +182,37 @@ func LoadFromDir(dir string) (*Config, error) {
 	return config, nil
 }
 
+// FindPluginFiles returns the plugin files in a directory: flat .json files
+// and plugin.json inside subdirectories, in directory order
+func FindPluginFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plugins directory: %w", err)
+	}
+
+	var filePaths []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			// Check for plugin.json inside the directory
+			pluginPath := filepath.Join(dir, entry.Name(), "plugin.json")
+			if _, err := os.Stat(pluginPath); err != nil {
+				continue // No plugin.json in this directory
+			}
+			filePaths = append(filePaths, pluginPath)
+		} else {
+			name := entry.Name()
+			if !strings.HasSuffix(name, ".json") {
+				continue
+			}
+			filePaths = append(filePaths, filepath.Join(dir, name))
+		}
+	}
+	return filePaths, nil
+}
+
 // LoadFromFile loads a single plugin file
 func LoadFromFile(filePath string) (*Config, error) {
-	pluginFile, err := loadFile(filePath)
+	pluginFile, err := LoadPluginFile(filePath)
 	if err != nil {
 		return nil, err
 	}
@@ -205,7 +237,23 @@ func LoadFromFile(filePath string) (*Config, error) {
 	return config, nil
 }
 
-func loadFile(filePath string) (*PluginFile, error) {
+// LoadPluginFile loads and validates a single plugin file without merging it into a Config.
+// Relative template and WASM paths are resolved against the file's directory.
+func LoadPluginFile(filePath string) (*PluginFile, error) {
+	pluginFile, err := ReadPluginFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	for i := range pluginFile.Tools {
+		if err := pluginFile.PrepareTool(i, filePath); err != nil {
+			return nil, err
+		}
+	}
+	return pluginFile, nil
+}
+
+// ReadPluginFile parses a single plugin file without validating its tools
+func ReadPluginFile(filePath string) (*PluginFile, error) {
 	data, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file: %w", err)
@@ -215,53 +263,95 @@ func loadFile(filePath string) (*PluginFile, error) {
 	if err := json.Unmarshal(data, &pluginFile); err != nil {
 		return nil, fmt.Errorf("failed to parse JSON: %w", err)
 	}
+	return &pluginFile, nil
+}
+
+// PrepareTool validates the tool at index i of a plugin file read from filePath, applies the
+// file-level deny patterns and resolves its relative template and WASM paths
+func (f *PluginFile) PrepareTool(i int, filePath string) error {
+	tool := &f.Tools[i]
 
 	// Get the directory of the plugin file for resolving relative paths
 	pluginDir := filepath.Dir(filePath)
 
-	// Validate and process tools
-	for i := range pluginFile.Tools {
-		tool := &pluginFile.Tools[i]
-		if tool.Name == "" {
-			return nil, fmt.Errorf("tool at index %d has no name", i)
+	if tool.Name == "" {
+		return fmt.Errorf("tool at index %d has no name", i)
+	}
+	if tool.Command == "" && tool.Sandbox != SandboxTypeWasm {
+		return fmt.Errorf("tool %q has no command", tool.Name)
+	}
+	if tool.Sandbox == SandboxTypeWasm && tool.WasmBinary == "" {
+		return fmt.Errorf("tool %q uses wasm sandbox but has no wasm_binary", tool.Name)
+	}
+	if err := tool.ValidateParameters(); err != nil {
+		return fmt.Errorf("tool %q: %w", tool.Name, err)
+	}
+	if err := tool.ValidateArgRules(); err != nil {
+		return fmt.Errorf("tool %q: %w", tool.Name, err)
+	}
+	if err := tool.ValidateLimits(); err != nil {
+		return fmt.Errorf("tool %q: %w", tool.Name, err)
+	}
+	if err := tool.ValidateStdin(); err != nil {
+		return fmt.Errorf("tool %q: %w", tool.Name, err)
+	}
+	if err := tool.ValidateWorkspace(); err != nil {
+		return fmt.Errorf("tool %q: %w", tool.Name, err)
+	}
+	if err := tool.ValidateOutputFilters(); err != nil {
+		return fmt.Errorf("tool %q: %w", tool.Name, err)
+	}
+	if err := tool.ValidateAuditBodies(); err != nil {
+		return fmt.Errorf("tool %q: %w", tool.Name, err)
+	}
+	if tool.PathArgs != nil {
+		if err := tool.PathArgs.Validate(); err != nil {
+			return fmt.Errorf("tool %q: %w", tool.Name, err)
 		}
-		if tool.Command == "" && tool.Sandbox != SandboxTypeWasm {
-			return nil, fmt.Errorf("tool %q has no command", tool.Name)
+	}
+	if tool.Bwrap != nil {
+		if tool.Sandbox != SandboxTypeBubblewrap {
+			return fmt.Errorf("tool %q: bwrap requires sandbox \"bubblewrap\"", tool.Name)
 		}
-		if tool.Sandbox == SandboxTypeWasm && tool.WasmBinary == "" {
-			return nil, fmt.Errorf("tool %q uses wasm sandbox but has no wasm_binary", tool.Name)
+		if err := tool.Bwrap.Validate(); err != nil {
+			return fmt.Errorf("tool %q: invalid bwrap: %w", tool.Name, err)
 		}
+	}
 
-		// Resolve and validate relative template paths
-		if tool.UITemplate != "" && !filepath.IsAbs(tool.UITemplate) {
-			// Check for parent directory traversal
-			if strings.Contains(tool.UITemplate, "..") {
-				return nil, fmt.Errorf("tool %q: template path cannot contain '..'", tool.Name)
-			}
-			resolved := filepath.Join(pluginDir, tool.UITemplate)
-			// Verify resolved path is still under plugin directory
-			if !strings.HasPrefix(filepath.Clean(resolved), filepath.Clean(pluginDir)) {
-				return nil, fmt.Errorf("tool %q: template path escapes plugin directory", tool.Name)
-			}
-			tool.UITemplate = resolved
+	// File-level deny patterns apply to every tool in the file
+	if len(f.DeniedArgGlobs) > 0 {
+		tool.DeniedArgGlobs = append(tool.DeniedArgGlobs, f.DeniedArgGlobs...)
+	}
+
+	// Resolve and validate relative template paths
+	if tool.UITemplate != "" && !filepath.IsAbs(tool.UITemplate) {
+		// Check for parent directory traversal
+		if strings.Contains(tool.UITemplate, "..") {
+			return fmt.Errorf("tool %q: template path cannot contain '..'", tool.Name)
 		}
+		resolved := filepath.Join(pluginDir, tool.UITemplate)
+		// Verify resolved path is still under plugin directory
+		if !strings.HasPrefix(filepath.Clean(resolved), filepath.Clean(pluginDir)) {
+			return fmt.Errorf("tool %q: template path escapes plugin directory", tool.Name)
+		}
+		tool.UITemplate = resolved
+	}
 
-		// Resolve and validate relative WASM binary paths
-		if tool.WasmBinary != "" && !filepath.IsAbs(tool.WasmBinary) {
-			// Check for parent directory traversal
-			if strings.Contains(tool.WasmBinary, "..") {
-				return nil, fmt.Errorf("tool %q: wasm_binary path cannot contain '..'", tool.Name)
-			}
-			resolved := filepath.Join(pluginDir, tool.WasmBinary)
-			// Verify resolved path is still under plugin directory
-			if !strings.HasPrefix(filepath.Clean(resolved), filepath.Clean(pluginDir)) {
-				return nil, fmt.Errorf("tool %q: wasm_binary path escapes plugin directory", tool.Name)
-			}
-			tool.WasmBinary = resolved
+	// Resolve and validate relative WASM binary paths
+	if tool.WasmBinary != "" && !filepath.IsAbs(tool.WasmBinary) {
+		// Check for parent directory traversal
+		if strings.Contains(tool.WasmBinary, "..") {
+			return fmt.Errorf("tool %q: wasm_binary path cannot contain '..'", tool.Name)
 		}
+		resolved := filepath.Join(pluginDir, tool.WasmBinary)
+		// Verify resolved path is still under plugin directory
+		if !strings.HasPrefix(filepath.Clean(resolved), filepath.Clean(pluginDir)) {
+			return fmt.Errorf("tool %q: wasm_binary path escapes plugin directory", tool.Name)
+		}
+		tool.WasmBinary = resolved
 	}
 
-	return &pluginFile, nil
+	return nil
 }
 
 // GetTool returns a tool by name
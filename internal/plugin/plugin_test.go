@@ -1,6 +1,9 @@
 package plugin
 
 import (
+	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
 )
 
@@ -99,3 +102,30 @@ func TestUIConfigParsing(t *testing.T) {
 		t.Error("ClipboardWrite should be true")
 	}
 }
+
+func TestLoadFromFile_FileLevelDeniedArgGlobs(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "git.json")
+	content := `{
+		"denied_arg_globs": ["--upload-pack*"],
+		"tools": [
+			{"name": "git-fetch", "command": "git", "allowed_arg_globs": ["**"], "denied_arg_globs": ["--exec=*"]},
+			{"name": "git-log", "command": "git", "allowed_arg_globs": ["**"]}
+		]
+	}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := LoadFromFile(path)
+	if err != nil {
+		t.Fatalf("LoadFromFile() error = %v", err)
+	}
+
+	if got, want := config.GetTool("git-fetch").DeniedArgGlobs, []string{"--exec=*", "--upload-pack*"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("git-fetch denied_arg_globs = %q, want %q", got, want)
+	}
+	if got, want := config.GetTool("git-log").DeniedArgGlobs, []string{"--upload-pack*"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("git-log denied_arg_globs = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,72 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+)
+
+// DefaultStdinMaxBytes is the stdin size limit when max_bytes is not set (1MB)
+const DefaultStdinMaxBytes = 1024 * 1024
+
+// Stdin content types checked before a tool is executed
+const (
+	StdinContentTypeText = "text" // Valid UTF-8
+	StdinContentTypeJSON = "json" // A single valid JSON value
+)
+
+// StdinConfig enables the "stdin" argument, whose value is written to the command's standard input
+type StdinConfig struct {
+	MaxBytes    int    `json:"max_bytes,omitempty"`    // Largest accepted input (default: DefaultStdinMaxBytes)
+	ContentType string `json:"content_type,omitempty"` // "text" or "json"; empty accepts any string
+	Description string `json:"description,omitempty"`  // Shown in the input schema
+}
+
+// validate checks the size limit and content type
+func (c *StdinConfig) validate() error {
+	if c.MaxBytes < 0 {
+		return fmt.Errorf("max_bytes cannot be negative")
+	}
+	switch c.ContentType {
+	case "", StdinContentTypeText, StdinContentTypeJSON:
+		return nil
+	}
+	return fmt.Errorf("unknown content_type %q (expected %q or %q)", c.ContentType, StdinContentTypeText, StdinContentTypeJSON)
+}
+
+// Limit returns the largest accepted input in bytes
+func (c *StdinConfig) Limit() int {
+	if c.MaxBytes > 0 {
+		return c.MaxBytes
+	}
+	return DefaultStdinMaxBytes
+}
+
+// Check returns an error if data exceeds the size limit or does not match the content type
+func (c *StdinConfig) Check(data string) error {
+	if len(data) > c.Limit() {
+		return fmt.Errorf("stdin is %d bytes, exceeding the limit of %d", len(data), c.Limit())
+	}
+	switch c.ContentType {
+	case StdinContentTypeText:
+		if !utf8.ValidString(data) {
+			return fmt.Errorf("stdin is not valid UTF-8 text")
+		}
+	case StdinContentTypeJSON:
+		if !json.Valid([]byte(data)) {
+			return fmt.Errorf("stdin is not valid JSON")
+		}
+	}
+	return nil
+}
+
+// ValidateStdin checks the tool's stdin configuration
+func (t *Tool) ValidateStdin() error {
+	if t.Stdin == nil {
+		return nil
+	}
+	if err := t.Stdin.validate(); err != nil {
+		return fmt.Errorf("invalid stdin: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package plugin
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestToolValidateStdin(t *testing.T) {
+	tests := []struct {
+		name    string
+		tool    Tool
+		wantErr bool
+	}{
+		{"no stdin", Tool{}, false},
+		{"defaults", Tool{Stdin: &StdinConfig{}}, false},
+		{"json with limit", Tool{Stdin: &StdinConfig{MaxBytes: 4096, ContentType: StdinContentTypeJSON}}, false},
+		{"text", Tool{Stdin: &StdinConfig{ContentType: StdinContentTypeText}}, false},
+		{"negative max_bytes", Tool{Stdin: &StdinConfig{MaxBytes: -1}}, true},
+		{"unknown content_type", Tool{Stdin: &StdinConfig{ContentType: "xml"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.tool.ValidateStdin(); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateStdin() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStdinConfigCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  StdinConfig
+		data    string
+		wantErr bool
+	}{
+		{"any content", StdinConfig{}, "hello\x00world", false},
+		{"within limit", StdinConfig{MaxBytes: 5}, "hello", false},
+		{"over limit", StdinConfig{MaxBytes: 4}, "hello", true},
+		{"over default limit", StdinConfig{}, strings.Repeat("x", DefaultStdinMaxBytes+1), true},
+		{"valid json", StdinConfig{ContentType: StdinContentTypeJSON}, `{"a": [1, 2]}`, false},
+		{"invalid json", StdinConfig{ContentType: StdinContentTypeJSON}, `{"a": `, true},
+		{"valid text", StdinConfig{ContentType: StdinContentTypeText}, "héllo", false},
+		{"invalid utf-8", StdinConfig{ContentType: StdinContentTypeText}, "\xff\xfe", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Check(tt.data); (err != nil) != tt.wantErr {
+				t.Errorf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTool_BuildArgs_Stdin(t *testing.T) {
+	tool := newParamTool()
+	tool.Stdin = &StdinConfig{}
+	args, err := tool.BuildArgs(map[string]interface{}{"stdin": "data", "oneline": true}, nil)
+	if err != nil {
+		t.Fatalf("BuildArgs() error = %v", err)
+	}
+	if strings.Join(args, " ") != "--oneline -n 20 --" {
+		t.Errorf("BuildArgs() = %q, want stdin kept out of argv", args)
+	}
+}
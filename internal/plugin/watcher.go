@@ -0,0 +1,140 @@
+package plugin
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultWatchInterval is the default polling interval for plugin file changes
+const DefaultWatchInterval = 2 * time.Second
+
+// WatcherConfig holds plugin watcher configuration
+type WatcherConfig struct {
+	Path     string              // Plugin directory or single plugin file
+	Interval time.Duration       // Polling interval (default DefaultWatchInterval)
+	Validate func(*Config) error // Optional: rejects a reloaded config before it is applied
+	OnReload func(*Config)       // Called with the new config after a successful reload
+	OnError  func(error)         // Optional: called when a reload fails (the previous config stays active)
+}
+
+// Watcher polls the plugin files and reloads the configuration when they change.
+// Polling is used instead of inotify so that it works on every platform and
+// with editors that replace files via rename.
+type Watcher struct {
+	config  *WatcherConfig
+	isDir   bool
+	applied string // Fingerprint of the files the current config was loaded from
+	pending string // Fingerprint seen on the previous poll, waiting to settle
+}
+
+// NewWatcher creates a Watcher for a plugin directory or single plugin file.
+// The current state of the files is taken as already loaded.
+func NewWatcher(config *WatcherConfig) (*Watcher, error) {
+	info, err := os.Stat(config.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat plugin path: %w", err)
+	}
+	if config.Interval <= 0 {
+		config.Interval = DefaultWatchInterval
+	}
+
+	w := &Watcher{
+		config: config,
+		isDir:  info.IsDir(),
+	}
+	w.applied, err = w.fingerprint()
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Run polls for changes until the context is cancelled
+func (w *Watcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := w.Check(); err != nil && w.config.OnError != nil {
+				w.config.OnError(err)
+			}
+		}
+	}
+}
+
+// Check polls the plugin files once and reloads them if they changed and have
+// been stable since the previous poll. It returns true if a new config was applied.
+// A failed reload is reported once and not retried until the files change again.
+func (w *Watcher) Check() (bool, error) {
+	current, err := w.fingerprint()
+	if err != nil {
+		return false, err
+	}
+	if current == w.applied {
+		w.pending = ""
+		return false, nil
+	}
+	if current != w.pending {
+		// Wait one more interval so that multi-file edits can settle
+		w.pending = current
+		return false, nil
+	}
+
+	w.applied = current
+	w.pending = ""
+
+	config, err := w.load()
+	if err != nil {
+		return false, err
+	}
+	if w.config.Validate != nil {
+		if err := w.config.Validate(config); err != nil {
+			return false, fmt.Errorf("invalid plugin configuration: %w", err)
+		}
+	}
+	if w.config.OnReload != nil {
+		w.config.OnReload(config)
+	}
+	return true, nil
+}
+
+// load loads the plugin configuration from the watched path
+func (w *Watcher) load() (*Config, error) {
+	if w.isDir {
+		return LoadFromDir(w.config.Path)
+	}
+	return LoadFromFile(w.config.Path)
+}
+
+// fingerprint hashes the name, size and modification time of every watched file
+func (w *Watcher) fingerprint() (string, error) {
+	h := sha256.New()
+	err := filepath.WalkDir(w.config.Path, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00%d\n", path, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to scan plugin files: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,108 @@
+package plugin
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writePluginFile(t *testing.T, path, content string, mtime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Set an explicit mtime so that changes are detected regardless of filesystem timestamp granularity
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWatcher_Check(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "tools.json")
+	base := time.Now().Add(-time.Hour)
+	writePluginFile(t, path, `{"tools": [{"name": "ls", "command": "ls"}]}`, base)
+
+	var reloaded *Config
+	w, err := NewWatcher(&WatcherConfig{
+		Path: dir,
+		Validate: func(c *Config) error {
+			if c.GetTool("forbidden") != nil {
+				return errors.New("forbidden tool")
+			}
+			return nil
+		},
+		OnReload: func(c *Config) { reloaded = c },
+	})
+	if err != nil {
+		t.Fatalf("NewWatcher() error = %v", err)
+	}
+
+	// No change
+	if changed, err := w.Check(); changed || err != nil {
+		t.Fatalf("Check() = %v, %v; want false, nil", changed, err)
+	}
+
+	// A change is applied once it has been stable for one poll
+	writePluginFile(t, path, `{"tools": [{"name": "ls", "command": "ls"}, {"name": "pwd", "command": "pwd"}]}`, base.Add(time.Second))
+	if changed, err := w.Check(); changed || err != nil {
+		t.Fatalf("Check() first poll = %v, %v; want false, nil", changed, err)
+	}
+	if changed, err := w.Check(); !changed || err != nil {
+		t.Fatalf("Check() second poll = %v, %v; want true, nil", changed, err)
+	}
+	if reloaded == nil || reloaded.GetTool("pwd") == nil {
+		t.Fatalf("expected reloaded config with pwd tool, got %+v", reloaded)
+	}
+
+	// An invalid edit is reported once and keeps the previous config
+	reloaded = nil
+	writePluginFile(t, path, `{"tools": [{"name": "forbidden", "command": "rm"}]}`, base.Add(2*time.Second))
+	w.Check()
+	if _, err := w.Check(); err == nil {
+		t.Fatal("Check() expected validation error")
+	}
+	if changed, err := w.Check(); changed || err != nil {
+		t.Fatalf("Check() after failed reload = %v, %v; want false, nil", changed, err)
+	}
+	if reloaded != nil {
+		t.Error("OnReload should not be called for an invalid config")
+	}
+
+	// A syntax error is reported as well
+	writePluginFile(t, path, `{"tools": [`, base.Add(3*time.Second))
+	w.Check()
+	if _, err := w.Check(); err == nil {
+		t.Fatal("Check() expected load error")
+	}
+}
+
+func TestWatcher_SingleFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "plugin.json")
+	base := time.Now().Add(-time.Hour)
+	writePluginFile(t, path, `{"tools": [{"name": "ls", "command": "ls"}]}`, base)
+
+	var reloaded *Config
+	w, err := NewWatcher(&WatcherConfig{Path: path, OnReload: func(c *Config) { reloaded = c }})
+	if err != nil {
+		t.Fatalf("NewWatcher() error = %v", err)
+	}
+
+	writePluginFile(t, path, `{"tools": [{"name": "cat", "command": "cat"}]}`, base.Add(time.Second))
+	w.Check()
+	if changed, err := w.Check(); !changed || err != nil {
+		t.Fatalf("Check() = %v, %v; want true, nil", changed, err)
+	}
+	if reloaded == nil || reloaded.GetTool("cat") == nil || reloaded.GetTool("ls") != nil {
+		t.Errorf("unexpected reloaded config: %+v", reloaded)
+	}
+}
+
+func TestNewWatcher_MissingPath(t *testing.T) {
+	if _, err := NewWatcher(&WatcherConfig{Path: filepath.Join(t.TempDir(), "missing")}); err == nil {
+		t.Error("NewWatcher() expected error for missing path")
+	}
+}
@@ -0,0 +1,41 @@
+package plugin
+
+import "fmt"
+
+// WorkspaceOverlay runs a bubblewrap command against a copy-on-write overlay of the root directory
+const WorkspaceOverlay = "overlay"
+
+// What happens to the changes made in an overlay workspace after the call
+const (
+	WorkspaceDiscard = "discard" // Changes are reported and thrown away (default)
+	WorkspaceCommit  = "commit"  // Changes are reported and applied to the root directory
+)
+
+// ValidateWorkspace checks the tool's workspace options
+func (t *Tool) ValidateWorkspace() error {
+	if t.Workspace == "" {
+		if t.WorkspaceChanges != "" {
+			return fmt.Errorf("workspace_changes requires workspace %q", WorkspaceOverlay)
+		}
+		return nil
+	}
+	if t.Workspace != WorkspaceOverlay {
+		return fmt.Errorf("unknown workspace %q (expected %q)", t.Workspace, WorkspaceOverlay)
+	}
+	if t.Sandbox != SandboxTypeBubblewrap {
+		return fmt.Errorf("workspace requires sandbox \"bubblewrap\"")
+	}
+	if t.Bwrap != nil && t.Bwrap.ReadonlyRoot {
+		return fmt.Errorf("workspace cannot be combined with bwrap readonly_root")
+	}
+	switch t.WorkspaceChanges {
+	case "", WorkspaceDiscard, WorkspaceCommit:
+		return nil
+	}
+	return fmt.Errorf("unknown workspace_changes %q (expected %q or %q)", t.WorkspaceChanges, WorkspaceDiscard, WorkspaceCommit)
+}
+
+// CommitsWorkspace reports whether changes made in the tool's overlay workspace are kept
+func (t *Tool) CommitsWorkspace() bool {
+	return t.Workspace == WorkspaceOverlay && t.WorkspaceChanges == WorkspaceCommit
+}
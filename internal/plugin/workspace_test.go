@@ -0,0 +1,34 @@
+package plugin
+
+import "testing"
+
+func TestToolValidateWorkspace(t *testing.T) {
+	bwrap := SandboxTypeBubblewrap
+	tests := []struct {
+		name       string
+		tool       Tool
+		wantErr    bool
+		wantCommit bool
+	}{
+		{"no workspace", Tool{}, false, false},
+		{"overlay", Tool{Sandbox: bwrap, Workspace: WorkspaceOverlay}, false, false},
+		{"discard", Tool{Sandbox: bwrap, Workspace: WorkspaceOverlay, WorkspaceChanges: WorkspaceDiscard}, false, false},
+		{"commit", Tool{Sandbox: bwrap, Workspace: WorkspaceOverlay, WorkspaceChanges: WorkspaceCommit}, false, true},
+		{"unknown workspace", Tool{Sandbox: bwrap, Workspace: "copy"}, true, false},
+		{"unknown changes", Tool{Sandbox: bwrap, Workspace: WorkspaceOverlay, WorkspaceChanges: "keep"}, true, false},
+		{"changes without workspace", Tool{Sandbox: bwrap, WorkspaceChanges: WorkspaceCommit}, true, false},
+		{"not bubblewrap", Tool{Sandbox: SandboxTypeNone, Workspace: WorkspaceOverlay}, true, false},
+		{"readonly root", Tool{Sandbox: bwrap, Workspace: WorkspaceOverlay, Bwrap: &BwrapConfig{ReadonlyRoot: true}}, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.tool.ValidateWorkspace(); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateWorkspace() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := tt.tool.CommitsWorkspace(); got != tt.wantCommit {
+				t.Errorf("CommitsWorkspace() = %v, want %v", got, tt.wantCommit)
+			}
+		})
+	}
+}
@@ -2,34 +2,92 @@ package policy
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
 
+	"github.com/takeshy/mcp-gatekeeper/internal/executor"
 	"github.com/takeshy/mcp-gatekeeper/internal/plugin"
 )
 
 // Decision represents the result of policy evaluation
 type Decision struct {
-	Allowed      bool
-	MatchedRules []string
-	Reason       string
+	Allowed      bool           `json:"allowed"`
+	MatchedRules []string       `json:"matched_rules"`
+	Reason       string         `json:"reason"`
+	Trace        []PatternCheck `json:"trace,omitempty"` // Only recorded by the Explain methods
+
+	tracing bool
+}
+
+// PatternCheck records a single pattern tried during evaluation
+type PatternCheck struct {
+	Rule    string `json:"rule"` // Rule kind, e.g. "arg_allow", "arg_deny", "arg[0]_allow", "path_root"
+	Pattern string `json:"pattern"`
+	Value   string `json:"value"` // Value the pattern was matched against (the first matching run for arg_deny)
+	Matched bool   `json:"matched"`
+}
+
+// record appends a pattern check to the trace when tracing is enabled
+func (d *Decision) record(rule, pattern, value string, matched bool) {
+	if d.tracing {
+		d.Trace = append(d.Trace, PatternCheck{Rule: rule, Pattern: pattern, Value: value, Matched: matched})
+	}
 }
 
 // Evaluator evaluates tools against requests
 type Evaluator struct {
-	matcher *Matcher
+	matcher    *Matcher
+	normalizer *executor.Normalizer
 }
 
 // NewEvaluator creates a new Evaluator
 func NewEvaluator() *Evaluator {
 	return &Evaluator{
-		matcher: NewMatcher(),
+		matcher:    NewMatcher(),
+		normalizer: executor.NewNormalizer(),
 	}
 }
 
 // EvaluateArgs evaluates if the given arguments are allowed for a tool
 func (e *Evaluator) EvaluateArgs(tool *plugin.Tool, args []string) (*Decision, error) {
-	decision := &Decision{
-		MatchedRules: make([]string, 0),
+	return e.evaluateArgs(tool, args, &Decision{MatchedRules: make([]string, 0)})
+}
+
+// ExplainArgs evaluates arguments like EvaluateArgs and records every pattern tried in Decision.Trace
+func (e *Evaluator) ExplainArgs(tool *plugin.Tool, args []string) (*Decision, error) {
+	return e.evaluateArgs(tool, args, &Decision{MatchedRules: make([]string, 0), Trace: make([]PatternCheck, 0), tracing: true})
+}
+
+// evaluateArgs implements EvaluateArgs and ExplainArgs
+func (e *Evaluator) evaluateArgs(tool *plugin.Tool, args []string, decision *Decision) (*Decision, error) {
+	// Check denied patterns first
+	denied, pattern, run, err := e.matcher.MatchArgRuns(tool.DeniedArgGlobs, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to match denied patterns: %w", err)
+	}
+	if decision.tracing {
+		// Report each deny pattern separately; the decision itself uses the combined match above
+		for _, p := range tool.DeniedArgGlobs {
+			matched, _, value, err := e.matcher.MatchArgRuns([]string{p}, args)
+			if err != nil {
+				return nil, fmt.Errorf("failed to match denied patterns: %w", err)
+			}
+			if !matched {
+				value = strings.Join(args, " ")
+			}
+			decision.record("arg_deny", p, value, matched)
+		}
+	}
+	if denied {
+		decision.Allowed = false
+		decision.Reason = fmt.Sprintf("denied by pattern %q (matched %q)", pattern, run)
+		decision.MatchedRules = append(decision.MatchedRules, fmt.Sprintf("arg_deny:%s", pattern))
+		return decision, nil
+	}
+
+	// Positional rules match each argument against its own patterns
+	if tool.HasArgRules() {
+		return e.evaluateArgRules(tool, args, decision)
 	}
 
 	// Join arguments for matching
@@ -48,6 +106,7 @@ func (e *Evaluator) EvaluateArgs(tool *plugin.Tool, args []string) (*Decision, e
 		if err != nil {
 			return nil, fmt.Errorf("failed to match pattern %q: %w", pattern, err)
 		}
+		decision.record("arg_allow", pattern, cmdline, matched)
 		if matched {
 			decision.Allowed = true
 			decision.Reason = fmt.Sprintf("allowed by pattern %q", pattern)
@@ -62,6 +121,188 @@ func (e *Evaluator) EvaluateArgs(tool *plugin.Tool, args []string) (*Decision, e
 	return decision, nil
 }
 
+// evaluateArgRules evaluates each argument against the arg rule for its position.
+// A positional rule without a corresponding argument is checked against "" so that
+// an allow list containing "" makes the argument optional.
+func (e *Evaluator) evaluateArgRules(tool *plugin.Tool, args []string, decision *Decision) (*Decision, error) {
+	count := len(args)
+	for _, rule := range tool.ArgRules {
+		if rule.Position != nil && *rule.Position+1 > count {
+			count = *rule.Position + 1
+		}
+	}
+
+	for i := 0; i < count; i++ {
+		rule := tool.ArgRuleFor(i)
+		if i >= len(args) {
+			// Missing argument: only positional rules apply
+			if rule == nil || rule.Position == nil {
+				continue
+			}
+			matched, pattern, err := e.matchAllow(decision, fmt.Sprintf("arg[%d]_allow", i), rule.Allow, "")
+			if err != nil {
+				return nil, fmt.Errorf("failed to match allowed patterns for argument %d: %w", i, err)
+			}
+			if !matched {
+				decision.Allowed = false
+				decision.Reason = fmt.Sprintf("argument %d is required", i)
+				return decision, nil
+			}
+			decision.MatchedRules = append(decision.MatchedRules, fmt.Sprintf("arg[%d]_allow:%s", i, pattern))
+			continue
+		}
+
+		arg := args[i]
+		if rule == nil {
+			decision.Allowed = false
+			decision.Reason = fmt.Sprintf("argument %d (%q) is not covered by any arg rule", i, arg)
+			return decision, nil
+		}
+
+		denied, pattern, err := e.matchDeny(decision, fmt.Sprintf("arg[%d]_deny", i), rule.Deny, arg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to match denied patterns for argument %d: %w", i, err)
+		}
+		if denied {
+			decision.Allowed = false
+			decision.Reason = fmt.Sprintf("argument %d (%q) denied by pattern %q", i, arg, pattern)
+			decision.MatchedRules = append(decision.MatchedRules, fmt.Sprintf("arg[%d]_deny:%s", i, pattern))
+			return decision, nil
+		}
+
+		matched, pattern, err := e.matchAllow(decision, fmt.Sprintf("arg[%d]_allow", i), rule.Allow, arg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to match allowed patterns for argument %d: %w", i, err)
+		}
+		if !matched {
+			decision.Allowed = false
+			decision.Reason = fmt.Sprintf("argument %d (%q) not in allowed patterns", i, arg)
+			return decision, nil
+		}
+		decision.MatchedRules = append(decision.MatchedRules, fmt.Sprintf("arg[%d]_allow:%s", i, pattern))
+	}
+
+	decision.Allowed = true
+	decision.Reason = "allowed by arg_rules"
+	return decision, nil
+}
+
+// matchAllow is MatchAny that records each pattern tried in the decision trace
+func (e *Evaluator) matchAllow(decision *Decision, rule string, patterns []string, value string) (bool, string, error) {
+	for _, pattern := range patterns {
+		matched, err := e.matcher.Match(pattern, value)
+		if err != nil {
+			return false, "", err
+		}
+		decision.record(rule, pattern, value, matched)
+		if matched {
+			return true, pattern, nil
+		}
+	}
+	return false, "", nil
+}
+
+// matchDeny is MatchAnyDeny that records each pattern tried in the decision trace
+func (e *Evaluator) matchDeny(decision *Decision, rule string, patterns []string, value string) (bool, string, error) {
+	for _, pattern := range patterns {
+		matched, _, err := e.matcher.MatchAnyDeny([]string{pattern}, value)
+		if err != nil {
+			return false, "", err
+		}
+		decision.record(rule, pattern, value, matched)
+		if matched {
+			return true, pattern, nil
+		}
+	}
+	return false, "", nil
+}
+
+// EvaluatePaths checks that the tool's declared path arguments resolve (following symlinks)
+// within rootDir and, if configured, within one of the allowed subtrees.
+// Relative paths are resolved against cwd.
+func (e *Evaluator) EvaluatePaths(tool *plugin.Tool, rootDir, cwd string, args []string) (*Decision, error) {
+	return e.evaluatePaths(tool, rootDir, cwd, args, &Decision{MatchedRules: make([]string, 0)})
+}
+
+// ExplainPaths evaluates path arguments like EvaluatePaths and records every check in Decision.Trace
+func (e *Evaluator) ExplainPaths(tool *plugin.Tool, rootDir, cwd string, args []string) (*Decision, error) {
+	return e.evaluatePaths(tool, rootDir, cwd, args, &Decision{MatchedRules: make([]string, 0), Trace: make([]PatternCheck, 0), tracing: true})
+}
+
+// evaluatePaths implements EvaluatePaths and ExplainPaths
+func (e *Evaluator) evaluatePaths(tool *plugin.Tool, rootDir, cwd string, args []string, decision *Decision) (*Decision, error) {
+	if tool.PathArgs == nil || rootDir == "" {
+		decision.Allowed = true
+		decision.Reason = "allowed (no path restrictions)"
+		return decision, nil
+	}
+
+	rootReal, err := e.normalizer.NormalizePath(rootDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve root dir: %w", err)
+	}
+	if cwd == "" {
+		cwd = rootReal
+	}
+
+	subtrees := make([]string, 0, len(tool.PathArgs.AllowedSubtrees))
+	for _, subtree := range tool.PathArgs.AllowedSubtrees {
+		subtreeReal, err := e.normalizer.NormalizePath(filepath.Join(rootReal, subtree))
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve allowed subtree %q: %w", subtree, err)
+		}
+		subtrees = append(subtrees, subtreeReal)
+	}
+
+	for _, path := range tool.PathArgs.Extract(args) {
+		target := path.Value
+		if !filepath.IsAbs(target) {
+			target = cwd + string(filepath.Separator) + target
+		}
+
+		resolved, err := e.normalizer.NormalizePath(target)
+		if err != nil {
+			decision.record("path_resolve", "", path.Value, false)
+			decision.Allowed = false
+			decision.Reason = fmt.Sprintf("path argument %d (%q) cannot be resolved: %v", path.Index, path.Value, err)
+			decision.MatchedRules = append(decision.MatchedRules, "path_deny:unresolved")
+			return decision, nil
+		}
+		withinRoot := executor.IsPathWithinRoot(rootReal, resolved)
+		decision.record("path_root", rootReal, resolved, withinRoot)
+		if !withinRoot {
+			decision.Allowed = false
+			decision.Reason = fmt.Sprintf("path argument %d (%q) is outside the root directory", path.Index, path.Value)
+			decision.MatchedRules = append(decision.MatchedRules, "path_deny:root")
+			return decision, nil
+		}
+
+		if len(subtrees) == 0 {
+			continue
+		}
+		allowed := false
+		for i, subtree := range subtrees {
+			within := executor.IsPathWithinRoot(subtree, resolved)
+			decision.record("path_allow", tool.PathArgs.AllowedSubtrees[i], resolved, within)
+			if within {
+				decision.MatchedRules = append(decision.MatchedRules, fmt.Sprintf("path_allow:%s", tool.PathArgs.AllowedSubtrees[i]))
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			decision.Allowed = false
+			decision.Reason = fmt.Sprintf("path argument %d (%q) is outside the allowed subtrees", path.Index, path.Value)
+			decision.MatchedRules = append(decision.MatchedRules, "path_deny:subtree")
+			return decision, nil
+		}
+	}
+
+	decision.Allowed = true
+	decision.Reason = "path arguments within allowed directories"
+	return decision, nil
+}
+
 // FilterEnvKeys filters environment variable keys based on allowed patterns
 func (e *Evaluator) FilterEnvKeys(allowedEnvKeys []string, envKeys []string) []string {
 	if len(allowedEnvKeys) == 0 {
@@ -92,6 +333,33 @@ func ValidateTool(tool *plugin.Tool) error {
 		}
 	}
 
+	for _, pattern := range tool.DeniedArgGlobs {
+		if _, err := matcher.CompileDeny(pattern); err != nil {
+			return fmt.Errorf("invalid denied_arg_glob %q: %w", pattern, err)
+		}
+	}
+
+	for i, rule := range tool.ArgRules {
+		for _, pattern := range rule.Allow {
+			if _, err := matcher.Compile(pattern); err != nil {
+				return fmt.Errorf("arg rule at index %d: invalid allow pattern %q: %w", i, pattern, err)
+			}
+		}
+		for _, pattern := range rule.Deny {
+			if _, err := matcher.CompileDeny(pattern); err != nil {
+				return fmt.Errorf("arg rule at index %d: invalid deny pattern %q: %w", i, pattern, err)
+			}
+		}
+	}
+	if err := tool.ValidateArgRules(); err != nil {
+		return err
+	}
+	if tool.PathArgs != nil {
+		if err := tool.PathArgs.Validate(); err != nil {
+			return err
+		}
+	}
+
 	// Validate sandbox type
 	switch tool.Sandbox {
 	case plugin.SandboxTypeNone, plugin.SandboxTypeBubblewrap, plugin.SandboxTypeWasm:
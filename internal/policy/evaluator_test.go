@@ -1,6 +1,9 @@
 package policy
 
 import (
+	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
 
 	"github.com/takeshy/mcp-gatekeeper/internal/plugin"
@@ -97,6 +100,310 @@ func TestEvaluator_EvaluateArgs(t *testing.T) {
 	}
 }
 
+func TestEvaluator_EvaluateArgs_DeniedArgGlobs(t *testing.T) {
+	e := NewEvaluator()
+	tool := &plugin.Tool{
+		Name:            "git-any",
+		Command:         "git",
+		AllowedArgGlobs: []string{"**"},
+		DeniedArgGlobs:  []string{"--exec=*", "-c *", "--upload-pack*"},
+		Sandbox:         plugin.SandboxTypeNone,
+	}
+
+	tests := []struct {
+		name        string
+		args        []string
+		wantAllowed bool
+		wantRule    string
+	}{
+		{
+			name:        "allowed when no deny pattern matches",
+			args:        []string{"log", "--oneline"},
+			wantAllowed: true,
+			wantRule:    "arg_allow:**",
+		},
+		{
+			name:        "deny single argument in the middle",
+			args:        []string{"rebase", "--exec=rm -rf /", "main"},
+			wantAllowed: false,
+			wantRule:    "arg_deny:--exec=*",
+		},
+		{
+			name:        "deny multi-token sequence",
+			args:        []string{"-c", "core.pager=sh", "log"},
+			wantAllowed: false,
+			wantRule:    "arg_deny:-c *",
+		},
+		{
+			name:        "deny prefix pattern",
+			args:        []string{"fetch", "--upload-pack=evil"},
+			wantAllowed: false,
+			wantRule:    "arg_deny:--upload-pack*",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision, err := e.EvaluateArgs(tool, tt.args)
+			if err != nil {
+				t.Fatalf("EvaluateArgs() error = %v", err)
+			}
+			if decision.Allowed != tt.wantAllowed {
+				t.Errorf("EvaluateArgs() allowed = %v, want %v (reason: %s)", decision.Allowed, tt.wantAllowed, decision.Reason)
+			}
+			if len(decision.MatchedRules) != 1 || decision.MatchedRules[0] != tt.wantRule {
+				t.Errorf("EvaluateArgs() matched rules = %v, want [%s]", decision.MatchedRules, tt.wantRule)
+			}
+		})
+	}
+}
+
+func TestEvaluator_EvaluateArgs_ArgRules(t *testing.T) {
+	e := NewEvaluator()
+	zero, one := 0, 1
+	tool := &plugin.Tool{
+		Name:    "git",
+		Command: "git",
+		ArgRules: []plugin.ArgRule{
+			{Position: &zero, Allow: []string{"log", "show"}},
+			{Position: &one, Allow: []string{"", "--oneline"}},
+			{Rest: true, Allow: []string{"*"}, Deny: []string{"--output*"}},
+		},
+		DeniedArgGlobs: []string{"--exec=*"},
+		Sandbox:        plugin.SandboxTypeNone,
+	}
+
+	tests := []struct {
+		name        string
+		args        []string
+		wantAllowed bool
+		wantReason  string
+	}{
+		{
+			name:        "first argument only",
+			args:        []string{"log"},
+			wantAllowed: true,
+		},
+		{
+			name:        "positional and rest arguments",
+			args:        []string{"log", "--oneline", "main", "README.md"},
+			wantAllowed: true,
+		},
+		{
+			name:        "missing required argument",
+			args:        []string{},
+			wantAllowed: false,
+			wantReason:  "argument 0 is required",
+		},
+		{
+			name:        "argument containing spaces is matched as one token",
+			args:        []string{"log --all"},
+			wantAllowed: false,
+			wantReason:  `argument 0 ("log --all") not in allowed patterns`,
+		},
+		{
+			name:        "second position rejects other values",
+			args:        []string{"show", "--stat"},
+			wantAllowed: false,
+			wantReason:  `argument 1 ("--stat") not in allowed patterns`,
+		},
+		{
+			name:        "rest deny pattern",
+			args:        []string{"log", "", "--output=/tmp/x"},
+			wantAllowed: false,
+			wantReason:  `argument 2 ("--output=/tmp/x") denied by pattern "--output*"`,
+		},
+		{
+			name:        "tool-level deny still applies",
+			args:        []string{"log", "--oneline", "--exec=sh"},
+			wantAllowed: false,
+			wantReason:  `denied by pattern "--exec=*" (matched "--exec=sh")`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision, err := e.EvaluateArgs(tool, tt.args)
+			if err != nil {
+				t.Fatalf("EvaluateArgs() error = %v", err)
+			}
+			if decision.Allowed != tt.wantAllowed {
+				t.Errorf("EvaluateArgs() allowed = %v, want %v (reason: %s)", decision.Allowed, tt.wantAllowed, decision.Reason)
+			}
+			if tt.wantReason != "" && decision.Reason != tt.wantReason {
+				t.Errorf("EvaluateArgs() reason = %q, want %q", decision.Reason, tt.wantReason)
+			}
+		})
+	}
+
+	// Without a rest rule, extra arguments are rejected
+	strict := &plugin.Tool{
+		Name:     "ls",
+		ArgRules: []plugin.ArgRule{{Position: &zero, Allow: []string{"-la"}}},
+	}
+	decision, err := e.EvaluateArgs(strict, []string{"-la", "/etc"})
+	if err != nil {
+		t.Fatalf("EvaluateArgs() error = %v", err)
+	}
+	if decision.Allowed {
+		t.Error("EvaluateArgs() expected extra argument to be denied")
+	}
+}
+
+func TestEvaluator_ExplainArgs(t *testing.T) {
+	e := NewEvaluator()
+	pos0 := 0
+	tool := &plugin.Tool{
+		Name:           "git",
+		Command:        "git",
+		DeniedArgGlobs: []string{"--exec=*"},
+		ArgRules: []plugin.ArgRule{
+			{Position: &pos0, Allow: []string{"log", "show"}},
+			{Rest: true, Allow: []string{"--oneline"}, Deny: []string{"-p"}},
+		},
+		Sandbox: plugin.SandboxTypeNone,
+	}
+
+	decision, err := e.ExplainArgs(tool, []string{"show", "-p"})
+	if err != nil {
+		t.Fatalf("ExplainArgs() error = %v", err)
+	}
+	if decision.Allowed {
+		t.Error("ExplainArgs() allowed = true, want false")
+	}
+	want := []PatternCheck{
+		{Rule: "arg_deny", Pattern: "--exec=*", Value: "show -p", Matched: false},
+		{Rule: "arg[0]_allow", Pattern: "log", Value: "show", Matched: false},
+		{Rule: "arg[0]_allow", Pattern: "show", Value: "show", Matched: true},
+		{Rule: "arg[1]_deny", Pattern: "-p", Value: "-p", Matched: true},
+	}
+	if !reflect.DeepEqual(decision.Trace, want) {
+		t.Errorf("ExplainArgs() trace = %+v, want %+v", decision.Trace, want)
+	}
+
+	// EvaluateArgs returns the same decision without a trace
+	plain, err := e.EvaluateArgs(tool, []string{"show", "-p"})
+	if err != nil {
+		t.Fatalf("EvaluateArgs() error = %v", err)
+	}
+	if plain.Trace != nil || plain.Reason != decision.Reason {
+		t.Errorf("EvaluateArgs() = %+v, want reason %q and no trace", plain, decision.Reason)
+	}
+}
+
+func TestEvaluator_EvaluatePaths(t *testing.T) {
+	e := NewEvaluator()
+
+	rootDir := t.TempDir()
+	outside := t.TempDir()
+	for _, dir := range []string{"src", "docs"} {
+		if err := os.Mkdir(filepath.Join(rootDir, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(outside, filepath.Join(rootDir, "src", "escape")); err != nil {
+		t.Fatal(err)
+	}
+
+	tool := &plugin.Tool{
+		Name:     "git-blame",
+		Command:  "git",
+		PathArgs: &plugin.PathArgs{AfterSeparator: true, Flags: []string{"--contents"}},
+	}
+	subtreeTool := &plugin.Tool{
+		Name:     "cat",
+		Command:  "cat",
+		PathArgs: &plugin.PathArgs{NonFlags: true, AllowedSubtrees: []string{"src"}},
+	}
+
+	tests := []struct {
+		name        string
+		tool        *plugin.Tool
+		cwd         string
+		args        []string
+		wantAllowed bool
+		wantRule    string
+	}{
+		{
+			name:        "no path args declared",
+			tool:        &plugin.Tool{Name: "echo"},
+			args:        []string{"/etc/passwd"},
+			wantAllowed: true,
+		},
+		{
+			name:        "relative path within root",
+			tool:        tool,
+			args:        []string{"-w", "--", "src/main.go"},
+			wantAllowed: true,
+		},
+		{
+			name:        "parent traversal",
+			tool:        tool,
+			args:        []string{"--", "../../etc/passwd"},
+			wantAllowed: false,
+			wantRule:    "path_deny:root",
+		},
+		{
+			name:        "absolute path outside root",
+			tool:        tool,
+			args:        []string{"--contents", "/etc/passwd", "--", "src/main.go"},
+			wantAllowed: false,
+			wantRule:    "path_deny:root",
+		},
+		{
+			name:        "symlink escaping root",
+			tool:        tool,
+			args:        []string{"--", "src/escape/secret"},
+			wantAllowed: false,
+			wantRule:    "path_deny:root",
+		},
+		{
+			name:        "relative to cwd",
+			tool:        tool,
+			cwd:         filepath.Join(rootDir, "src"),
+			args:        []string{"--", "../docs/README.md"},
+			wantAllowed: true,
+		},
+		{
+			name:        "inside allowed subtree",
+			tool:        subtreeTool,
+			args:        []string{"-n", "src/main.go"},
+			wantAllowed: true,
+			wantRule:    "path_allow:src",
+		},
+		{
+			name:        "outside allowed subtree",
+			tool:        subtreeTool,
+			args:        []string{"docs/README.md"},
+			wantAllowed: false,
+			wantRule:    "path_deny:subtree",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision, err := e.EvaluatePaths(tt.tool, rootDir, tt.cwd, tt.args)
+			if err != nil {
+				t.Fatalf("EvaluatePaths() error = %v", err)
+			}
+			if decision.Allowed != tt.wantAllowed {
+				t.Errorf("EvaluatePaths() allowed = %v, want %v (reason: %s)", decision.Allowed, tt.wantAllowed, decision.Reason)
+			}
+			if tt.wantRule != "" {
+				found := false
+				for _, rule := range decision.MatchedRules {
+					if rule == tt.wantRule {
+						found = true
+					}
+				}
+				if !found {
+					t.Errorf("EvaluatePaths() matched rules = %v, want %s", decision.MatchedRules, tt.wantRule)
+				}
+			}
+		})
+	}
+}
+
 func TestEvaluator_FilterEnvKeys(t *testing.T) {
 	e := NewEvaluator()
 
@@ -200,6 +507,26 @@ func TestValidateTool(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "invalid denied arg glob pattern",
+			tool: &plugin.Tool{
+				Name:           "test-tool",
+				Command:        "/usr/bin/test",
+				DeniedArgGlobs: []string{"[invalid"},
+				Sandbox:        plugin.SandboxTypeNone,
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid arg rule pattern",
+			tool: &plugin.Tool{
+				Name:     "test-tool",
+				Command:  "/usr/bin/test",
+				ArgRules: []plugin.ArgRule{{Rest: true, Allow: []string{"[invalid"}}},
+				Sandbox:  plugin.SandboxTypeNone,
+			},
+			wantErr: true,
+		},
 		{
 			name: "invalid sandbox type",
 			tool: &plugin.Tool{
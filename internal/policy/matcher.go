@@ -2,6 +2,7 @@ package policy
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 
 	"github.com/gobwas/glob"
@@ -9,7 +10,8 @@ import (
 
 // Matcher handles glob pattern matching with caching
 type Matcher struct {
-	cache sync.Map // map[string]glob.Glob
+	cache     sync.Map // map[string]glob.Glob
+	denyCache sync.Map // map[string]glob.Glob, compiled without separators
 }
 
 // NewMatcher creates a new Matcher
@@ -32,6 +34,22 @@ func (m *Matcher) Compile(pattern string) (glob.Glob, error) {
 	return g, nil
 }
 
+// CompileDeny compiles a deny glob pattern (with caching).
+// Deny patterns have no separator, so "*" also matches '/' and "--exec=*" blocks "--exec=/bin/sh".
+func (m *Matcher) CompileDeny(pattern string) (glob.Glob, error) {
+	if cached, ok := m.denyCache.Load(pattern); ok {
+		return cached.(glob.Glob), nil
+	}
+
+	g, err := glob.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob pattern %q: %w", pattern, err)
+	}
+
+	m.denyCache.Store(pattern, g)
+	return g, nil
+}
+
 // Match checks if a value matches a pattern
 func (m *Matcher) Match(pattern, value string) (bool, error) {
 	g, err := m.Compile(pattern)
@@ -55,6 +73,52 @@ func (m *Matcher) MatchAny(patterns []string, value string) (matched bool, match
 	return false, "", nil
 }
 
+// MatchAnyDeny checks if a value matches any of the deny patterns
+func (m *Matcher) MatchAnyDeny(patterns []string, value string) (matched bool, matchedPattern string, err error) {
+	for _, pattern := range patterns {
+		g, err := m.CompileDeny(pattern)
+		if err != nil {
+			return false, "", err
+		}
+		if g.Match(value) {
+			return true, pattern, nil
+		}
+	}
+	return false, "", nil
+}
+
+// MatchArgRuns checks deny patterns against the full command line, each single argument
+// and each adjacent pair of arguments joined with a space (such as "-c foo"). Longer runs
+// are only seen as part of the full command line, which keeps the check linear.
+func (m *Matcher) MatchArgRuns(patterns []string, args []string) (matched bool, matchedPattern string, run string, err error) {
+	if len(patterns) == 0 {
+		return false, "", "", nil
+	}
+
+	// The full command line (also covers calls without arguments)
+	cmdline := strings.Join(args, " ")
+	if matched, pattern, err := m.MatchAnyDeny(patterns, cmdline); err != nil || matched {
+		return matched, pattern, cmdline, err
+	}
+	if len(args) == 1 {
+		return false, "", "", nil // The only argument is the full command line
+	}
+
+	for i, arg := range args {
+		if matched, pattern, err := m.MatchAnyDeny(patterns, arg); err != nil || matched {
+			return matched, pattern, arg, err
+		}
+		if i+1 < len(args) && len(args) > 2 {
+			pair := arg + " " + args[i+1]
+			if matched, pattern, err := m.MatchAnyDeny(patterns, pair); err != nil || matched {
+				return matched, pattern, pair, err
+			}
+		}
+	}
+
+	return false, "", "", nil
+}
+
 // MatchAll checks if a value matches all patterns
 func (m *Matcher) MatchAll(patterns []string, value string) (bool, error) {
 	for _, pattern := range patterns {
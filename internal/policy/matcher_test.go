@@ -150,3 +150,84 @@ func TestMatcher_MatchCwd(t *testing.T) {
 		})
 	}
 }
+
+func TestMatcher_MatchArgRuns(t *testing.T) {
+	m := NewMatcher()
+
+	tests := []struct {
+		name        string
+		patterns    []string
+		args        []string
+		wantMatched bool
+		wantRun     string
+	}{
+		{
+			name:        "no patterns",
+			patterns:    []string{},
+			args:        []string{"log"},
+			wantMatched: false,
+		},
+		{
+			name:        "full command line",
+			patterns:    []string{"push *"},
+			args:        []string{"push", "origin"},
+			wantMatched: true,
+			wantRun:     "push origin",
+		},
+		{
+			name:        "single argument",
+			patterns:    []string{"--exec=*"},
+			args:        []string{"rebase", "--exec=make", "main"},
+			wantMatched: true,
+			wantRun:     "--exec=make",
+		},
+		{
+			name:        "contiguous run",
+			patterns:    []string{"-c *"},
+			args:        []string{"status", "-c", "x=y"},
+			wantMatched: true,
+			wantRun:     "-c x=y",
+		},
+		{
+			name:        "star crosses slash",
+			patterns:    []string{"--exec=*"},
+			args:        []string{"--exec=/bin/sh"},
+			wantMatched: true,
+			wantRun:     "--exec=/bin/sh",
+		},
+		{
+			name:        "runs longer than a pair only match the full command line",
+			patterns:    []string{"a b c"},
+			args:        []string{"x", "a", "b", "c"},
+			wantMatched: false,
+		},
+		{
+			name:        "no match",
+			patterns:    []string{"--exec=*"},
+			args:        []string{"log", "--oneline"},
+			wantMatched: false,
+		},
+		{
+			name:        "empty args match empty pattern",
+			patterns:    []string{""},
+			args:        []string{},
+			wantMatched: true,
+			wantRun:     "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matched, _, run, err := m.MatchArgRuns(tt.patterns, tt.args)
+			if err != nil {
+				t.Fatalf("MatchArgRuns() error = %v", err)
+			}
+			if matched != tt.wantMatched {
+				t.Errorf("MatchArgRuns() matched = %v, want %v", matched, tt.wantMatched)
+			}
+			if matched && run != tt.wantRun {
+				t.Errorf("MatchArgRuns() run = %q, want %q", run, tt.wantRun)
+			}
+		})
+	}
+}
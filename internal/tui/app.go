@@ -2,7 +2,9 @@ package tui
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -17,8 +19,13 @@ const (
 	ScreenMain Screen = iota
 	ScreenOAuthClients
 	ScreenOAuthClientNew
+	ScreenOAuthClientScopes
 	ScreenOAuthClientCreated
 	ScreenOAuthClientConfirmDelete
+	ScreenAPIKeys
+	ScreenAPIKeyNew
+	ScreenAPIKeyCreated
+	ScreenAPIKeyConfirmDelete
 	ScreenAuditLogs
 )
 
@@ -69,6 +76,15 @@ type App struct {
 	selectedClient *db.OAuthClient
 	newClientID   string
 	newClientSecret string
+	newClientScopes []string
+	apiKeys       []*db.APIKey
+	selectedAPIKey *db.APIKey
+	newKeyStep    int // Current prompt of the new API key form (see apiKeyPrompts)
+	newKeyName    string
+	newKeyTools   []string
+	newKeyRateLimit int
+	newKeyExpiresAt *time.Time
+	newKey        string
 	inputMode     bool
 	inputValue    string
 }
@@ -136,6 +152,12 @@ func (a *App) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			a.screen = ScreenOAuthClientConfirmDelete
 			a.cursor = 0
 		}
+		// Delete API key
+		if a.screen == ScreenAPIKeys && len(a.apiKeys) > 0 && a.cursor < len(a.apiKeys) {
+			a.selectedAPIKey = a.apiKeys[a.cursor]
+			a.screen = ScreenAPIKeyConfirmDelete
+			a.cursor = 0
+		}
 	case "r":
 		// Revoke client
 		if a.screen == ScreenOAuthClients && len(a.oauthClients) > 0 && a.cursor < len(a.oauthClients) {
@@ -149,6 +171,18 @@ func (a *App) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				}
 			}
 		}
+		// Revoke API key
+		if a.screen == ScreenAPIKeys && len(a.apiKeys) > 0 && a.cursor < len(a.apiKeys) {
+			key := a.apiKeys[a.cursor]
+			if key.Status == "active" {
+				if err := a.db.RevokeAPIKey(key.ID); err != nil {
+					a.err = err
+				} else {
+					a.message = fmt.Sprintf("API key '%s' revoked", key.Name)
+					a.loadAPIKeys()
+				}
+			}
+		}
 	}
 	return a, nil
 }
@@ -157,27 +191,40 @@ func (a *App) handleInputMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "enter":
 		if a.screen == ScreenOAuthClientNew {
-			// Create new client
+			// Ask for scopes next
 			clientID := strings.TrimSpace(a.inputValue)
 			if clientID == "" {
 				a.err = fmt.Errorf("client ID cannot be empty")
 				return a, nil
 			}
-			clientSecret, err := a.db.CreateOAuthClient(clientID)
+			a.newClientID = clientID
+			a.inputValue = ""
+			a.err = nil
+			a.screen = ScreenOAuthClientScopes
+		} else if a.screen == ScreenOAuthClientScopes {
+			// Create new client
+			scopes := db.ParseScope(a.inputValue)
+			clientSecret, err := a.db.CreateOAuthClient(a.newClientID, scopes)
 			if err != nil {
 				a.err = err
 				return a, nil
 			}
-			a.newClientID = clientID
 			a.newClientSecret = clientSecret
+			a.newClientScopes = scopes
 			a.inputMode = false
 			a.inputValue = ""
 			a.screen = ScreenOAuthClientCreated
+		} else if a.screen == ScreenAPIKeyNew {
+			return a.handleAPIKeyInput()
 		}
 	case "esc":
 		a.inputMode = false
 		a.inputValue = ""
-		a.screen = ScreenOAuthClients
+		if a.screen == ScreenAPIKeyNew {
+			a.screen = ScreenAPIKeys
+		} else {
+			a.screen = ScreenOAuthClients
+		}
 		a.cursor = 0
 	case "backspace":
 		if len(a.inputValue) > 0 {
@@ -191,6 +238,68 @@ func (a *App) handleInputMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return a, nil
 }
 
+// apiKeyPrompts are the prompts of the new API key form, one per step
+var apiKeyPrompts = []string{
+	"Enter key name:",
+	"Enter allowed tools (space-separated globs, e.g. \"git-* ls\"; empty = all tools):",
+	"Enter rate limit per minute (empty or 0 = server limit only):",
+	"Enter expiry in days (empty = never expires):",
+}
+
+// handleAPIKeyInput accepts the current step of the new API key form
+func (a *App) handleAPIKeyInput() (tea.Model, tea.Cmd) {
+	value := strings.TrimSpace(a.inputValue)
+	switch a.newKeyStep {
+	case 0:
+		if value == "" {
+			a.err = fmt.Errorf("key name cannot be empty")
+			return a, nil
+		}
+		a.newKeyName = value
+	case 1:
+		a.newKeyTools = strings.Fields(value)
+	case 2:
+		a.newKeyRateLimit = 0
+		if value != "" {
+			limit, err := strconv.Atoi(value)
+			if err != nil || limit < 0 {
+				a.err = fmt.Errorf("rate limit must be a non-negative number")
+				return a, nil
+			}
+			a.newKeyRateLimit = limit
+		}
+	case 3:
+		a.newKeyExpiresAt = nil
+		if value != "" {
+			days, err := strconv.Atoi(value)
+			if err != nil || days <= 0 {
+				a.err = fmt.Errorf("expiry must be a positive number of days")
+				return a, nil
+			}
+			expiresAt := time.Now().AddDate(0, 0, days)
+			a.newKeyExpiresAt = &expiresAt
+		}
+
+		// Create new API key
+		key, err := a.db.CreateAPIKey(a.newKeyName, a.newKeyTools, a.newKeyRateLimit, a.newKeyExpiresAt)
+		if err != nil {
+			a.err = err
+			return a, nil
+		}
+		a.newKey = key
+		a.inputMode = false
+		a.inputValue = ""
+		a.err = nil
+		a.screen = ScreenAPIKeyCreated
+		return a, nil
+	}
+
+	a.newKeyStep++
+	a.inputValue = ""
+	a.err = nil
+	return a, nil
+}
+
 func (a *App) handleEnter() (tea.Model, tea.Cmd) {
 	switch a.screen {
 	case ScreenMain:
@@ -214,6 +323,40 @@ func (a *App) handleEnter() (tea.Model, tea.Cmd) {
 		a.screen = ScreenOAuthClients
 		a.cursor = 0
 		a.loadOAuthClients()
+	case ScreenAPIKeys:
+		// Handle menu at bottom of key list
+		menuOffset := len(a.apiKeys)
+		if a.cursor == menuOffset {
+			// New API Key
+			a.screen = ScreenAPIKeyNew
+			a.newKeyStep = 0
+			a.inputMode = true
+			a.inputValue = ""
+			a.err = nil
+			a.message = ""
+		} else if a.cursor == menuOffset+1 {
+			// Back
+			a.screen = ScreenMain
+			a.cursor = 0
+		}
+	case ScreenAPIKeyCreated:
+		a.newKey = ""
+		a.screen = ScreenAPIKeys
+		a.cursor = 0
+		a.loadAPIKeys()
+	case ScreenAPIKeyConfirmDelete:
+		if a.cursor == 0 {
+			// Confirm delete
+			if err := a.db.DeleteAPIKey(a.selectedAPIKey.ID); err != nil {
+				a.err = err
+			} else {
+				a.message = fmt.Sprintf("API key '%s' deleted", a.selectedAPIKey.Name)
+			}
+		}
+		a.selectedAPIKey = nil
+		a.screen = ScreenAPIKeys
+		a.cursor = 0
+		a.loadAPIKeys()
 	case ScreenOAuthClientConfirmDelete:
 		if a.cursor == 0 {
 			// Confirm delete
@@ -244,10 +387,16 @@ func (a *App) handleMainMenuEnter() (tea.Model, tea.Cmd) {
 		a.err = nil
 		a.message = ""
 		a.loadOAuthClients()
-	case 1: // Audit Logs
+	case 1: // API Keys
+		a.screen = ScreenAPIKeys
+		a.cursor = 0
+		a.err = nil
+		a.message = ""
+		a.loadAPIKeys()
+	case 2: // Audit Logs
 		a.screen = ScreenAuditLogs
 		a.cursor = 0
-	case 2: // Quit
+	case 3: // Quit
 		return a, tea.Quit
 	}
 	return a, nil
@@ -262,6 +411,15 @@ func (a *App) loadOAuthClients() {
 	a.oauthClients = clients
 }
 
+func (a *App) loadAPIKeys() {
+	keys, err := a.db.ListAPIKeys()
+	if err != nil {
+		a.err = err
+		return
+	}
+	a.apiKeys = keys
+}
+
 // View renders the UI
 func (a *App) View() string {
 	var b strings.Builder
@@ -273,10 +431,20 @@ func (a *App) View() string {
 		b.WriteString(a.viewOAuthClients())
 	case ScreenOAuthClientNew:
 		b.WriteString(a.viewOAuthClientNew())
+	case ScreenOAuthClientScopes:
+		b.WriteString(a.viewOAuthClientScopes())
 	case ScreenOAuthClientCreated:
 		b.WriteString(a.viewOAuthClientCreated())
 	case ScreenOAuthClientConfirmDelete:
 		b.WriteString(a.viewOAuthClientConfirmDelete())
+	case ScreenAPIKeys:
+		b.WriteString(a.viewAPIKeys())
+	case ScreenAPIKeyNew:
+		b.WriteString(a.viewAPIKeyNew())
+	case ScreenAPIKeyCreated:
+		b.WriteString(a.viewAPIKeyCreated())
+	case ScreenAPIKeyConfirmDelete:
+		b.WriteString(a.viewAPIKeyConfirmDelete())
 	case ScreenAuditLogs:
 		b.WriteString(a.viewAuditLogs())
 	}
@@ -293,6 +461,7 @@ func (a *App) viewMain() string {
 
 	menuItems := []string{
 		"OAuth Clients",
+		"API Keys",
 		"Audit Logs",
 		"Quit",
 	}
@@ -341,9 +510,10 @@ func (a *App) viewOAuthClients() string {
 				statusStr = errorStyle.Render("revoked")
 			}
 
-			item := fmt.Sprintf("%s [%s] - Created: %s",
+			item := fmt.Sprintf("%s [%s] - Scopes: %s - Created: %s",
 				client.ClientID,
 				statusStr,
+				formatScopes(client.Scopes),
 				client.CreatedAt.Format("2006-01-02 15:04"))
 
 			if i == a.cursor {
@@ -400,6 +570,35 @@ func (a *App) viewOAuthClientNew() string {
 	return b.String()
 }
 
+func (a *App) viewOAuthClientScopes() string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render("New OAuth Client"))
+	b.WriteString("\n\n")
+
+	if a.err != nil {
+		b.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", a.err)))
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString(fmt.Sprintf("Client ID: %s\n\n", a.newClientID))
+	b.WriteString("Enter scopes (space-separated, e.g. \"tools:git-status tools:git-log\"; empty = all tools):\n\n")
+	b.WriteString(boxStyle.Render(a.inputValue + "_"))
+	b.WriteString("\n")
+
+	b.WriteString(helpStyle.Render("\n[Enter] Create  [Esc] Cancel"))
+
+	return b.String()
+}
+
+// formatScopes formats client scopes for display
+func formatScopes(scopes []string) string {
+	if len(scopes) == 0 {
+		return "all tools"
+	}
+	return db.FormatScope(scopes)
+}
+
 func (a *App) viewOAuthClientCreated() string {
 	var b strings.Builder
 
@@ -412,7 +611,7 @@ func (a *App) viewOAuthClientCreated() string {
 	b.WriteString(warningStyle.Render("IMPORTANT: Save the client secret now. It will not be shown again!"))
 	b.WriteString("\n\n")
 
-	content := fmt.Sprintf("Client ID:     %s\nClient Secret: %s", a.newClientID, a.newClientSecret)
+	content := fmt.Sprintf("Client ID:     %s\nClient Secret: %s\nScopes:        %s", a.newClientID, a.newClientSecret, formatScopes(a.newClientScopes))
 	b.WriteString(boxStyle.Render(content))
 	b.WriteString("\n")
 
@@ -452,6 +651,188 @@ func (a *App) viewOAuthClientConfirmDelete() string {
 	return b.String()
 }
 
+func (a *App) viewAPIKeys() string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render("API Keys"))
+	b.WriteString("\n\n")
+
+	if a.err != nil {
+		b.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", a.err)))
+		b.WriteString("\n\n")
+	}
+
+	if a.message != "" {
+		b.WriteString(successStyle.Render(a.message))
+		b.WriteString("\n\n")
+	}
+
+	if len(a.apiKeys) == 0 {
+		b.WriteString("No API keys found.\n\n")
+	} else {
+		for i, key := range a.apiKeys {
+			statusStr := successStyle.Render("active")
+			if key.Status == "revoked" {
+				statusStr = errorStyle.Render("revoked")
+			} else if key.ExpiresAt != nil && time.Now().After(*key.ExpiresAt) {
+				statusStr = warningStyle.Render("expired")
+			}
+
+			item := fmt.Sprintf("%s [%s] - Tools: %s - Rate limit: %s - Expires: %s",
+				key.Name,
+				statusStr,
+				formatTools(key.Tools),
+				formatRateLimit(key.RateLimit),
+				formatExpiry(key.ExpiresAt))
+
+			if i == a.cursor {
+				b.WriteString(selectedItemStyle.Render("> " + item))
+			} else {
+				b.WriteString(menuItemStyle.Render("  " + item))
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
+	// Menu options
+	menuItems := []string{"[New API Key]", "[Back]"}
+	menuOffset := len(a.apiKeys)
+
+	for i, item := range menuItems {
+		if a.cursor == menuOffset+i {
+			b.WriteString(selectedItemStyle.Render("> " + item))
+		} else {
+			b.WriteString(menuItemStyle.Render("  " + item))
+		}
+		b.WriteString("\n")
+	}
+
+	// Clamp cursor
+	maxCursor := menuOffset + len(menuItems) - 1
+	if a.cursor > maxCursor {
+		a.cursor = maxCursor
+	}
+
+	b.WriteString(helpStyle.Render("\n[j/k] Navigate  [Enter] Select  [r] Revoke  [d] Delete  [Esc] Back"))
+
+	return b.String()
+}
+
+func (a *App) viewAPIKeyNew() string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render("New API Key"))
+	b.WriteString("\n\n")
+
+	if a.err != nil {
+		b.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", a.err)))
+		b.WriteString("\n\n")
+	}
+
+	if a.newKeyStep > 0 {
+		b.WriteString(fmt.Sprintf("Name: %s\n", a.newKeyName))
+	}
+	if a.newKeyStep > 1 {
+		b.WriteString(fmt.Sprintf("Tools: %s\n", formatTools(a.newKeyTools)))
+	}
+	if a.newKeyStep > 2 {
+		b.WriteString(fmt.Sprintf("Rate limit: %s\n", formatRateLimit(a.newKeyRateLimit)))
+	}
+	if a.newKeyStep > 0 {
+		b.WriteString("\n")
+	}
+
+	b.WriteString(apiKeyPrompts[a.newKeyStep] + "\n\n")
+	b.WriteString(boxStyle.Render(a.inputValue + "_"))
+	b.WriteString("\n")
+
+	if a.newKeyStep == len(apiKeyPrompts)-1 {
+		b.WriteString(helpStyle.Render("\n[Enter] Create  [Esc] Cancel"))
+	} else {
+		b.WriteString(helpStyle.Render("\n[Enter] Next  [Esc] Cancel"))
+	}
+
+	return b.String()
+}
+
+// formatTools formats an API key's tool allowlist for display
+func formatTools(tools []string) string {
+	if len(tools) == 0 {
+		return "all tools"
+	}
+	return strings.Join(tools, " ")
+}
+
+// formatRateLimit formats an API key's rate limit for display
+func formatRateLimit(limit int) string {
+	if limit <= 0 {
+		return "server limit"
+	}
+	return fmt.Sprintf("%d/min", limit)
+}
+
+// formatExpiry formats an API key's expiry for display
+func formatExpiry(expiresAt *time.Time) string {
+	if expiresAt == nil {
+		return "never"
+	}
+	return expiresAt.Format("2006-01-02 15:04")
+}
+
+func (a *App) viewAPIKeyCreated() string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render("API Key Created"))
+	b.WriteString("\n\n")
+
+	b.WriteString(successStyle.Render("API key created successfully!"))
+	b.WriteString("\n\n")
+
+	b.WriteString(warningStyle.Render("IMPORTANT: Save the API key now. It will not be shown again!"))
+	b.WriteString("\n\n")
+
+	content := fmt.Sprintf("Name:       %s\nAPI Key:    %s\nTools:      %s\nRate limit: %s\nExpires:    %s",
+		a.newKeyName, a.newKey, formatTools(a.newKeyTools), formatRateLimit(a.newKeyRateLimit), formatExpiry(a.newKeyExpiresAt))
+	b.WriteString(boxStyle.Render(content))
+	b.WriteString("\n")
+
+	b.WriteString(helpStyle.Render("\n[Enter] Continue"))
+
+	return b.String()
+}
+
+func (a *App) viewAPIKeyConfirmDelete() string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render("Delete API Key"))
+	b.WriteString("\n\n")
+
+	b.WriteString(warningStyle.Render(fmt.Sprintf("Are you sure you want to delete API key '%s'?", a.selectedAPIKey.Name)))
+	b.WriteString("\n")
+	b.WriteString(warningStyle.Render("This action cannot be undone."))
+	b.WriteString("\n\n")
+
+	menuItems := []string{"Yes, delete", "No, cancel"}
+	for i, item := range menuItems {
+		if i == a.cursor {
+			b.WriteString(selectedItemStyle.Render("> " + item))
+		} else {
+			b.WriteString(menuItemStyle.Render("  " + item))
+		}
+		b.WriteString("\n")
+	}
+
+	// Clamp cursor
+	if a.cursor > 1 {
+		a.cursor = 1
+	}
+
+	b.WriteString(helpStyle.Render("\n[j/k] Navigate  [Enter] Select"))
+
+	return b.String()
+}
+
 func (a *App) viewAuditLogs() string {
 	var b strings.Builder
 
@@ -0,0 +1,263 @@
+package validate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/takeshy/mcp-gatekeeper/internal/executor"
+	"github.com/takeshy/mcp-gatekeeper/internal/mcp"
+	"github.com/takeshy/mcp-gatekeeper/internal/plugin"
+	"github.com/takeshy/mcp-gatekeeper/internal/policy"
+)
+
+// Severity represents how serious a validation issue is
+type Severity string
+
+const (
+	SeverityError   Severity = "error"   // The server would refuse or misbehave with this configuration
+	SeverityWarning Severity = "warning" // Likely a mistake, but the configuration loads
+)
+
+// Issue represents a single validation finding
+type Issue struct {
+	Severity Severity `json:"severity"`
+	File     string   `json:"file,omitempty"`
+	Tool     string   `json:"tool,omitempty"`
+	Message  string   `json:"message"`
+}
+
+// Report is the machine-readable result of validating plugin files
+type Report struct {
+	Path     string   `json:"path"`
+	Valid    bool     `json:"valid"`
+	Files    []string `json:"files"`
+	Tools    int      `json:"tools"`
+	Errors   int      `json:"errors"`
+	Warnings int      `json:"warnings"`
+	Issues   []Issue  `json:"issues"`
+}
+
+// Run validates a plugin directory or a single plugin file.
+// Every file is checked independently so that all problems are reported at once.
+func Run(path string) *Report {
+	report := &Report{
+		Path:   path,
+		Files:  []string{},
+		Issues: []Issue{},
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		report.add(SeverityError, "", "", fmt.Sprintf("cannot access plugin path: %v", err))
+		return report.finish()
+	}
+
+	var filePaths []string
+	if info.IsDir() {
+		filePaths, err = plugin.FindPluginFiles(path)
+		if err != nil {
+			report.add(SeverityError, "", "", err.Error())
+			return report.finish()
+		}
+		if len(filePaths) == 0 {
+			report.add(SeverityWarning, "", "", "no plugin files found")
+		}
+	} else {
+		filePaths = []string{path}
+	}
+
+	seen := make(map[string]string) // tool name -> file
+	for _, filePath := range filePaths {
+		file := filePath
+		if info.IsDir() {
+			file, _ = filepath.Rel(path, filePath)
+		}
+		report.Files = append(report.Files, file)
+		report.checkFile(filePath, file, seen)
+	}
+
+	return report.finish()
+}
+
+// checkFile validates a single plugin file
+func (r *Report) checkFile(filePath, file string, seen map[string]string) {
+	checkUnknownFields(r, filePath, file)
+
+	pluginFile, err := plugin.ReadPluginFile(filePath)
+	if err != nil {
+		r.add(SeverityError, file, "", err.Error())
+		return
+	}
+
+	if err := policy.ValidateAllowedEnvKeys(pluginFile.AllowedEnvKeys); err != nil {
+		r.add(SeverityError, file, "", err.Error())
+	}
+	for _, dup := range duplicates(pluginFile.AllowedEnvKeys) {
+		r.add(SeverityWarning, file, "", fmt.Sprintf("duplicate allowed_env_key %q", dup))
+	}
+
+	for i := range pluginFile.Tools {
+		tool := &pluginFile.Tools[i]
+		if tool.Sandbox == "" {
+			tool.Sandbox = plugin.SandboxTypeNone
+		}
+		r.Tools++
+
+		// Each tool is validated on its own so that every broken tool is reported
+		if err := pluginFile.PrepareTool(i, filePath); err != nil {
+			r.add(SeverityError, file, tool.Name, err.Error())
+			continue
+		}
+
+		if other, exists := seen[tool.Name]; exists {
+			r.add(SeverityError, file, tool.Name, fmt.Sprintf("duplicate tool name (also defined in %s)", other))
+		} else {
+			seen[tool.Name] = file
+		}
+
+		r.checkTool(file, tool)
+	}
+}
+
+// checkTool validates a single tool definition
+func (r *Report) checkTool(file string, tool *plugin.Tool) {
+	if err := policy.ValidateTool(tool); err != nil {
+		r.add(SeverityError, file, tool.Name, err.Error())
+	}
+
+	// The server's ceilings are configurable, so exceeding the defaults is a warning
+	if err := executor.DefaultLimits().ValidateTool(tool); err != nil {
+		r.add(SeverityWarning, file, tool.Name, fmt.Sprintf("%v; start the server with a higher --timeout-ceiling or --output-ceiling", err))
+	}
+
+	switch tool.Sandbox {
+	case plugin.SandboxTypeWasm:
+		if info, err := os.Stat(tool.WasmBinary); err != nil {
+			r.add(SeverityError, file, tool.Name, fmt.Sprintf("wasm_binary not found: %v", err))
+		} else if info.IsDir() {
+			r.add(SeverityError, file, tool.Name, fmt.Sprintf("wasm_binary %q is a directory", tool.WasmBinary))
+		}
+	case plugin.SandboxTypeNone, plugin.SandboxTypeBubblewrap:
+		// The command may only exist on the deployment host, so this is a warning
+		if _, err := exec.LookPath(tool.Command); err != nil {
+			r.add(SeverityWarning, file, tool.Name, fmt.Sprintf("command %q not found: %v", tool.Command, err))
+		}
+	}
+
+	switch tool.UIType {
+	case plugin.UITypeNone, plugin.UITypeTable, plugin.UITypeJSON, plugin.UITypeLog:
+	default:
+		r.add(SeverityError, file, tool.Name, fmt.Sprintf("invalid ui_type %q", tool.UIType))
+	}
+	switch tool.OutputFormat {
+	case plugin.OutputFormatNone, plugin.OutputFormatJSON, plugin.OutputFormatCSV, plugin.OutputFormatLines:
+	default:
+		r.add(SeverityError, file, tool.Name, fmt.Sprintf("invalid output_format %q", tool.OutputFormat))
+	}
+	if tool.UITemplate != "" {
+		if err := mcp.ValidateTemplate(tool.UITemplate); err != nil {
+			r.add(SeverityError, file, tool.Name, fmt.Sprintf("ui_template: %v", err))
+		}
+	}
+
+	for _, msg := range unreachablePatterns("allowed_arg_globs", tool.AllowedArgGlobs, tool.DeniedArgGlobs) {
+		r.add(SeverityWarning, file, tool.Name, msg)
+	}
+	for _, dup := range duplicates(tool.DeniedArgGlobs) {
+		r.add(SeverityWarning, file, tool.Name, fmt.Sprintf("duplicate pattern %q in denied_arg_globs", dup))
+	}
+	for i, rule := range tool.ArgRules {
+		field := fmt.Sprintf("arg_rules[%d].allow", i)
+		deny := append(append([]string{}, rule.Deny...), tool.DeniedArgGlobs...)
+		for _, msg := range unreachablePatterns(field, rule.Allow, deny) {
+			r.add(SeverityWarning, file, tool.Name, msg)
+		}
+	}
+}
+
+// unreachablePatterns reports allow patterns that can never decide a match: duplicates,
+// patterns after a catch-all "**", and literal patterns that a deny pattern always rejects
+func unreachablePatterns(field string, allow, deny []string) []string {
+	var msgs []string
+	matcher := policy.NewMatcher()
+
+	for _, dup := range duplicates(allow) {
+		msgs = append(msgs, fmt.Sprintf("duplicate pattern %q in %s", dup, field))
+	}
+
+	for i, pattern := range allow {
+		if pattern == "**" && i < len(allow)-1 {
+			msgs = append(msgs, fmt.Sprintf("patterns after %q in %s are unreachable: %q", pattern, field, allow[i+1:]))
+			break
+		}
+	}
+
+	for _, pattern := range allow {
+		if !isLiteral(pattern) {
+			continue
+		}
+		if matched, denyPattern, err := matcher.MatchAnyDeny(deny, pattern); err == nil && matched {
+			msgs = append(msgs, fmt.Sprintf("pattern %q in %s is unreachable: always denied by %q", pattern, field, denyPattern))
+		}
+	}
+
+	return msgs
+}
+
+// isLiteral returns true if a glob pattern contains no special characters
+func isLiteral(pattern string) bool {
+	return !strings.ContainsAny(pattern, `*?[]{}\`)
+}
+
+// duplicates returns the values that appear more than once, in order of first repetition
+func duplicates(values []string) []string {
+	var dups []string
+	count := make(map[string]int)
+	for _, v := range values {
+		count[v]++
+		if count[v] == 2 {
+			dups = append(dups, v)
+		}
+	}
+	return dups
+}
+
+// checkUnknownFields reports fields that plugin.json does not define (usually typos)
+func checkUnknownFields(r *Report, filePath, file string) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return // Reported by ReadPluginFile
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var pluginFile plugin.PluginFile
+	if err := dec.Decode(&pluginFile); err != nil && strings.Contains(err.Error(), "unknown field") {
+		r.add(SeverityWarning, file, "", strings.TrimPrefix(err.Error(), "json: "))
+	}
+}
+
+// add records an issue
+func (r *Report) add(severity Severity, file, tool, message string) {
+	r.Issues = append(r.Issues, Issue{
+		Severity: severity,
+		File:     file,
+		Tool:     tool,
+		Message:  message,
+	})
+	if severity == SeverityError {
+		r.Errors++
+	} else {
+		r.Warnings++
+	}
+}
+
+// finish computes the overall result
+func (r *Report) finish() *Report {
+	r.Valid = r.Errors == 0
+	return r
+}
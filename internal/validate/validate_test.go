@@ -0,0 +1,208 @@
+package validate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// hasIssue reports whether the report contains an issue with the given severity and message fragment
+func hasIssue(report *Report, severity Severity, fragment string) bool {
+	for _, issue := range report.Issues {
+		if issue.Severity == severity && strings.Contains(issue.Message, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRun_Valid(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "ls.json"), `{
+		"tools": [{"name": "ls", "command": "ls", "allowed_arg_globs": ["-la", "*"]}]
+	}`)
+	writeFile(t, filepath.Join(dir, "git", "plugin.json"), `{
+		"tools": [{"name": "git-status", "command": "git", "args_prefix": ["status"], "allowed_arg_globs": [""]}]
+	}`)
+
+	report := Run(dir)
+	if !report.Valid || report.Errors != 0 {
+		t.Fatalf("Run() = %+v, want valid", report)
+	}
+	if len(report.Files) != 2 || report.Tools != 2 {
+		t.Errorf("Run() files = %v, tools = %d", report.Files, report.Tools)
+	}
+}
+
+func TestRun_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		severity Severity
+		fragment string
+	}{
+		{
+			name:     "invalid json",
+			files:    map[string]string{"bad.json": `{"tools": [`},
+			severity: SeverityError,
+			fragment: "failed to parse",
+		},
+		{
+			name: "duplicate tool names",
+			files: map[string]string{
+				"a.json": `{"tools": [{"name": "ls", "command": "ls"}]}`,
+				"b.json": `{"tools": [{"name": "ls", "command": "ls"}]}`,
+			},
+			severity: SeverityError,
+			fragment: "duplicate tool name",
+		},
+		{
+			name:     "invalid glob",
+			files:    map[string]string{"a.json": `{"tools": [{"name": "ls", "command": "ls", "allowed_arg_globs": ["[abc"]}]}`},
+			severity: SeverityError,
+			fragment: "invalid",
+		},
+		{
+			name:     "missing wasm binary",
+			files:    map[string]string{"a.json": `{"tools": [{"name": "w", "sandbox": "wasm", "wasm_binary": "missing.wasm"}]}`},
+			severity: SeverityError,
+			fragment: "wasm_binary not found",
+		},
+		{
+			name:     "missing ui template",
+			files:    map[string]string{"a.json": `{"tools": [{"name": "ls", "command": "ls", "ui_template": "missing.html"}]}`},
+			severity: SeverityError,
+			fragment: "ui_template",
+		},
+		{
+			name: "unparsable ui template",
+			files: map[string]string{
+				"a.json":  `{"tools": [{"name": "ls", "command": "ls", "ui_template": "ui.html"}]}`,
+				"ui.html": `{{.Output`,
+			},
+			severity: SeverityError,
+			fragment: "ui_template",
+		},
+		{
+			name:     "invalid ui type",
+			files:    map[string]string{"a.json": `{"tools": [{"name": "ls", "command": "ls", "ui_type": "chart"}]}`},
+			severity: SeverityError,
+			fragment: "invalid ui_type",
+		},
+		{
+			name:     "invalid timeout",
+			files:    map[string]string{"a.json": `{"tools": [{"name": "ls", "command": "ls", "timeout": "5"}]}`},
+			severity: SeverityError,
+			fragment: "invalid timeout",
+		},
+		{
+			name:     "timeout above default ceiling",
+			files:    map[string]string{"a.json": `{"tools": [{"name": "ls", "command": "ls", "timeout": "1h"}]}`},
+			severity: SeverityWarning,
+			fragment: "--timeout-ceiling",
+		},
+		{
+			name:     "unknown field",
+			files:    map[string]string{"a.json": `{"tools": [{"name": "ls", "command": "ls", "allowed_args_globs": ["*"]}]}`},
+			severity: SeverityWarning,
+			fragment: "allowed_args_globs",
+		},
+		{
+			name:     "command not found",
+			files:    map[string]string{"a.json": `{"tools": [{"name": "x", "command": "no-such-command-for-validate-test"}]}`},
+			severity: SeverityWarning,
+			fragment: "not found",
+		},
+		{
+			name:     "patterns after catch-all",
+			files:    map[string]string{"a.json": `{"tools": [{"name": "ls", "command": "ls", "allowed_arg_globs": ["**", "-la"]}]}`},
+			severity: SeverityWarning,
+			fragment: "unreachable",
+		},
+		{
+			name:     "duplicate pattern",
+			files:    map[string]string{"a.json": `{"tools": [{"name": "ls", "command": "ls", "allowed_arg_globs": ["-la", "-la"]}]}`},
+			severity: SeverityWarning,
+			fragment: "duplicate pattern",
+		},
+		{
+			name: "allow pattern always denied",
+			files: map[string]string{"a.json": `{"tools": [{"name": "git", "command": "git",
+				"allowed_arg_globs": ["push --force"], "denied_arg_globs": ["*--force*"]}]}`},
+			severity: SeverityWarning,
+			fragment: "always denied",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				writeFile(t, filepath.Join(dir, name), content)
+			}
+
+			report := Run(dir)
+			if !hasIssue(report, tt.severity, tt.fragment) {
+				t.Fatalf("Run() issues = %+v, want %s containing %q", report.Issues, tt.severity, tt.fragment)
+			}
+			if tt.severity == SeverityError && report.Valid {
+				t.Error("Run() Valid = true, want false")
+			}
+			if tt.severity == SeverityWarning && !report.Valid {
+				t.Errorf("Run() Valid = false for warning only, issues = %+v", report.Issues)
+			}
+		})
+	}
+}
+
+func TestRun_ReportsEveryBrokenTool(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.json"), `{"tools": [
+		{"name": "a", "command": "ls", "timeout": "5"},
+		{"name": "b"},
+		{"name": "c", "command": "ls", "ui_type": "chart"}
+	]}`)
+
+	report := Run(dir)
+	for _, tool := range []string{"a", "b", "c"} {
+		found := false
+		for _, issue := range report.Issues {
+			if issue.Severity == SeverityError && issue.Tool == tool {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Run() issues = %+v, want an error for tool %q", report.Issues, tool)
+		}
+	}
+	if report.Tools != 3 {
+		t.Errorf("Run() Tools = %d, want 3", report.Tools)
+	}
+}
+
+func TestRun_SingleFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "plugin.json")
+	writeFile(t, path, `{"tools": [{"name": "ls", "command": "ls"}]}`)
+
+	report := Run(path)
+	if !report.Valid || report.Tools != 1 {
+		t.Errorf("Run() = %+v", report)
+	}
+
+	report = Run(filepath.Join(dir, "missing.json"))
+	if report.Valid || report.Errors != 1 {
+		t.Errorf("Run() missing path = %+v, want one error", report)
+	}
+}